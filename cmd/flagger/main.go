@@ -5,14 +5,19 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"strings"
 	"time"
 
 	semver "github.com/Masterminds/semver/v3"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"k8s.io/client-go/tools/cache"
@@ -22,6 +27,7 @@ import (
 	"k8s.io/client-go/transport"
 	_ "k8s.io/code-generator/cmd/client-gen/generators"
 
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 	"github.com/weaveworks/flagger/pkg/canary"
 	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
 	informers "github.com/weaveworks/flagger/pkg/client/informers/externalversions"
@@ -32,6 +38,7 @@ import (
 	"github.com/weaveworks/flagger/pkg/router"
 	"github.com/weaveworks/flagger/pkg/server"
 	"github.com/weaveworks/flagger/pkg/signals"
+	"github.com/weaveworks/flagger/pkg/sink"
 	"github.com/weaveworks/flagger/pkg/version"
 )
 
@@ -51,6 +58,7 @@ var (
 	zapReplaceGlobals        bool
 	zapEncoding              string
 	namespace                string
+	shard                    string
 	meshProvider             string
 	selectorLabels           string
 	ingressAnnotationsPrefix string
@@ -59,6 +67,19 @@ var (
 	enableConfigTracking     bool
 	ver                      bool
 	kubeconfigServiceMesh    string
+	kubeconfigMeshClusters   string
+	eventSinkProvider        string
+	eventSinkAddress         string
+	eventSinkTopic           string
+	eventSinkBufferSize      int
+	enableAutoCanary         bool
+	autoCanaryTemplate       string
+	enableFleet              bool
+	enableAdmissionWebhook   bool
+	enableConversionWebhook  bool
+	admissionPort            string
+	admissionTLSCertFile     string
+	admissionTLSKeyFile      string
 )
 
 func init() {
@@ -77,6 +98,7 @@ func init() {
 	flag.BoolVar(&zapReplaceGlobals, "zap-replace-globals", false, "Whether to change the logging level of the global zap logger.")
 	flag.StringVar(&zapEncoding, "zap-encoding", "json", "Zap logger encoding.")
 	flag.StringVar(&namespace, "namespace", "", "Namespace that flagger would watch canary object.")
+	flag.StringVar(&shard, "shard", "", "Watch only the canaries and canary fleets labeled with flagger.app/shard set to this value, when empty the labeled ones are ignored.")
 	flag.StringVar(&meshProvider, "mesh-provider", "istio", "Service mesh provider, can be istio, linkerd, appmesh, supergloo, nginx or smi.")
 	flag.StringVar(&selectorLabels, "selector-labels", "app,name,app.kubernetes.io/name", "List of pod labels that Flagger uses to create pod selectors.")
 	flag.StringVar(&ingressAnnotationsPrefix, "ingress-annotations-prefix", "nginx.ingress.kubernetes.io", "Annotations prefix for ingresses.")
@@ -85,6 +107,19 @@ func init() {
 	flag.BoolVar(&enableConfigTracking, "enable-config-tracking", true, "Enable secrets and configmaps tracking.")
 	flag.BoolVar(&ver, "version", false, "Print version")
 	flag.StringVar(&kubeconfigServiceMesh, "kubeconfig-service-mesh", "", "Path to a kubeconfig for the service mesh control plane cluster.")
+	flag.StringVar(&kubeconfigMeshClusters, "kubeconfig-service-mesh-clusters", "", "Comma separated list of kubeconfigs for the other control plane clusters of an Istio multi-primary mesh.")
+	flag.StringVar(&eventSinkProvider, "event-sink-provider", "", "Message broker for publishing canary events, can be nats or kafka.")
+	flag.StringVar(&eventSinkAddress, "event-sink-address", "", "NATS server URL or comma separated list of Kafka brokers.")
+	flag.StringVar(&eventSinkTopic, "event-sink-topic", "flagger", "NATS subject or Kafka topic for canary events.")
+	flag.IntVar(&eventSinkBufferSize, "event-sink-buffer-size", 1000, "Max number of canary events buffered before being dropped.")
+	flag.BoolVar(&enableAutoCanary, "enable-auto-canary", false, "Generate canaries for the deployments and daemonsets labeled with flagger.app/auto-canary=enabled.")
+	flag.StringVar(&autoCanaryTemplate, "auto-canary-template", "", "Template of the generated canaries in the format [CanaryTemplate/|ClusterCanaryTemplate/]name, can be overridden with the flagger.app/canary-template annotation.")
+	flag.BoolVar(&enableFleet, "enable-fleet", false, "Roll out the canaries of the CanaryFleet resources across clusters in waves.")
+	flag.BoolVar(&enableAdmissionWebhook, "enable-admission-webhook", false, "Serve the validating and mutating admission webhooks for the Flagger custom resources.")
+	flag.BoolVar(&enableConversionWebhook, "enable-conversion-webhook", false, "Serve the Canary conversion webhook between the flagger.app v1beta1 and v1 API versions.")
+	flag.StringVar(&admissionPort, "admission-port", "9443", "HTTPS port of the admission and conversion webhooks.")
+	flag.StringVar(&admissionTLSCertFile, "admission-tls-cert-file", "/etc/flagger/tls/tls.crt", "TLS certificate of the admission webhooks.")
+	flag.StringVar(&admissionTLSKeyFile, "admission-tls-key-file", "/etc/flagger/tls/tls.key", "TLS private key of the admission webhooks.")
 }
 
 func main() {
@@ -138,8 +173,15 @@ func main() {
 		logger.Fatalf("Error building mesh clientset: %v", err)
 	}
 
+	// keep the routing in sync across the control planes of a multi-primary mesh
+	meshClusters := initMeshClusters(kubeconfigMeshClusters, logger)
+
 	verifyCRDs(flaggerClient, logger)
 	verifyKubernetesVersion(kubeClient, logger)
+	if errs := validation.IsValidLabelValue(shard); len(errs) > 0 {
+		logger.Fatalf("Invalid shard %s: %s", shard, strings.Join(errs, ", "))
+	}
+
 	infos := startInformers(flaggerClient, logger, stopCh)
 
 	labels := strings.Split(selectorLabels, ",")
@@ -150,6 +192,9 @@ func main() {
 	if namespace != "" {
 		logger.Infof("Watching namespace %s", namespace)
 	}
+	if shard != "" {
+		logger.Infof("Watching shard %s", shard)
+	}
 
 	observerFactory, err := observers.NewFactory(metricsServer)
 	if err != nil {
@@ -166,17 +211,35 @@ func main() {
 	// setup Slack or MS Teams notifications
 	notifierClient := initNotifier(logger)
 
+	// setup NATS or Kafka event publishing
+	eventSink := initEventSink(logger, stopCh)
+
 	// start HTTP server
+	admissionMux := http.NewServeMux()
+	if enableAdmissionWebhook {
+		server.HandleAdmission(admissionMux, logger)
+	}
+	if enableConversionWebhook {
+		server.HandleConversion(admissionMux, logger)
+	}
+	if enableAdmissionWebhook || enableConversionWebhook {
+		go server.ListenAndServeTLS(admissionPort, admissionTLSCertFile, admissionTLSKeyFile, admissionMux, 3*time.Second, logger, stopCh)
+	}
 	go server.ListenAndServe(port, 3*time.Second, logger, stopCh)
 
-	routerFactory := router.NewFactory(cfg, kubeClient, flaggerClient, ingressAnnotationsPrefix, logger, meshClient)
+	routerFactory := router.NewFactory(cfg, kubeClient, flaggerClient, ingressAnnotationsPrefix, logger, meshClient, meshClusters...)
 
 	var configTracker canary.Tracker
 	if enableConfigTracking {
+		dynamicClient, err := dynamic.NewForConfig(cfg)
+		if err != nil {
+			logger.Fatalf("Error building dynamic client: %v", err)
+		}
 		configTracker = &canary.ConfigTracker{
 			Logger:        logger,
 			KubeClient:    kubeClient,
 			FlaggerClient: flaggerClient,
+			DynamicClient: dynamicClient,
 		}
 	} else {
 		configTracker = &canary.NopTracker{}
@@ -197,8 +260,27 @@ func main() {
 		meshProvider,
 		version.VERSION,
 		fromEnv("EVENT_WEBHOOK_URL", eventWebhook),
+		eventSink,
+		shard,
 	)
 
+	// generate canaries for labeled workloads
+	var autoCanary *controller.AutoCanaryController
+	if enableAutoCanary {
+		kubeInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, time.Second*30,
+			kubeinformers.WithNamespace(namespace), kubeinformers.WithTweakListOptions(shardListOptions))
+		autoCanary = controller.NewAutoCanaryController(kubeClient, flaggerClient, kubeInformerFactory, infos, autoCanaryTemplate, logger)
+		kubeInformerFactory.Start(stopCh)
+		logger.Infof("Generating canaries for workloads labeled with %s=enabled", flaggerv1.AutoCanaryLabel)
+	}
+
+	// roll out canaries across clusters
+	var fleet *controller.FleetController
+	if enableFleet {
+		fleet = controller.NewFleetController(kubeClient, flaggerClient, namespace, labels, shardListOptions, logger)
+		logger.Info("Rolling out canary fleets across clusters")
+	}
+
 	// leader election context
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -214,6 +296,12 @@ func main() {
 
 	// wrap controller run
 	runController := func() {
+		if autoCanary != nil {
+			go autoCanary.Run(stopCh)
+		}
+		if fleet != nil {
+			go fleet.Run(controlLoopInterval, stopCh)
+		}
 		if err := c.Run(threadiness, stopCh); err != nil {
 			logger.Fatalf("Error running controller: %v", err)
 		}
@@ -231,11 +319,40 @@ func main() {
 	}
 }
 
+func initMeshClusters(kubeconfigs string, logger *zap.SugaredLogger) []router.MeshCluster {
+	var clusters []router.MeshCluster
+	for _, path := range strings.Split(kubeconfigs, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		cfg, err := clientcmd.BuildConfigFromFlags("", path)
+		if err != nil {
+			logger.Fatalf("Error building mesh cluster kubeconfig %s: %v", path, err)
+		}
+		client, err := clientset.NewForConfig(cfg)
+		if err != nil {
+			logger.Fatalf("Error building mesh cluster clientset %s: %v", path, err)
+		}
+
+		// name the cluster after the kubeconfig context
+		name := path
+		if raw, err := clientcmd.LoadFromFile(path); err == nil && raw.CurrentContext != "" {
+			name = raw.CurrentContext
+		}
+		clusters = append(clusters, router.MeshCluster{Name: name, Client: client})
+		logger.Infof("Syncing Istio routing to mesh cluster %s", name)
+	}
+	return clusters
+}
+
 func startInformers(flaggerClient clientset.Interface, logger *zap.SugaredLogger, stopCh <-chan struct{}) controller.Informers {
 	flaggerInformerFactory := informers.NewSharedInformerFactoryWithOptions(flaggerClient, time.Second*30, informers.WithNamespace(namespace))
+	shardInformerFactory := informers.NewSharedInformerFactoryWithOptions(flaggerClient, time.Second*30,
+		informers.WithNamespace(namespace), informers.WithTweakListOptions(shardListOptions))
 
 	logger.Info("Waiting for canary informer cache to sync")
-	canaryInformer := flaggerInformerFactory.Flagger().V1beta1().Canaries()
+	canaryInformer := shardInformerFactory.Flagger().V1beta1().Canaries()
 	go canaryInformer.Informer().Run(stopCh)
 	if ok := cache.WaitForNamedCacheSync("flagger", stopCh, canaryInformer.Informer().HasSynced); !ok {
 		logger.Fatalf("failed to wait for cache to sync")
@@ -255,15 +372,40 @@ func startInformers(flaggerClient clientset.Interface, logger *zap.SugaredLogger
 		logger.Fatalf("failed to wait for cache to sync")
 	}
 
+	logger.Info("Waiting for canary template informer caches to sync")
+	canaryTemplateInformer := flaggerInformerFactory.Flagger().V1beta1().CanaryTemplates()
+	go canaryTemplateInformer.Informer().Run(stopCh)
+	clusterCanaryTemplateInformer := flaggerInformerFactory.Flagger().V1beta1().ClusterCanaryTemplates()
+	go clusterCanaryTemplateInformer.Informer().Run(stopCh)
+	if ok := cache.WaitForNamedCacheSync("flagger", stopCh, canaryTemplateInformer.Informer().HasSynced,
+		clusterCanaryTemplateInformer.Informer().HasSynced); !ok {
+		logger.Fatalf("failed to wait for cache to sync")
+	}
+
 	return controller.Informers{
-		CanaryInformer: canaryInformer,
-		MetricInformer: metricInformer,
-		AlertInformer:  alertInformer,
+		CanaryInformer:                canaryInformer,
+		MetricInformer:                metricInformer,
+		AlertInformer:                 alertInformer,
+		CanaryTemplateInformer:        canaryTemplateInformer,
+		ClusterCanaryTemplateInformer: clusterCanaryTemplateInformer,
+	}
+}
+
+// shardListOptions selects the canaries, the canary fleets and the auto canary workloads of the shard
+func shardListOptions(options *metav1.ListOptions) {
+	if shard == "" {
+		options.LabelSelector = "!" + flaggerv1.ShardLabel
+		return
 	}
+	options.LabelSelector = fmt.Sprintf("%s=%s", flaggerv1.ShardLabel, shard)
 }
 
 func startLeaderElection(ctx context.Context, run func(), ns string, kubeClient kubernetes.Interface, logger *zap.SugaredLogger) {
+	// the instances of a shard elect their own leader
 	configMapName := "flagger-leader-election"
+	if shard != "" {
+		configMapName = fmt.Sprintf("%s-%s", configMapName, shard)
+	}
 	id, err := os.Hostname()
 	if err != nil {
 		logger.Fatalf("Error running controller: %v", err)
@@ -328,6 +470,25 @@ func initNotifier(logger *zap.SugaredLogger) (client notifier.Interface) {
 	return
 }
 
+func initEventSink(logger *zap.SugaredLogger, stopCh <-chan struct{}) sink.Interface {
+	if eventSinkProvider == "" {
+		return &sink.NopSink{}
+	}
+
+	sinkFactory := sink.NewFactory(fromEnv("EVENT_SINK_ADDRESS", eventSinkAddress), eventSinkTopic)
+	s, err := sinkFactory.Sink(eventSinkProvider)
+	if err != nil {
+		logger.Fatalf("Error creating event sink: %v", err)
+	}
+
+	dispatcher := sink.NewDispatcher(s, eventSinkBufferSize, logger)
+	prometheus.MustRegister(dispatcher.NewDroppedCounter("flagger"))
+	go dispatcher.Run(stopCh)
+
+	logger.Infof("Publishing canary events to %s topic %s", eventSinkProvider, eventSinkTopic)
+	return dispatcher
+}
+
 func fromEnv(envVar string, defaultVal string) string {
 	if v := os.Getenv(envVar); v != "" {
 		return v
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+	"github.com/weaveworks/flagger/pkg/flaggerctl"
+	"github.com/weaveworks/flagger/pkg/signals"
+	"github.com/weaveworks/flagger/pkg/version"
+)
+
+const usage = `flaggerctl operates Flagger canaries through the Kubernetes API,
+installed in the PATH as kubectl-flagger it can be used as a kubectl plugin.
+
+Usage:
+  kubectl flagger status [name]                 show the rollout status of a canary or list the canaries
+  kubectl flagger events <name> [-f]            print the analysis events of a canary
+  kubectl flagger promote <name>                promote the canary without completing the analysis
+  kubectl flagger pause <name>                  halt the canary advancement
+  kubectl flagger resume <name>                 resume the canary advancement
+  kubectl flagger abort <name>                  roll back the analysis underway
+  kubectl flagger gates list <name>             list the loadtester gates of a canary
+  kubectl flagger gates open <name>             open the confirm or rollback gate
+  kubectl flagger gates close <name>            close the confirm or rollback gate
+  kubectl flagger routes <name>                 print the routing objects generated for a canary
+  kubectl flagger render -canary <file> -target <file>
+                                                print the objects Flagger would generate without a cluster
+  kubectl flagger simulate -canary <file> -timeline <file> [-target <file>]
+                                                replay a metrics timeline against the canary analysis without a cluster
+  kubectl flagger version                       print the version
+
+Flags:
+`
+
+var (
+	kubeconfig               string
+	kubeContext              string
+	namespace                string
+	allNamespaces            bool
+	follow                   bool
+	loadtester               string
+	gateToken                string
+	rollback                 bool
+	ttl                      time.Duration
+	reason                   string
+	canaryFile               string
+	targetFile               string
+	provider                 string
+	weight                   int
+	selectorLabels           string
+	ingressAnnotationsPrefix string
+	timelineFile             string
+	maxSteps                 int
+)
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig. Defaults to the KUBECONFIG env var or ~/.kube/config.")
+	fs.StringVar(&kubeContext, "context", "", "The kubeconfig context to use.")
+	fs.StringVar(&namespace, "n", "", "Namespace of the canary. Defaults to the kubeconfig context namespace.")
+	fs.StringVar(&namespace, "namespace", "", "Namespace of the canary. Defaults to the kubeconfig context namespace.")
+	fs.BoolVar(&allNamespaces, "A", false, "List the canaries across all namespaces.")
+	fs.BoolVar(&follow, "f", false, "Follow the analysis events.")
+	fs.StringVar(&loadtester, "loadtester", "", "Loadtester service address <name>.<namespace>:<port>. Defaults to the canary gate webhooks.")
+	fs.StringVar(&gateToken, "gate-token", os.Getenv("FLAGGER_GATE_TOKEN"), "Token used to authenticate to the loadtester gate API. Defaults to the FLAGGER_GATE_TOKEN env var.")
+	fs.BoolVar(&rollback, "rollback", false, "Toggle the rollback gate instead of the confirm gate.")
+	fs.DurationVar(&ttl, "ttl", 0, "Close the opened gate automatically after the duration.")
+	fs.StringVar(&reason, "reason", "", "Reason recorded in the gate audit trail.")
+	fs.StringVar(&canaryFile, "canary", "", "Path to the canary manifest rendered offline.")
+	fs.StringVar(&targetFile, "target", "", "Path to the target workload manifests rendered offline, can contain ConfigMaps, Secrets, HPAs and Ingresses.")
+	fs.StringVar(&provider, "provider", "", "Mesh provider used for rendering. Defaults to the canary provider or istio.")
+	fs.IntVar(&weight, "weight", 0, "Canary traffic weight used for rendering the routes.")
+	fs.StringVar(&selectorLabels, "selector-labels", "app,name,app.kubernetes.io/name", "List of pod labels that Flagger uses to create pod selectors.")
+	fs.StringVar(&ingressAnnotationsPrefix, "ingress-annotations-prefix", "nginx.ingress.kubernetes.io", "Annotations prefix for ingresses.")
+	fs.StringVar(&timelineFile, "timeline", "", "Path to the metric values and webhook outcomes replayed by the simulation.")
+	fs.IntVar(&maxSteps, "max-steps", 100, "Stop the simulation after the number of analysis steps.")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs parses the flags placed before, between or after the positional arguments
+func parseArgs(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func main() {
+	fs := newFlagSet("flaggerctl")
+	args := parseArgs(fs, os.Args[1:])
+	if len(args) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "version":
+		fmt.Println(version.VERSION)
+		return
+	case "render":
+		if err := render(); err != nil {
+			fatalf("%v", err)
+		}
+		return
+	case "simulate":
+		if err := simulate(); err != nil {
+			fatalf("%v", err)
+		}
+		return
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		loadingRules, &clientcmd.ConfigOverrides{CurrentContext: kubeContext})
+
+	cfg, err := clientConfig.ClientConfig()
+	if err != nil {
+		fatalf("Error building kubeconfig: %v", err)
+	}
+	if namespace == "" {
+		namespace, _, err = clientConfig.Namespace()
+		if err != nil {
+			fatalf("Error reading the kubeconfig namespace: %v", err)
+		}
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		fatalf("Error building kubernetes clientset: %v", err)
+	}
+	flaggerClient, err := clientset.NewForConfig(cfg)
+	if err != nil {
+		fatalf("Error building flagger clientset: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopCh := signals.SetupSignalHandler()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	client := flaggerctl.NewClient(kubeClient, flaggerClient, gateToken, os.Stdout)
+	if err := run(ctx, client, command, args); err != nil {
+		fatalf("%v", err)
+	}
+}
+
+func run(ctx context.Context, client *flaggerctl.Client, command string, args []string) error {
+	switch command {
+	case "status":
+		if len(args) == 0 {
+			if allNamespaces {
+				return client.ListCanaries(ctx, "")
+			}
+			return client.ListCanaries(ctx, namespace)
+		}
+		return client.Status(ctx, namespace, args[0])
+	case "events":
+		name, err := canaryName(args)
+		if err != nil {
+			return err
+		}
+		return client.Events(ctx, namespace, name, follow)
+	case "promote", "pause", "resume", "abort":
+		name, err := canaryName(args)
+		if err != nil {
+			return err
+		}
+		switch command {
+		case "promote":
+			return client.Promote(ctx, namespace, name)
+		case "pause":
+			return client.Pause(ctx, namespace, name)
+		case "resume":
+			return client.Resume(ctx, namespace, name)
+		default:
+			return client.Abort(ctx, namespace, name)
+		}
+	case "gates":
+		if len(args) == 0 {
+			return fmt.Errorf("gates requires one of the list, open or close actions")
+		}
+		action := args[0]
+		name, err := canaryName(args[1:])
+		if err != nil {
+			return err
+		}
+		opts := flaggerctl.GateOptions{Rollback: rollback, TTL: ttl, Reason: reason}
+		switch action {
+		case "list":
+			return client.ListGates(ctx, namespace, name, loadtester)
+		case "open":
+			return client.OpenGate(ctx, namespace, name, loadtester, opts)
+		case "close":
+			return client.CloseGate(ctx, namespace, name, loadtester, opts)
+		default:
+			return fmt.Errorf("unknown gates action %s", action)
+		}
+	case "routes":
+		name, err := canaryName(args)
+		if err != nil {
+			return err
+		}
+		return client.Routes(ctx, namespace, name)
+	default:
+		return fmt.Errorf("unknown command %s, run with -h for usage", command)
+	}
+}
+
+// render runs offline and does not require a kubeconfig
+func render() error {
+	if canaryFile == "" || targetFile == "" {
+		return fmt.Errorf("render requires the -canary and -target flags")
+	}
+	canaryManifest, err := ioutil.ReadFile(canaryFile)
+	if err != nil {
+		return fmt.Errorf("reading the canary manifest failed: %w", err)
+	}
+	targetManifests, err := ioutil.ReadFile(targetFile)
+	if err != nil {
+		return fmt.Errorf("reading the target manifest failed: %w", err)
+	}
+
+	return flaggerctl.Render(canaryManifest, targetManifests, flaggerctl.RenderOptions{
+		Provider:                 provider,
+		Weight:                   weight,
+		SelectorLabels:           strings.Split(selectorLabels, ","),
+		IngressAnnotationsPrefix: ingressAnnotationsPrefix,
+	}, os.Stdout)
+}
+
+// simulate runs offline and does not require a kubeconfig
+func simulate() error {
+	if canaryFile == "" || timelineFile == "" {
+		return fmt.Errorf("simulate requires the -canary and -timeline flags")
+	}
+	canaryManifest, err := ioutil.ReadFile(canaryFile)
+	if err != nil {
+		return fmt.Errorf("reading the canary manifest failed: %w", err)
+	}
+	timeline, err := ioutil.ReadFile(timelineFile)
+	if err != nil {
+		return fmt.Errorf("reading the timeline failed: %w", err)
+	}
+	var targetManifests []byte
+	if targetFile != "" {
+		targetManifests, err = ioutil.ReadFile(targetFile)
+		if err != nil {
+			return fmt.Errorf("reading the target manifest failed: %w", err)
+		}
+	}
+
+	return flaggerctl.Simulate(canaryManifest, targetManifests, timeline, flaggerctl.SimulateOptions{
+		Provider:       provider,
+		SelectorLabels: strings.Split(selectorLabels, ","),
+		MaxSteps:       maxSteps,
+	}, os.Stdout)
+}
+
+func canaryName(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("expected the canary name as argument")
+	}
+	return args[0], nil
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -3,21 +3,35 @@ package main
 import (
 	"flag"
 	"log"
+	"os"
 	"time"
 
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
 	"github.com/weaveworks/flagger/pkg/loadtester"
 	"github.com/weaveworks/flagger/pkg/logger"
 	"github.com/weaveworks/flagger/pkg/signals"
-	"go.uber.org/zap"
 )
 
 var VERSION = "0.16.0"
 var (
-	logLevel          string
-	port              string
-	timeout           time.Duration
-	zapReplaceGlobals bool
-	zapEncoding       string
+	logLevel             string
+	port                 string
+	timeout              time.Duration
+	zapReplaceGlobals    bool
+	zapEncoding          string
+	kubeconfig           string
+	gateStorage          string
+	gateStoragePath      string
+	gateStorageNamespace string
+	gateStorageName      string
+	gateTokensFile       string
+	tlsPort              string
+	tlsCertFile          string
+	tlsKeyFile           string
+	tlsClientCAFile      string
 )
 
 func init() {
@@ -26,6 +40,16 @@ func init() {
 	flag.DurationVar(&timeout, "timeout", time.Hour, "Load test exec timeout.")
 	flag.BoolVar(&zapReplaceGlobals, "zap-replace-globals", false, "Whether to change the logging level of the global zap logger.")
 	flag.StringVar(&zapEncoding, "zap-encoding", "json", "Zap logger encoding.")
+	flag.StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster and the gate storage is configmap.")
+	flag.StringVar(&gateStorage, "gate-storage", "in-memory", "Gate storage backend can be: in-memory, file, configmap.")
+	flag.StringVar(&gateStoragePath, "gate-storage-path", "/data/gates.json", "Path to the gate storage file.")
+	flag.StringVar(&gateStorageNamespace, "gate-storage-namespace", "", "Namespace of the gate storage ConfigMap. Defaults to the POD_NAMESPACE env var.")
+	flag.StringVar(&gateStorageName, "gate-storage-name", "flagger-loadtester-gates", "Name of the gate storage ConfigMap.")
+	flag.StringVar(&gateTokensFile, "gate-tokens-file", "", "Path to a JSON file mapping the tokens to the user names used to authenticate the gate API callers.")
+	flag.StringVar(&tlsPort, "tls-port", "9443", "HTTPS port to listen on when a TLS certificate is specified.")
+	flag.StringVar(&tlsCertFile, "tls-cert-file", "", "Path to the TLS certificate.")
+	flag.StringVar(&tlsKeyFile, "tls-key-file", "", "Path to the TLS private key.")
+	flag.StringVar(&tlsClientCAFile, "tls-client-ca-file", "", "Path to the CA used to verify client certificates of the gate API callers.")
 }
 
 func main() {
@@ -49,6 +73,69 @@ func main() {
 
 	logger.Infof("Starting load tester v%s API on port %s", VERSION, port)
 
-	gateStorage := loadtester.NewGateStorage("in-memory")
-	loadtester.ListenAndServe(port, time.Minute, logger, taskRunner, gateStorage, stopCh)
+	gateStorage := initGateStorage(logger)
+	logger.Infof("Using %s gate storage", gateStorage.Backend())
+
+	if tlsClientCAFile != "" && tlsCertFile == "" {
+		logger.Fatalf("The -tls-client-ca-file flag requires -tls-cert-file and -tls-key-file")
+	}
+
+	var tokens map[string]string
+	if gateTokensFile != "" {
+		tokens, err = loadtester.LoadGateTokens(gateTokensFile)
+		if err != nil {
+			logger.Fatalf("Error loading gate tokens: %v", err)
+		}
+	}
+	gateAuth := loadtester.NewGateAuthenticator(tokens, tlsClientCAFile != "")
+	if gateAuth.Enabled() {
+		logger.Info("Gate API authentication enabled")
+	}
+
+	var tlsOpts *loadtester.TLSOptions
+	if tlsCertFile != "" {
+		tlsOpts = &loadtester.TLSOptions{
+			Port:         tlsPort,
+			CertFile:     tlsCertFile,
+			KeyFile:      tlsKeyFile,
+			ClientCAFile: tlsClientCAFile,
+		}
+		logger.Infof("Starting load tester HTTPS API on port %s", tlsPort)
+	}
+
+	loadtester.ListenAndServe(port, time.Minute, logger, taskRunner, gateStorage, gateAuth, tlsOpts, stopCh)
+}
+
+func initGateStorage(logger *zap.SugaredLogger) *loadtester.GateStorage {
+	switch gateStorage {
+	case "in-memory":
+		return loadtester.NewGateStorage(gateStorage)
+	case "file":
+		store, err := loadtester.NewFileGateStore(gateStoragePath)
+		if err != nil {
+			logger.Fatalf("Error creating file gate storage: %v", err)
+		}
+		return loadtester.NewGateStorageWithStore(gateStorage, store)
+	case "configmap":
+		cfg, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+		if err != nil {
+			logger.Fatalf("Error building kubeconfig: %v", err)
+		}
+		kubeClient, err := kubernetes.NewForConfig(cfg)
+		if err != nil {
+			logger.Fatalf("Error building kubernetes clientset: %v", err)
+		}
+		ns := gateStorageNamespace
+		if ns == "" {
+			ns = os.Getenv("POD_NAMESPACE")
+		}
+		store, err := loadtester.NewConfigMapGateStore(kubeClient, ns, gateStorageName)
+		if err != nil {
+			logger.Fatalf("Error creating configmap gate storage: %v", err)
+		}
+		return loadtester.NewGateStorageWithStore(gateStorage, store)
+	default:
+		logger.Fatalf("Gate storage %s not supported", gateStorage)
+	}
+	return nil
 }
@@ -7,7 +7,10 @@ require (
 	github.com/aws/aws-sdk-go v1.30.19
 	github.com/davecgh/go-spew v1.1.1
 	github.com/google/go-cmp v0.4.0
+	github.com/nats-io/nats-server/v2 v2.1.7
+	github.com/nats-io/nats.go v1.10.0
 	github.com/prometheus/client_golang v1.5.1
+	github.com/segmentio/kafka-go v0.3.6
 	github.com/stretchr/testify v1.5.1
 	go.uber.org/zap v1.14.1
 	gopkg.in/h2non/gock.v1 v1.0.15
@@ -15,6 +18,7 @@ require (
 	k8s.io/apimachinery v0.18.2
 	k8s.io/client-go v0.18.2
 	k8s.io/code-generator v0.18.2
+	sigs.k8s.io/yaml v1.2.0
 )
 
 replace k8s.io/klog => github.com/stefanprodan/klog v0.0.0-20190418165334-9cbb78b20423
@@ -0,0 +1,271 @@
+/*
+Copyright 2020 The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
+)
+
+const (
+	CanaryKind = "Canary"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Canary is the configuration for a canary release,
+// which automatically manages the bootstrap, analysis, traffic shifting,
+// promotion or rollback of an app revision
+type Canary struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CanarySpec   `json:"spec"`
+	Status CanaryStatus `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CanaryList is a list of Canary resources
+type CanaryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []Canary `json:"items"`
+}
+
+// CanarySpec is the specification of the desired behavior of the Canary
+type CanarySpec struct {
+	// Provider overwrites the -mesh-provider flag for this particular canary
+	// +optional
+	Provider string `json:"provider,omitempty"`
+
+	// MetricsServer overwrites the -metrics-server flag for this particular canary
+	// +optional
+	MetricsServer string `json:"metricsServer,omitempty"`
+
+	// TargetRef references a target resource
+	TargetRef v1beta1.CrossNamespaceObjectReference `json:"targetRef"`
+
+	// AutoscalerRef references an autoscaling resource
+	// +optional
+	AutoscalerRef *v1beta1.CrossNamespaceObjectReference `json:"autoscalerRef,omitempty"`
+
+	// Reference to NGINX ingress resource
+	// +optional
+	IngressRef *v1beta1.CrossNamespaceObjectReference `json:"ingressRef,omitempty"`
+
+	// CompanionRefs references PodDisruptionBudgets, NetworkPolicies and ServiceMonitors
+	// that are cloned and relabeled for the primary workload
+	// +optional
+	CompanionRefs []v1beta1.CrossNamespaceObjectReference `json:"companionRefs,omitempty"`
+
+	// ConfigTracking defines how the ConfigMaps and Secrets referenced by the target are copied for the primary
+	// +optional
+	ConfigTracking *v1beta1.CanaryConfigTracking `json:"configTracking,omitempty"`
+
+	// Service defines how ClusterIP services, service mesh or ingress routing objects are generated
+	Service CanaryService `json:"service"`
+
+	// Analysis defines the validation process of a release
+	// +optional
+	Analysis *v1beta1.CanaryAnalysis `json:"analysis,omitempty"`
+
+	// TemplateRef references a CanaryTemplate or ClusterCanaryTemplate
+	// merged into the canary spec at reconcile time
+	// +optional
+	TemplateRef *v1beta1.CanaryTemplateRef `json:"templateRef,omitempty"`
+
+	// ProgressDeadlineSeconds represents the maximum time in seconds for a
+	// canary deployment to make progress before it is considered to be failed
+	// +optional
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+
+	// RevisionHistoryLimit is the number of promoted revisions kept for rollback
+	// Defaults to 10
+	// +optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// IgnoredChanges lists the pod template changes applied to the primary without analysis
+	// +optional
+	IgnoredChanges *v1beta1.CanaryIgnoredChanges `json:"ignoredChanges,omitempty"`
+
+	// SkipAnalysis promotes the canary without analysing it
+	// +optional
+	SkipAnalysis bool `json:"skipAnalysis,omitempty"`
+
+	// revert canary mutation on deletion of canary resource
+	// +optional
+	RevertOnDeletion bool `json:"revertOnDeletion,omitempty"`
+}
+
+// CanaryService defines how ClusterIP services, service mesh or ingress routing objects are generated,
+// the routing options that apply to a single provider are grouped under the provider field
+type CanaryService struct {
+	// Name of the Kubernetes service generated by Flagger
+	// Defaults to CanarySpec.TargetRef.Name
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Port of the generated Kubernetes service
+	Port int32 `json:"port"`
+
+	// Port name of the generated Kubernetes service
+	// Defaults to http
+	// +optional
+	PortName string `json:"portName,omitempty"`
+
+	// Target port number or name of the generated Kubernetes service
+	// Defaults to CanaryService.Port
+	// +optional
+	TargetPort intstr.IntOrString `json:"targetPort,omitempty"`
+
+	// PortDiscovery adds all container ports to the generated Kubernetes service
+	// +optional
+	PortDiscovery bool `json:"portDiscovery,omitempty"`
+
+	// Timeout of the HTTP or gRPC request
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+
+	// Gateways attached to the generated Istio virtual service or SMI traffic split
+	// Defaults to the internal mesh gateway
+	// +optional
+	Gateways []string `json:"gateways,omitempty"`
+
+	// Hosts attached to the generated routing objects
+	// Defaults to the service name
+	// +optional
+	Hosts []string `json:"hosts,omitempty"`
+
+	// URI match conditions for the generated service
+	// +optional
+	Match []istiov1alpha3.HTTPMatchRequest `json:"match,omitempty"`
+
+	// Retries policy for the generated service
+	// +optional
+	Retries *istiov1alpha3.HTTPRetry `json:"retries,omitempty"`
+
+	// Istio routing options of the generated virtual service and destination rules
+	// +optional
+	Istio *IstioService `json:"istio,omitempty"`
+
+	// AppMesh routing options of the generated virtual nodes and virtual service
+	// +optional
+	AppMesh *AppMeshService `json:"appMesh,omitempty"`
+
+	// Apex is metadata to add to the apex service
+	// +optional
+	Apex *v1beta1.CustomMetadata `json:"apex,omitempty"`
+
+	// Primary is the metadata to add to the primary service
+	// +optional
+	Primary *v1beta1.CustomMetadata `json:"primary,omitempty"`
+
+	// Canary is the metadata to add to the canary service
+	// +optional
+	Canary *v1beta1.CustomMetadata `json:"canary,omitempty"`
+}
+
+// IstioService holds the routing options specific to Istio
+type IstioService struct {
+	// TrafficPolicy attached to the generated Istio destination rules
+	// +optional
+	TrafficPolicy *istiov1alpha3.TrafficPolicy `json:"trafficPolicy,omitempty"`
+
+	// Rewrite HTTP URIs for the generated virtual service
+	// +optional
+	Rewrite *istiov1alpha3.HTTPRewrite `json:"rewrite,omitempty"`
+
+	// Headers operations for the generated virtual service
+	// +optional
+	Headers *istiov1alpha3.Headers `json:"headers,omitempty"`
+
+	// Cross-Origin Resource Sharing policy for the generated virtual service
+	// +optional
+	CorsPolicy *istiov1alpha3.CorsPolicy `json:"corsPolicy,omitempty"`
+}
+
+// AppMeshService holds the routing options specific to App Mesh
+type AppMeshService struct {
+	// Mesh name of the generated virtual nodes and virtual service
+	// +optional
+	MeshName string `json:"meshName,omitempty"`
+
+	// Backends of the generated virtual nodes
+	// +optional
+	Backends []string `json:"backends,omitempty"`
+}
+
+// CanaryStatus is used for state persistence (read-only)
+type CanaryStatus struct {
+	Phase v1beta1.CanaryPhase `json:"phase"`
+
+	// Analysis holds the progress of the current analysis
+	// +optional
+	Analysis CanaryAnalysisStatus `json:"analysis,omitempty"`
+
+	// Revision holds the last applied and promoted revisions
+	// +optional
+	Revision CanaryRevisionStatus `json:"revision,omitempty"`
+
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// +optional
+	Conditions []v1beta1.CanaryCondition `json:"conditions,omitempty"`
+}
+
+// CanaryAnalysisStatus is the progress of the current analysis
+type CanaryAnalysisStatus struct {
+	// FailedChecks is the number of failed checks of the current analysis
+	FailedChecks int `json:"failedChecks"`
+
+	// CanaryWeight is the traffic percentage routed to the canary
+	CanaryWeight int `json:"canaryWeight"`
+
+	// Iterations is the number of checks run for A/B testing, Blue/Green and shadow analysis
+	Iterations int `json:"iterations"`
+
+	// Metrics holds the last measured values of the analysis metrics
+	// +optional
+	Metrics []v1beta1.CanaryMetricStatus `json:"metrics,omitempty"`
+}
+
+// CanaryRevisionStatus holds the revisions tracked by Flagger
+type CanaryRevisionStatus struct {
+	// LastAppliedSpec is the hash of the last applied canary pod template
+	// +optional
+	LastAppliedSpec string `json:"lastAppliedSpec,omitempty"`
+
+	// LastPromotedSpec is the hash of the last promoted canary pod template
+	// +optional
+	LastPromotedSpec string `json:"lastPromotedSpec,omitempty"`
+
+	// RollbackRevision is the rollback-to-revision annotation value applied to the primary,
+	// it is set while the rollback waits for the primary to become ready
+	// +optional
+	RollbackRevision string `json:"rollbackRevision,omitempty"`
+
+	// TrackedConfigs holds the checksums of the tracked ConfigMaps and Secrets
+	// +optional
+	TrackedConfigs map[string]string `json:"trackedConfigs,omitempty"`
+}
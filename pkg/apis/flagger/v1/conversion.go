@@ -0,0 +1,175 @@
+/*
+Copyright 2020 The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// ConvertFromV1beta1 returns the v1 representation of a v1beta1 canary,
+// the deprecated spec.canaryAnalysis is converted to spec.analysis
+func ConvertFromV1beta1(in *v1beta1.Canary) *Canary {
+	in = in.DeepCopy()
+	out := &Canary{
+		ObjectMeta: in.ObjectMeta,
+	}
+	out.APIVersion = SchemeGroupVersion.String()
+	out.Kind = CanaryKind
+
+	out.Spec = CanarySpec{
+		Provider:                in.Spec.Provider,
+		MetricsServer:           in.Spec.MetricsServer,
+		TargetRef:               in.Spec.TargetRef,
+		AutoscalerRef:           in.Spec.AutoscalerRef,
+		IngressRef:              in.Spec.IngressRef,
+		CompanionRefs:           in.Spec.CompanionRefs,
+		ConfigTracking:          in.Spec.ConfigTracking,
+		Analysis:                in.GetAnalysis(),
+		TemplateRef:             in.Spec.TemplateRef,
+		ProgressDeadlineSeconds: in.Spec.ProgressDeadlineSeconds,
+		RevisionHistoryLimit:    in.Spec.RevisionHistoryLimit,
+		IgnoredChanges:          in.Spec.IgnoredChanges,
+		SkipAnalysis:            in.Spec.SkipAnalysis,
+		RevertOnDeletion:        in.Spec.RevertOnDeletion,
+	}
+
+	svc := in.Spec.Service
+	out.Spec.Service = CanaryService{
+		Name:          svc.Name,
+		Port:          svc.Port,
+		PortName:      svc.PortName,
+		TargetPort:    svc.TargetPort,
+		PortDiscovery: svc.PortDiscovery,
+		Timeout:       svc.Timeout,
+		Gateways:      svc.Gateways,
+		Hosts:         svc.Hosts,
+		Match:         svc.Match,
+		Retries:       svc.Retries,
+		Apex:          svc.Apex,
+		Primary:       svc.Primary,
+		Canary:        svc.Canary,
+	}
+	if svc.TrafficPolicy != nil || svc.Rewrite != nil || svc.Headers != nil || svc.CorsPolicy != nil {
+		out.Spec.Service.Istio = &IstioService{
+			TrafficPolicy: svc.TrafficPolicy,
+			Rewrite:       svc.Rewrite,
+			Headers:       svc.Headers,
+			CorsPolicy:    svc.CorsPolicy,
+		}
+	}
+	if svc.MeshName != "" || svc.Backends != nil {
+		out.Spec.Service.AppMesh = &AppMeshService{
+			MeshName: svc.MeshName,
+			Backends: svc.Backends,
+		}
+	}
+
+	out.Status = CanaryStatus{
+		Phase: in.Status.Phase,
+		Analysis: CanaryAnalysisStatus{
+			FailedChecks: in.Status.FailedChecks,
+			CanaryWeight: in.Status.CanaryWeight,
+			Iterations:   in.Status.Iterations,
+			Metrics:      in.Status.Metrics,
+		},
+		Revision: CanaryRevisionStatus{
+			LastAppliedSpec:  in.Status.LastAppliedSpec,
+			LastPromotedSpec: in.Status.LastPromotedSpec,
+			RollbackRevision: in.Status.RollbackRevision,
+		},
+		LastTransitionTime: in.Status.LastTransitionTime,
+		Conditions:         in.Status.Conditions,
+	}
+	if in.Status.TrackedConfigs != nil {
+		out.Status.Revision.TrackedConfigs = *in.Status.TrackedConfigs
+	}
+
+	return out
+}
+
+// ToV1beta1 returns the v1beta1 representation of the canary
+func (c *Canary) ToV1beta1() *v1beta1.Canary {
+	in := c.DeepCopy()
+	out := &v1beta1.Canary{
+		ObjectMeta: in.ObjectMeta,
+	}
+	out.APIVersion = v1beta1.SchemeGroupVersion.String()
+	out.Kind = v1beta1.CanaryKind
+
+	out.Spec = v1beta1.CanarySpec{
+		Provider:                in.Spec.Provider,
+		MetricsServer:           in.Spec.MetricsServer,
+		TargetRef:               in.Spec.TargetRef,
+		AutoscalerRef:           in.Spec.AutoscalerRef,
+		IngressRef:              in.Spec.IngressRef,
+		CompanionRefs:           in.Spec.CompanionRefs,
+		ConfigTracking:          in.Spec.ConfigTracking,
+		Analysis:                in.Spec.Analysis,
+		TemplateRef:             in.Spec.TemplateRef,
+		ProgressDeadlineSeconds: in.Spec.ProgressDeadlineSeconds,
+		RevisionHistoryLimit:    in.Spec.RevisionHistoryLimit,
+		IgnoredChanges:          in.Spec.IgnoredChanges,
+		SkipAnalysis:            in.Spec.SkipAnalysis,
+		RevertOnDeletion:        in.Spec.RevertOnDeletion,
+	}
+
+	svc := in.Spec.Service
+	out.Spec.Service = v1beta1.CanaryService{
+		Name:          svc.Name,
+		Port:          svc.Port,
+		PortName:      svc.PortName,
+		TargetPort:    svc.TargetPort,
+		PortDiscovery: svc.PortDiscovery,
+		Timeout:       svc.Timeout,
+		Gateways:      svc.Gateways,
+		Hosts:         svc.Hosts,
+		Match:         svc.Match,
+		Retries:       svc.Retries,
+		Apex:          svc.Apex,
+		Primary:       svc.Primary,
+		Canary:        svc.Canary,
+	}
+	if istio := svc.Istio; istio != nil {
+		out.Spec.Service.TrafficPolicy = istio.TrafficPolicy
+		out.Spec.Service.Rewrite = istio.Rewrite
+		out.Spec.Service.Headers = istio.Headers
+		out.Spec.Service.CorsPolicy = istio.CorsPolicy
+	}
+	if appMesh := svc.AppMesh; appMesh != nil {
+		out.Spec.Service.MeshName = appMesh.MeshName
+		out.Spec.Service.Backends = appMesh.Backends
+	}
+
+	out.Status = v1beta1.CanaryStatus{
+		Phase:              in.Status.Phase,
+		FailedChecks:       in.Status.Analysis.FailedChecks,
+		CanaryWeight:       in.Status.Analysis.CanaryWeight,
+		Iterations:         in.Status.Analysis.Iterations,
+		LastAppliedSpec:    in.Status.Revision.LastAppliedSpec,
+		LastPromotedSpec:   in.Status.Revision.LastPromotedSpec,
+		RollbackRevision:   in.Status.Revision.RollbackRevision,
+		LastTransitionTime: in.Status.LastTransitionTime,
+		Conditions:         in.Status.Conditions,
+		Metrics:            in.Status.Analysis.Metrics,
+	}
+	if in.Status.Revision.TrackedConfigs != nil {
+		trackedConfigs := in.Status.Revision.TrackedConfigs
+		out.Status.TrackedConfigs = &trackedConfigs
+	}
+
+	return out
+}
@@ -0,0 +1,140 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
+)
+
+func newTestV1beta1Canary() *v1beta1.Canary {
+	trackedConfigs := map[string]string{"configmap/podinfo-config-env": "abc"}
+	return &v1beta1.Canary{
+		TypeMeta: metav1.TypeMeta{APIVersion: "flagger.app/v1beta1", Kind: "Canary"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "podinfo",
+			Namespace:   "default",
+			Annotations: map[string]string{"test": "test"},
+		},
+		Spec: v1beta1.CanarySpec{
+			Provider: "istio",
+			TargetRef: v1beta1.CrossNamespaceObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "podinfo",
+			},
+			Service: v1beta1.CanaryService{
+				Port:       9898,
+				TargetPort: intstr.FromString("http"),
+				Timeout:    "15s",
+				Gateways:   []string{"public-gateway"},
+				Hosts:      []string{"app.example.com"},
+				TrafficPolicy: &istiov1alpha3.TrafficPolicy{
+					TLS: &istiov1alpha3.TLSSettings{Mode: "ISTIO_MUTUAL"},
+				},
+				Rewrite:    &istiov1alpha3.HTTPRewrite{Uri: "/"},
+				Retries:    &istiov1alpha3.HTTPRetry{Attempts: 3},
+				CorsPolicy: &istiov1alpha3.CorsPolicy{AllowOrigin: []string{"example.com"}},
+				MeshName:   "global",
+				Backends:   []string{"backend.default"},
+				Apex:       &v1beta1.CustomMetadata{Labels: map[string]string{"test": "test"}},
+			},
+			Analysis: &v1beta1.CanaryAnalysis{
+				Interval:   "1m",
+				Threshold:  5,
+				MaxWeight:  50,
+				StepWeight: 10,
+				Metrics: []v1beta1.CanaryMetric{
+					{Name: "request-success-rate", Interval: "1m"},
+				},
+			},
+			TemplateRef: &v1beta1.CanaryTemplateRef{Name: "podinfo-template"},
+		},
+		Status: v1beta1.CanaryStatus{
+			Phase:            v1beta1.CanaryPhaseProgressing,
+			FailedChecks:     1,
+			CanaryWeight:     20,
+			Iterations:       2,
+			TrackedConfigs:   &trackedConfigs,
+			LastAppliedSpec:  "1234",
+			LastPromotedSpec: "5678",
+			RollbackRevision: "0",
+			Metrics: []v1beta1.CanaryMetricStatus{
+				{Name: "request-success-rate", Value: "99.50"},
+			},
+			Conditions: []v1beta1.CanaryCondition{
+				{Type: v1beta1.PromotedType, Status: corev1.ConditionUnknown, Reason: "Progressing"},
+			},
+		},
+	}
+}
+
+func TestConvertFromV1beta1(t *testing.T) {
+	in := newTestV1beta1Canary()
+	out := ConvertFromV1beta1(in)
+
+	assert.Equal(t, "flagger.app/v1", out.APIVersion)
+	assert.Equal(t, "Canary", out.Kind)
+	assert.Equal(t, in.ObjectMeta, out.ObjectMeta)
+	assert.Equal(t, in.Spec.Analysis, out.Spec.Analysis)
+	assert.Equal(t, in.Spec.Service.Hosts, out.Spec.Service.Hosts)
+	assert.Equal(t, in.Spec.Service.TrafficPolicy, out.Spec.Service.Istio.TrafficPolicy)
+	assert.Equal(t, in.Spec.Service.CorsPolicy, out.Spec.Service.Istio.CorsPolicy)
+	assert.Nil(t, out.Spec.Service.Istio.Headers)
+	assert.Equal(t, "global", out.Spec.Service.AppMesh.MeshName)
+	assert.Equal(t, 20, out.Status.Analysis.CanaryWeight)
+	assert.Equal(t, 1, out.Status.Analysis.FailedChecks)
+	assert.Equal(t, 2, out.Status.Analysis.Iterations)
+	assert.Equal(t, in.Status.Metrics, out.Status.Analysis.Metrics)
+	assert.Equal(t, "5678", out.Status.Revision.LastPromotedSpec)
+	assert.Equal(t, "0", out.Status.Revision.RollbackRevision)
+	assert.Equal(t, "abc", out.Status.Revision.TrackedConfigs["configmap/podinfo-config-env"])
+
+	// the conversion must not mutate the source object
+	assert.Equal(t, newTestV1beta1Canary(), in)
+}
+
+func TestConvertFromV1beta1_DeprecatedAnalysis(t *testing.T) {
+	in := newTestV1beta1Canary()
+	in.Spec.CanaryAnalysis = in.Spec.Analysis
+	in.Spec.Analysis = nil
+
+	out := ConvertFromV1beta1(in)
+	assert.Equal(t, in.Spec.CanaryAnalysis, out.Spec.Analysis)
+
+	back := out.ToV1beta1()
+	assert.Equal(t, in.Spec.CanaryAnalysis, back.Spec.Analysis)
+	assert.Nil(t, back.Spec.CanaryAnalysis)
+}
+
+func TestConversion_RoundTripV1beta1(t *testing.T) {
+	in := newTestV1beta1Canary()
+	assert.Equal(t, in, ConvertFromV1beta1(in).ToV1beta1())
+
+	in.Spec.Service = v1beta1.CanaryService{Port: 9898}
+	in.Status = v1beta1.CanaryStatus{Phase: v1beta1.CanaryPhaseInitializing}
+	out := ConvertFromV1beta1(in)
+	assert.Nil(t, out.Spec.Service.Istio)
+	assert.Nil(t, out.Spec.Service.AppMesh)
+	assert.Nil(t, out.Status.Revision.TrackedConfigs)
+	assert.Equal(t, in, out.ToV1beta1())
+}
+
+func TestConversion_RoundTripV1(t *testing.T) {
+	in := ConvertFromV1beta1(newTestV1beta1Canary())
+	in.Spec.Service.Istio.Headers = &istiov1alpha3.Headers{
+		Request: &istiov1alpha3.HeaderOperations{Add: map[string]string{"x-test": "test"}},
+	}
+	in.Spec.Service.AppMesh = nil
+
+	out := in.ToV1beta1()
+	assert.Equal(t, "flagger.app/v1beta1", out.APIVersion)
+	assert.Equal(t, in.Spec.Service.Istio.Headers, out.Spec.Service.Headers)
+	assert.Empty(t, out.Spec.Service.MeshName)
+	assert.Equal(t, in, ConvertFromV1beta1(out))
+}
@@ -30,6 +30,69 @@ const (
 	ProgressDeadlineSeconds = 600
 	AnalysisInterval        = 60 * time.Second
 	MetricInterval          = "1m"
+	RevisionHistoryLimit    = 10
+	ShadowIterations        = 10
+)
+
+const (
+	// RollbackRevisionAnnotation requests the rollback of the primary to a promoted revision number
+	RollbackRevisionAnnotation = "flagger.app/rollback-to-revision"
+	// PromoteAnnotation requests the promotion of the canary under analysis
+	// without running the remaining checks, the annotation is removed once applied
+	PromoteAnnotation = "flagger.app/promote"
+	// AbortAnnotation requests the rollback of the canary under analysis,
+	// the annotation is removed once applied
+	AbortAnnotation = "flagger.app/abort"
+	// PauseAnnotation halts the canary advancement while its value is true
+	PauseAnnotation = "flagger.app/pause"
+	// FleetHoldAnnotation is set by a canary fleet to prevent the analysis of a new revision
+	// until the wave of the cluster starts, the value is the fleet namespace/name
+	FleetHoldAnnotation = "flagger.app/fleet-hold"
+)
+
+const (
+	// TrackConfigMapsAnnotation lists the ConfigMaps tracked in addition to the ones
+	// referenced by the target pod spec, in the format [namespace/]name
+	TrackConfigMapsAnnotation = "flagger.app/track-configmaps"
+	// TrackSecretsAnnotation lists the Secrets tracked in addition to the ones
+	// referenced by the target pod spec, in the format [namespace/]name
+	TrackSecretsAnnotation = "flagger.app/track-secrets"
+	// TrackResourcesAnnotation lists the custom resources tracked for changes,
+	// in the format kind.version.group/[namespace/]name
+	TrackResourcesAnnotation = "flagger.app/track-resources"
+	// IgnoreConfigsAnnotation lists the ConfigMaps and Secrets excluded from tracking,
+	// in the format configmap/name or secret/name
+	IgnoreConfigsAnnotation = "flagger.app/ignore-configs"
+)
+
+const (
+	// AutoCanaryLabel marks the Deployments and DaemonSets for which Flagger generates a canary,
+	// the canary is generated when the label value is enabled
+	AutoCanaryLabel = "flagger.app/auto-canary"
+	// AutoCanaryTemplateAnnotation references the template of the generated canary,
+	// in the format [CanaryTemplate/|ClusterCanaryTemplate/]name
+	AutoCanaryTemplateAnnotation = "flagger.app/canary-template"
+	// AutoCanaryPortAnnotation sets the service port of the generated canary,
+	// when missing the port is discovered from the pod template containers
+	AutoCanaryPortAnnotation = "flagger.app/canary-port"
+)
+
+const (
+	// ShardLabel assigns the canary to the Flagger instances started with the same -shard flag,
+	// the instances started without the flag own the canaries that don't have the label
+	ShardLabel = "flagger.app/shard"
+	// ShardOwnerAnnotation records the shard driving the canary analysis,
+	// another shard takes over the canary only once the analysis has finished
+	ShardOwnerAnnotation = "flagger.app/shard-owner"
+)
+
+const (
+	// KnativeServiceAPIVersion is the API version of the Knative Serving services
+	KnativeServiceAPIVersion = "serving.knative.dev/v1"
+	// KnativeProvider is the router and metrics provider used for Knative Service targets
+	KnativeProvider = "knative"
+	// KnativePrimaryRevisionAnnotation holds the name of the revision promoted by Flagger
+	KnativePrimaryRevisionAnnotation = "flagger.app/primary-revision"
 )
 
 // +genclient
@@ -77,6 +140,15 @@ type CanarySpec struct {
 	// +optional
 	IngressRef *CrossNamespaceObjectReference `json:"ingressRef,omitempty"`
 
+	// CompanionRefs references PodDisruptionBudgets, NetworkPolicies and ServiceMonitors
+	// that are cloned and relabeled for the primary workload
+	// +optional
+	CompanionRefs []CrossNamespaceObjectReference `json:"companionRefs,omitempty"`
+
+	// ConfigTracking defines how the ConfigMaps and Secrets referenced by the target are copied for the primary
+	// +optional
+	ConfigTracking *CanaryConfigTracking `json:"configTracking,omitempty"`
+
 	// Service defines how ClusterIP services, service mesh or ingress routing objects are generated
 	Service CanaryService `json:"service"`
 
@@ -86,11 +158,25 @@ type CanarySpec struct {
 	// Deprecated: replaced by Analysis
 	CanaryAnalysis *CanaryAnalysis `json:"canaryAnalysis,omitempty"`
 
+	// TemplateRef references a CanaryTemplate or ClusterCanaryTemplate
+	// merged into the canary spec at reconcile time
+	// +optional
+	TemplateRef *CanaryTemplateRef `json:"templateRef,omitempty"`
+
 	// ProgressDeadlineSeconds represents the maximum time in seconds for a
 	// canary deployment to make progress before it is considered to be failed
 	// +optional
 	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
 
+	// RevisionHistoryLimit is the number of promoted revisions kept for rollback
+	// Defaults to 10
+	// +optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// IgnoredChanges lists the pod template changes applied to the primary without analysis
+	// +optional
+	IgnoredChanges *CanaryIgnoredChanges `json:"ignoredChanges,omitempty"`
+
 	// SkipAnalysis promotes the canary without analysing it
 	// +optional
 	SkipAnalysis bool `json:"skipAnalysis,omitempty"`
@@ -100,6 +186,29 @@ type CanarySpec struct {
 	RevertOnDeletion bool `json:"revertOnDeletion,omitempty"`
 }
 
+// CanaryConfigTracking defines how the tracked ConfigMaps and Secrets are copied for the primary
+type CanaryConfigTracking struct {
+	// Versioned copies the tracked configs to immutable objects suffixed with the content checksum,
+	// the copies referenced by the revisions kept for rollback are not garbage collected
+	// +optional
+	Versioned bool `json:"versioned,omitempty"`
+}
+
+// CanaryIgnoredChanges defines the pod template paths that don't trigger a canary analysis
+type CanaryIgnoredChanges struct {
+	// AnnotationPrefixes of the pod template annotations to ignore
+	// +optional
+	AnnotationPrefixes []string `json:"annotationPrefixes,omitempty"`
+
+	// Env lists the names of the container environment variables to ignore
+	// +optional
+	Env []string `json:"env,omitempty"`
+
+	// Resources ignores the container resource requests and limits
+	// +optional
+	Resources bool `json:"resources,omitempty"`
+}
+
 // CanaryService defines how ClusterIP services, service mesh or ingress routing objects are generated
 type CanaryService struct {
 	// Name of the Kubernetes service generated by Flagger
@@ -187,7 +296,8 @@ type CanaryAnalysis struct {
 	// Schedule interval for this canary analysis
 	Interval string `json:"interval"`
 
-	// Number of checks to run for A/B Testing and Blue/Green
+	// Number of checks to run for A/B Testing, Blue/Green and shadow analysis,
+	// the shadow analysis defaults to 10 iterations
 	// +optional
 	Iterations int `json:"iterations,omitempty"`
 
@@ -224,6 +334,60 @@ type CanaryAnalysis struct {
 	// A/B testing HTTP header match conditions
 	// +optional
 	Match []istiov1alpha3.HTTPMatchRequest `json:"match,omitempty"`
+
+	// ProportionalScaling sizes the canary workload based on the traffic weight
+	// +optional
+	ProportionalScaling *CanaryProportionalScaling `json:"proportionalScaling,omitempty"`
+
+	// BakeTime is the duration the metrics are checked against the primary after promotion,
+	// if the checks fail the primary is reverted to the previous revision
+	// +optional
+	BakeTime string `json:"bakeTime,omitempty"`
+
+	// Shadow runs the analysis for the configured iterations (10 if not set) without shifting traffic
+	// and reports the verdict in the canary status without promoting the canary
+	// +optional
+	Shadow bool `json:"shadow,omitempty"`
+}
+
+// CanaryProportionalScaling is used to scale the canary and primary workloads
+// in proportion to the traffic weight they receive
+type CanaryProportionalScaling struct {
+	// MinReplicas is the replica floor of the scaled workloads
+	// Defaults to one
+	// +optional
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+
+	// Headroom is the extra capacity added to the proportional replicas in percentage
+	// +optional
+	Headroom int `json:"headroom,omitempty"`
+
+	// ScaleDownPrimary scales the primary workload down when the canary weight is over 50%
+	// +optional
+	ScaleDownPrimary bool `json:"scaleDownPrimary,omitempty"`
+}
+
+// GetReplicas returns the number of replicas needed to serve the weight
+// percentage of the traffic handled by the reference replicas
+func (s *CanaryProportionalScaling) GetReplicas(reference int32, weight int) int32 {
+	floor := s.MinReplicas
+	if floor < 1 {
+		floor = 1
+	}
+
+	headroom := s.Headroom
+	if headroom < 0 {
+		headroom = 0
+	}
+
+	replicas := int32((int64(reference)*int64(weight)*int64(100+headroom) + 9999) / 10000)
+	if replicas > reference {
+		replicas = reference
+	}
+	if replicas < floor {
+		replicas = floor
+	}
+	return replicas
 }
 
 // CanaryMetric holds the reference to metrics used for canary analysis
@@ -355,6 +519,11 @@ type CrossNamespaceObjectReference struct {
 	Namespace string `json:"namespace,omitempty"`
 }
 
+// IsKnativeService returns true if the reference points to a Knative Service
+func (r CrossNamespaceObjectReference) IsKnativeService() bool {
+	return r.Kind == "Service" && r.APIVersion == KnativeServiceAPIVersion
+}
+
 // CustomMetadata holds labels and annotations to set on generated objects.
 type CustomMetadata struct {
 	Labels      map[string]string `json:"labels,omitempty"`
@@ -416,11 +585,62 @@ func (c *Canary) GetAnalysisThreshold() int {
 	return 1
 }
 
+// GetRevisionHistoryLimit returns the number of promoted revisions kept for rollback (default 10)
+func (c *Canary) GetRevisionHistoryLimit() int {
+	if c.Spec.RevisionHistoryLimit != nil && *c.Spec.RevisionHistoryLimit > 0 {
+		return int(*c.Spec.RevisionHistoryLimit)
+	}
+	return RevisionHistoryLimit
+}
+
+// HasVersionedConfigs returns true if the primary references immutable copies of the tracked configs
+func (c *Canary) HasVersionedConfigs() bool {
+	return c.Spec.ConfigTracking != nil && c.Spec.ConfigTracking.Versioned
+}
+
 // GetMetricInterval returns the metric interval default value (1m)
 func (c *Canary) GetMetricInterval() string {
 	return MetricInterval
 }
 
+// GetProportionalScaling returns the proportional scaling settings or nil if the replicas don't follow the weight
+func (c *Canary) GetProportionalScaling() *CanaryProportionalScaling {
+	if analysis := c.GetAnalysis(); analysis != nil {
+		return analysis.ProportionalScaling
+	}
+	return nil
+}
+
+// GetBakeTime returns the post-promotion bake duration, zero means the bake period is disabled
+func (c *Canary) GetBakeTime() time.Duration {
+	analysis := c.GetAnalysis()
+	if analysis == nil || analysis.BakeTime == "" {
+		return 0
+	}
+
+	bakeTime, err := time.ParseDuration(analysis.BakeTime)
+	if err != nil || bakeTime < 0 {
+		return 0
+	}
+	return bakeTime
+}
+
+// GetBakeIterations returns the number of analysis runs that cover the bake time
+func (c *Canary) GetBakeIterations() int {
+	bakeTime := c.GetBakeTime()
+	if bakeTime == 0 {
+		return 0
+	}
+	interval := c.GetAnalysisInterval()
+	return int((bakeTime + interval - 1) / interval)
+}
+
+// IsShadow returns true if the analysis runs without traffic shifting and promotion
+func (c *Canary) IsShadow() bool {
+	analysis := c.GetAnalysis()
+	return analysis != nil && analysis.Shadow
+}
+
 // SkipAnalysis returns true if the analysis is nil
 // or if spec.SkipAnalysis is true
 func (c *Canary) SkipAnalysis() bool {
@@ -0,0 +1,143 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	CanaryFleetKind = "CanaryFleet"
+	// FleetKubeconfigKey is the key of the kubeconfig in the cluster secrets
+	FleetKubeconfigKey = "kubeconfig"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CanaryFleet rolls out a canary across clusters in waves,
+// the canary analysis of a wave must succeed in all its clusters before the next wave starts
+type CanaryFleet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CanaryFleetSpec `json:"spec"`
+	// +optional
+	Status CanaryFleetStatus `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CanaryFleetList is a list of canary fleet resources
+type CanaryFleetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []CanaryFleet `json:"items"`
+}
+
+// CanaryFleetSpec is the specification of the desired behavior of the CanaryFleet
+type CanaryFleetSpec struct {
+	// CanaryRef references the canary deployed in each cluster of the fleet,
+	// the namespace defaults to the fleet namespace
+	CanaryRef CrossNamespaceObjectReference `json:"canaryRef"`
+
+	// Clusters of the fleet
+	Clusters []CanaryFleetCluster `json:"clusters"`
+
+	// Waves of the rollout, defaults to a single wave with all the clusters
+	// +optional
+	Waves []CanaryFleetWave `json:"waves,omitempty"`
+}
+
+// CanaryFleetCluster is a member cluster of the fleet
+type CanaryFleetCluster struct {
+	// Name of the cluster
+	Name string `json:"name"`
+
+	// SecretRef references the secret containing the kubeconfig of the cluster,
+	// the cluster Flagger runs in is used if not set
+	// +optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+// CanaryFleetWave selects the clusters rolled out together
+type CanaryFleetWave struct {
+	// Name of the wave
+	Name string `json:"name"`
+
+	// Clusters of the wave selected by name
+	// +optional
+	Clusters []string `json:"clusters,omitempty"`
+
+	// Percentage of the fleet rolled out at the end of the wave,
+	// the clusters are selected in the spec order
+	// +optional
+	Percentage int `json:"percentage,omitempty"`
+}
+
+// CanaryFleetStatus is used for state persistence (read-only)
+type CanaryFleetStatus struct {
+	// Phase of the fleet rollout
+	Phase CanaryPhase `json:"phase"`
+
+	// Wave is the name of the wave being rolled out
+	// +optional
+	Wave string `json:"wave,omitempty"`
+
+	// Message explains the phase
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastTransitionTime of the phase or wave
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// Clusters holds the canary state in each cluster
+	// +optional
+	Clusters []CanaryFleetClusterStatus `json:"clusters,omitempty"`
+}
+
+// CanaryFleetClusterStatus is the canary state in a cluster of the fleet
+type CanaryFleetClusterStatus struct {
+	// Name of the cluster
+	Name string `json:"name"`
+
+	// Wave of the cluster
+	Wave string `json:"wave"`
+
+	// Phase of the canary
+	// +optional
+	Phase CanaryPhase `json:"phase,omitempty"`
+
+	// CanaryWeight is the traffic weight routed to the canary
+	// +optional
+	CanaryWeight int `json:"canaryWeight"`
+
+	// FailedChecks of the canary analysis
+	// +optional
+	FailedChecks int `json:"failedChecks"`
+
+	// Held is set while the fleet pauses the canary of the cluster
+	// +optional
+	Held bool `json:"held"`
+
+	// Message holds the error of the cluster
+	// +optional
+	Message string `json:"message,omitempty"`
+}
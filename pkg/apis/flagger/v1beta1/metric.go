@@ -83,6 +83,7 @@ type MetricTemplateModel struct {
 	Target    string `json:"target"`
 	Service   string `json:"service"`
 	Ingress   string `json:"ingress"`
+	Revision  string `json:"revision,omitempty"`
 	Interval  string `json:"interval"`
 }
 
@@ -94,6 +95,7 @@ func (mtm *MetricTemplateModel) TemplateFunctions() template.FuncMap {
 		"target":    func() string { return mtm.Target },
 		"service":   func() string { return mtm.Service },
 		"ingress":   func() string { return mtm.Ingress },
+		"revision":  func() string { return mtm.Revision },
 		"interval":  func() string { return mtm.Interval },
 	}
 }
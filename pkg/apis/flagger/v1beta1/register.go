@@ -51,6 +51,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&MetricTemplateList{},
 		&AlertProvider{},
 		&AlertProviderList{},
+		&CanaryTemplate{},
+		&CanaryTemplateList{},
+		&ClusterCanaryTemplate{},
+		&ClusterCanaryTemplateList{},
+		&CanaryFleet{},
+		&CanaryFleetList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -11,6 +11,8 @@ type CanaryConditionType string
 const (
 	// PromotedType refers to the result of the last canary analysis
 	PromotedType CanaryConditionType = "Promoted"
+	// ShadowAnalysisType refers to the verdict of the last shadow analysis
+	ShadowAnalysisType CanaryConditionType = "ShadowAnalysis"
 )
 
 // CanaryCondition is a status condition for a Canary
@@ -51,6 +53,18 @@ const (
 	CanaryPhasePromoting CanaryPhase = "Promoting"
 	// CanaryPhaseProgressing means the canary promotion is finished and traffic has been routed back to primary
 	CanaryPhaseFinalising CanaryPhase = "Finalising"
+	// CanaryPhaseBaking means the canary has been promoted and the primary
+	// is being analysed during the bake time
+	CanaryPhaseBaking CanaryPhase = "Baking"
+	// CanaryPhaseShadowing means the shadow analysis is underway
+	// and all traffic is routed to the primary
+	CanaryPhaseShadowing CanaryPhase = "Shadowing"
+	// CanaryPhaseShadowSucceeded means the shadow analysis has been successful
+	// and the canary deployment has been scaled to zero without promotion
+	CanaryPhaseShadowSucceeded CanaryPhase = "ShadowSucceeded"
+	// CanaryPhaseShadowFailed means the shadow analysis failed
+	// and the canary deployment has been scaled to zero
+	CanaryPhaseShadowFailed CanaryPhase = "ShadowFailed"
 	// CanaryPhaseSucceeded means the canary analysis has been successful
 	// and the canary deployment has been promoted
 	CanaryPhaseSucceeded CanaryPhase = "Succeeded"
@@ -77,8 +91,26 @@ type CanaryStatus struct {
 	LastAppliedSpec string `json:"lastAppliedSpec,omitempty"`
 	// +optional
 	LastPromotedSpec string `json:"lastPromotedSpec,omitempty"`
+	// RollbackRevision is the rollback-to-revision annotation value applied to the primary,
+	// it is set while the rollback waits for the primary to become ready
+	// +optional
+	RollbackRevision string `json:"rollbackRevision,omitempty"`
 	// +optional
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 	// +optional
 	Conditions []CanaryCondition `json:"conditions,omitempty"`
+	// +optional
+	Metrics []CanaryMetricStatus `json:"metrics,omitempty"`
+}
+
+// CanaryMetricStatus is the last value measured for a metric of the canary analysis
+type CanaryMetricStatus struct {
+	// Name of the metric
+	Name string `json:"name"`
+
+	// Value is the last measured value of the metric
+	Value string `json:"value"`
+
+	// LastUpdateTime of the value
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
 }
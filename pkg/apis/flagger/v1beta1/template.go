@@ -0,0 +1,100 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	CanaryTemplateKind        = "CanaryTemplate"
+	ClusterCanaryTemplateKind = "ClusterCanaryTemplate"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CanaryTemplate is a namespaced preset of canary settings referenced by canaries
+type CanaryTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CanaryTemplateSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CanaryTemplateList is a list of canary template resources
+type CanaryTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []CanaryTemplate `json:"items"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterCanaryTemplate is a cluster wide preset of canary settings referenced by canaries
+type ClusterCanaryTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CanaryTemplateSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterCanaryTemplateList is a list of cluster canary template resources
+type ClusterCanaryTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ClusterCanaryTemplate `json:"items"`
+}
+
+// CanaryTemplateSpec holds the canary settings merged into the spec of the referencing canaries
+type CanaryTemplateSpec struct {
+	// Provider overwrites the -mesh-provider flag for the canaries without a provider
+	// +optional
+	Provider string `json:"provider,omitempty"`
+
+	// MetricsServer overwrites the -metrics-server flag for the canaries without a metrics server
+	// +optional
+	MetricsServer string `json:"metricsServer,omitempty"`
+
+	// ProgressDeadlineSeconds represents the maximum time in seconds for a
+	// canary deployment to make progress before it is considered to be failed
+	// +optional
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+
+	// Analysis defaults, the fields set in the canary analysis take precedence
+	// and the metrics, webhooks and alerts are merged by name
+	// +optional
+	Analysis *CanaryAnalysis `json:"analysis,omitempty"`
+}
+
+// CanaryTemplateRef references a CanaryTemplate in the canary namespace or a ClusterCanaryTemplate
+type CanaryTemplateRef struct {
+	// Kind of the template, defaults to CanaryTemplate
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name of the template
+	Name string `json:"name"`
+}
@@ -223,6 +223,11 @@ func (in *CanaryAnalysis) DeepCopyInto(out *CanaryAnalysis) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ProportionalScaling != nil {
+		in, out := &in.ProportionalScaling, &out.ProportionalScaling
+		*out = new(CanaryProportionalScaling)
+		**out = **in
+	}
 	return
 }
 
@@ -254,6 +259,220 @@ func (in *CanaryCondition) DeepCopy() *CanaryCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryConfigTracking) DeepCopyInto(out *CanaryConfigTracking) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryConfigTracking.
+func (in *CanaryConfigTracking) DeepCopy() *CanaryConfigTracking {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryConfigTracking)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryFleet) DeepCopyInto(out *CanaryFleet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryFleet.
+func (in *CanaryFleet) DeepCopy() *CanaryFleet {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryFleet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryFleet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryFleetCluster) DeepCopyInto(out *CanaryFleetCluster) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryFleetCluster.
+func (in *CanaryFleetCluster) DeepCopy() *CanaryFleetCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryFleetCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryFleetClusterStatus) DeepCopyInto(out *CanaryFleetClusterStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryFleetClusterStatus.
+func (in *CanaryFleetClusterStatus) DeepCopy() *CanaryFleetClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryFleetClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryFleetList) DeepCopyInto(out *CanaryFleetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CanaryFleet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryFleetList.
+func (in *CanaryFleetList) DeepCopy() *CanaryFleetList {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryFleetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryFleetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryFleetSpec) DeepCopyInto(out *CanaryFleetSpec) {
+	*out = *in
+	out.CanaryRef = in.CanaryRef
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]CanaryFleetCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Waves != nil {
+		in, out := &in.Waves, &out.Waves
+		*out = make([]CanaryFleetWave, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryFleetSpec.
+func (in *CanaryFleetSpec) DeepCopy() *CanaryFleetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryFleetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryFleetStatus) DeepCopyInto(out *CanaryFleetStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]CanaryFleetClusterStatus, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryFleetStatus.
+func (in *CanaryFleetStatus) DeepCopy() *CanaryFleetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryFleetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryFleetWave) DeepCopyInto(out *CanaryFleetWave) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryFleetWave.
+func (in *CanaryFleetWave) DeepCopy() *CanaryFleetWave {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryFleetWave)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryIgnoredChanges) DeepCopyInto(out *CanaryIgnoredChanges) {
+	*out = *in
+	if in.AnnotationPrefixes != nil {
+		in, out := &in.AnnotationPrefixes, &out.AnnotationPrefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryIgnoredChanges.
+func (in *CanaryIgnoredChanges) DeepCopy() *CanaryIgnoredChanges {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryIgnoredChanges)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryList) DeepCopyInto(out *CanaryList) {
 	*out = *in
@@ -313,6 +532,39 @@ func (in *CanaryMetric) DeepCopy() *CanaryMetric {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetricStatus) DeepCopyInto(out *CanaryMetricStatus) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricStatus.
+func (in *CanaryMetricStatus) DeepCopy() *CanaryMetricStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetricStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryProportionalScaling) DeepCopyInto(out *CanaryProportionalScaling) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryProportionalScaling.
+func (in *CanaryProportionalScaling) DeepCopy() *CanaryProportionalScaling {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryProportionalScaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryService) DeepCopyInto(out *CanaryService) {
 	*out = *in
@@ -406,6 +658,16 @@ func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
 		*out = new(CrossNamespaceObjectReference)
 		**out = **in
 	}
+	if in.CompanionRefs != nil {
+		in, out := &in.CompanionRefs, &out.CompanionRefs
+		*out = make([]CrossNamespaceObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConfigTracking != nil {
+		in, out := &in.ConfigTracking, &out.ConfigTracking
+		*out = new(CanaryConfigTracking)
+		**out = **in
+	}
 	in.Service.DeepCopyInto(&out.Service)
 	if in.Analysis != nil {
 		in, out := &in.Analysis, &out.Analysis
@@ -417,11 +679,26 @@ func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
 		*out = new(CanaryAnalysis)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TemplateRef != nil {
+		in, out := &in.TemplateRef, &out.TemplateRef
+		*out = new(CanaryTemplateRef)
+		**out = **in
+	}
 	if in.ProgressDeadlineSeconds != nil {
 		in, out := &in.ProgressDeadlineSeconds, &out.ProgressDeadlineSeconds
 		*out = new(int32)
 		**out = **in
 	}
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.IgnoredChanges != nil {
+		in, out := &in.IgnoredChanges, &out.IgnoredChanges
+		*out = new(CanaryIgnoredChanges)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -457,6 +734,13 @@ func (in *CanaryStatus) DeepCopyInto(out *CanaryStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]CanaryMetricStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -470,6 +754,108 @@ func (in *CanaryStatus) DeepCopy() *CanaryStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryTemplate) DeepCopyInto(out *CanaryTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryTemplate.
+func (in *CanaryTemplate) DeepCopy() *CanaryTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryTemplateList) DeepCopyInto(out *CanaryTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CanaryTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryTemplateList.
+func (in *CanaryTemplateList) DeepCopy() *CanaryTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryTemplateRef) DeepCopyInto(out *CanaryTemplateRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryTemplateRef.
+func (in *CanaryTemplateRef) DeepCopy() *CanaryTemplateRef {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryTemplateRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryTemplateSpec) DeepCopyInto(out *CanaryTemplateSpec) {
+	*out = *in
+	if in.ProgressDeadlineSeconds != nil {
+		in, out := &in.ProgressDeadlineSeconds, &out.ProgressDeadlineSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Analysis != nil {
+		in, out := &in.Analysis, &out.Analysis
+		*out = new(CanaryAnalysis)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryTemplateSpec.
+func (in *CanaryTemplateSpec) DeepCopy() *CanaryTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryThresholdRange) DeepCopyInto(out *CanaryThresholdRange) {
 	*out = *in
@@ -546,6 +932,66 @@ func (in *CanaryWebhookPayload) DeepCopy() *CanaryWebhookPayload {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterCanaryTemplate) DeepCopyInto(out *ClusterCanaryTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCanaryTemplate.
+func (in *ClusterCanaryTemplate) DeepCopy() *ClusterCanaryTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterCanaryTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterCanaryTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterCanaryTemplateList) DeepCopyInto(out *ClusterCanaryTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterCanaryTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCanaryTemplateList.
+func (in *ClusterCanaryTemplateList) DeepCopy() *ClusterCanaryTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterCanaryTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterCanaryTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CrossNamespaceObjectReference) DeepCopyInto(out *CrossNamespaceObjectReference) {
 	*out = *in
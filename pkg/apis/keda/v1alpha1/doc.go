@@ -0,0 +1,5 @@
+// +k8s:deepcopy-gen=package
+
+// Package v1alpha1 is the v1alpha1 version of the KEDA API.
+// +groupName=keda.sh
+package v1alpha1
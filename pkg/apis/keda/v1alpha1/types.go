@@ -0,0 +1,130 @@
+package v1alpha1
+
+import (
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PausedReplicasAnnotation scales the target to the given number of replicas and pauses autoscaling
+const PausedReplicasAnnotation = "autoscaling.keda.sh/paused-replicas"
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ScaledObject is a specification for a KEDA ScaledObject resource
+type ScaledObject struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ScaledObjectSpec `json:"spec"`
+	// +optional
+	Status ScaledObjectStatus `json:"status,omitempty"`
+}
+
+// ScaledObjectSpec is the spec for a ScaledObject resource
+type ScaledObjectSpec struct {
+	ScaleTargetRef *ScaleTarget `json:"scaleTargetRef"`
+	// +optional
+	PollingInterval *int32 `json:"pollingInterval,omitempty"`
+	// +optional
+	CooldownPeriod *int32 `json:"cooldownPeriod,omitempty"`
+	// +optional
+	IdleReplicaCount *int32 `json:"idleReplicaCount,omitempty"`
+	// +optional
+	MinReplicaCount *int32 `json:"minReplicaCount,omitempty"`
+	// +optional
+	MaxReplicaCount *int32 `json:"maxReplicaCount,omitempty"`
+	// +optional
+	Advanced *AdvancedConfig `json:"advanced,omitempty"`
+
+	Triggers []ScaleTriggers `json:"triggers"`
+	// +optional
+	Fallback *Fallback `json:"fallback,omitempty"`
+}
+
+// ScaleTarget holds the a reference to the scale target Object
+type ScaleTarget struct {
+	Name string `json:"name"`
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// +optional
+	EnvSourceContainerName string `json:"envSourceContainerName,omitempty"`
+}
+
+// AdvancedConfig specifies advance scaling options
+type AdvancedConfig struct {
+	// +optional
+	HorizontalPodAutoscalerConfig *HorizontalPodAutoscalerConfig `json:"horizontalPodAutoscalerConfig,omitempty"`
+	// +optional
+	RestoreToOriginalReplicaCount bool `json:"restoreToOriginalReplicaCount,omitempty"`
+}
+
+// HorizontalPodAutoscalerConfig specifies horizontal scale config
+type HorizontalPodAutoscalerConfig struct {
+	// +optional
+	Behavior *autoscalingv2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// ScaleTriggers reference the scaler that will be used
+type ScaleTriggers struct {
+	Type string `json:"type"`
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	Metadata map[string]string `json:"metadata"`
+	// +optional
+	AuthenticationRef *ScaledObjectAuthRef `json:"authenticationRef,omitempty"`
+	// +optional
+	MetricType autoscalingv2.MetricTargetType `json:"metricType,omitempty"`
+}
+
+// ScaledObjectAuthRef points to the TriggerAuthentication or ClusterTriggerAuthentication object that
+// is used to authenticate the scaler with the environment
+type ScaledObjectAuthRef struct {
+	Name string `json:"name"`
+	// +optional
+	Kind string `json:"kind,omitempty"`
+}
+
+// Fallback is the spec for fallback options
+type Fallback struct {
+	FailureThreshold int32 `json:"failureThreshold"`
+	Replicas         int32 `json:"replicas"`
+}
+
+// ScaledObjectStatus is the status for a ScaledObject resource
+type ScaledObjectStatus struct {
+	// +optional
+	ScaleTargetKind string `json:"scaleTargetKind,omitempty"`
+	// +optional
+	OriginalReplicaCount *int32 `json:"originalReplicaCount,omitempty"`
+	// +optional
+	LastActiveTime *metav1.Time `json:"lastActiveTime,omitempty"`
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+// Condition to store the condition state
+type Condition struct {
+	Type   string                 `json:"type"`
+	Status corev1.ConditionStatus `json:"status"`
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ScaledObjectList is a list of ScaledObject resources
+type ScaledObjectList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ScaledObject `json:"items"`
+}
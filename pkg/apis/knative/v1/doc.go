@@ -0,0 +1,5 @@
+// +k8s:deepcopy-gen=package
+
+// Package v1 is the v1 version of the Knative Serving API.
+// +groupName=serving.knative.dev
+package v1
@@ -0,0 +1,186 @@
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// This file contains a subset of the Knative Serving v1 API,
+// only the fields used by Flagger to route traffic between revisions are declared.
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Service manages the lifecycle of a serverless workload
+type Service struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ServiceSpec   `json:"spec,omitempty"`
+	Status ServiceStatus `json:"status,omitempty"`
+}
+
+// ServiceSpec holds the revision template and the traffic targets
+type ServiceSpec struct {
+	// Template holds the latest specification for the revision to be stamped out
+	Template RevisionTemplateSpec `json:"template,omitempty"`
+
+	// Traffic specifies how to distribute traffic over a collection of revisions
+	// +optional
+	Traffic []TrafficTarget `json:"traffic,omitempty"`
+}
+
+// RevisionTemplateSpec describes the data a revision should have when created from a template
+type RevisionTemplateSpec struct {
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Spec RevisionSpec `json:"spec,omitempty"`
+}
+
+// TrafficTarget holds a single entry of the routing table
+type TrafficTarget struct {
+	// Tag is optionally used to expose a dedicated url for referencing this target exclusively
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// RevisionName of a specific revision to which to send this portion of traffic
+	// +optional
+	RevisionName string `json:"revisionName,omitempty"`
+
+	// ConfigurationName of a configuration to whose latest revision we will send this portion of traffic
+	// +optional
+	ConfigurationName string `json:"configurationName,omitempty"`
+
+	// LatestRevision may be optionally provided to indicate that the latest ready revision
+	// of the configuration should be used for this traffic target
+	// +optional
+	LatestRevision *bool `json:"latestRevision,omitempty"`
+
+	// Percent indicates that percentage based routing should be used
+	// +optional
+	Percent *int64 `json:"percent,omitempty"`
+
+	// URL displays the URL for accessing tagged traffic targets
+	// +optional
+	URL string `json:"url,omitempty"`
+}
+
+// ServiceStatus represents the current state of the Service
+type ServiceStatus struct {
+	// ObservedGeneration is the generation observed by the Knative controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions the latest available observations of the service state
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// LatestReadyRevisionName holds the name of the latest revision stamped out that has become ready
+	// +optional
+	LatestReadyRevisionName string `json:"latestReadyRevisionName,omitempty"`
+
+	// LatestCreatedRevisionName is the last revision that was created from the template
+	// +optional
+	LatestCreatedRevisionName string `json:"latestCreatedRevisionName,omitempty"`
+
+	// URL holds the url that will distribute traffic over the provided traffic targets
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// Traffic holds the configured traffic distribution
+	// +optional
+	Traffic []TrafficTarget `json:"traffic,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceList is a list of Knative Service resources
+type ServiceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []Service `json:"items"`
+}
+
+// +genclient
+// +genclient:onlyVerbs=get,list,watch
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Revision is an immutable snapshot of code and configuration
+type Revision struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RevisionSpec   `json:"spec,omitempty"`
+	Status RevisionStatus `json:"status,omitempty"`
+}
+
+// RevisionSpec holds the desired state of the Revision
+type RevisionSpec struct {
+	corev1.PodSpec `json:",inline"`
+
+	// ContainerConcurrency specifies the maximum allowed in-flight requests per container
+	// +optional
+	ContainerConcurrency *int64 `json:"containerConcurrency,omitempty"`
+
+	// TimeoutSeconds is the maximum duration in seconds that the request routing layer will wait
+	// for a request delivered to a container to begin replying
+	// +optional
+	TimeoutSeconds *int64 `json:"timeoutSeconds,omitempty"`
+}
+
+// RevisionStatus communicates the observed state of the Revision
+type RevisionStatus struct {
+	// ObservedGeneration is the generation observed by the Knative controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions the latest available observations of the revision state
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// ServiceName holds the name of a core Kubernetes Service resource that
+	// load balances over the pods backing this Revision
+	// +optional
+	ServiceName string `json:"serviceName,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RevisionList is a list of Knative Revision resources
+type RevisionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []Revision `json:"items"`
+}
+
+// ConditionReady is set when the resource is ready to serve traffic
+const ConditionReady = "Ready"
+
+// Condition defines a readiness condition of a Knative resource
+type Condition struct {
+	// Type of condition
+	Type string `json:"type"`
+
+	// Status of the condition, one of True, False, Unknown
+	Status corev1.ConditionStatus `json:"status"`
+
+	// Severity with which to treat failures of this type of condition
+	// +optional
+	Severity string `json:"severity,omitempty"`
+
+	// LastTransitionTime is the last time the condition transitioned from one status to another
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// The reason for the condition's last transition
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// A human readable message indicating details about the transition
+	// +optional
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,5 @@
+// +k8s:deepcopy-gen=package
+
+// Package v1 is the v1 version of the Prometheus Operator monitoring API.
+// +groupName=monitoring.coreos.com
+package v1
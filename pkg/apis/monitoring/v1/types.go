@@ -0,0 +1,123 @@
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// This file contains a subset of the Prometheus Operator monitoring v1 API,
+// only the fields used to scrape the primary workloads are declared.
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceMonitor defines monitoring for a set of services
+type ServiceMonitor struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ServiceMonitorSpec `json:"spec"`
+}
+
+// ServiceMonitorSpec contains specification parameters for a ServiceMonitor
+type ServiceMonitorSpec struct {
+	// +optional
+	JobLabel string `json:"jobLabel,omitempty"`
+	// +optional
+	TargetLabels []string `json:"targetLabels,omitempty"`
+	// +optional
+	PodTargetLabels []string `json:"podTargetLabels,omitempty"`
+
+	Endpoints []Endpoint `json:"endpoints"`
+
+	Selector metav1.LabelSelector `json:"selector"`
+	// +optional
+	NamespaceSelector NamespaceSelector `json:"namespaceSelector,omitempty"`
+	// +optional
+	SampleLimit uint64 `json:"sampleLimit,omitempty"`
+}
+
+// Endpoint defines a scrapeable endpoint serving Prometheus metrics
+type Endpoint struct {
+	// +optional
+	Port string `json:"port,omitempty"`
+	// +optional
+	TargetPort *intstr.IntOrString `json:"targetPort,omitempty"`
+	// +optional
+	Path string `json:"path,omitempty"`
+	// +optional
+	Scheme string `json:"scheme,omitempty"`
+	// +optional
+	Params map[string][]string `json:"params,omitempty"`
+	// +optional
+	Interval string `json:"interval,omitempty"`
+	// +optional
+	ScrapeTimeout string `json:"scrapeTimeout,omitempty"`
+	// +optional
+	TLSConfig *TLSConfig `json:"tlsConfig,omitempty"`
+	// +optional
+	BearerTokenFile string `json:"bearerTokenFile,omitempty"`
+	// +optional
+	BearerTokenSecret *corev1.SecretKeySelector `json:"bearerTokenSecret,omitempty"`
+	// +optional
+	HonorLabels bool `json:"honorLabels,omitempty"`
+	// +optional
+	HonorTimestamps *bool `json:"honorTimestamps,omitempty"`
+	// +optional
+	MetricRelabelConfigs []*RelabelConfig `json:"metricRelabelings,omitempty"`
+	// +optional
+	RelabelConfigs []*RelabelConfig `json:"relabelings,omitempty"`
+	// +optional
+	ProxyURL *string `json:"proxyUrl,omitempty"`
+}
+
+// TLSConfig specifies TLS configuration parameters
+type TLSConfig struct {
+	// +optional
+	CAFile string `json:"caFile,omitempty"`
+	// +optional
+	CertFile string `json:"certFile,omitempty"`
+	// +optional
+	KeyFile string `json:"keyFile,omitempty"`
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// RelabelConfig allows dynamic rewriting of the label set
+type RelabelConfig struct {
+	// +optional
+	SourceLabels []string `json:"sourceLabels,omitempty"`
+	// +optional
+	Separator string `json:"separator,omitempty"`
+	// +optional
+	TargetLabel string `json:"targetLabel,omitempty"`
+	// +optional
+	Regex string `json:"regex,omitempty"`
+	// +optional
+	Modulus uint64 `json:"modulus,omitempty"`
+	// +optional
+	Replacement string `json:"replacement,omitempty"`
+	// +optional
+	Action string `json:"action,omitempty"`
+}
+
+// NamespaceSelector is a selector for selecting either all namespaces or a list of namespaces
+type NamespaceSelector struct {
+	// +optional
+	Any bool `json:"any,omitempty"`
+	// +optional
+	MatchNames []string `json:"matchNames,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceMonitorList is a list of ServiceMonitors
+type ServiceMonitorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ServiceMonitor `json:"items"`
+}
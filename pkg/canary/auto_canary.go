@@ -0,0 +1,117 @@
+package canary
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// IsAutoCanaryEnabled returns true if the workload labels request a generated canary
+func IsAutoCanaryEnabled(labels map[string]string) bool {
+	return labels[flaggerv1.AutoCanaryLabel] == "enabled"
+}
+
+// NewAutoCanary generates the canary of a labeled workload, the canary is owned by the workload,
+// references the template from the workload annotations (or the default template) and
+// uses the service port from the annotations or the one discovered from the pod template,
+// the canary is assigned to the shard of the workload and reverts the workload when it's deleted
+func NewAutoCanary(workload metav1.Object, gvk schema.GroupVersionKind, podTemplate corev1.PodTemplateSpec,
+	defaultTemplate string) (*flaggerv1.Canary, error) {
+	annotations := workload.GetAnnotations()
+
+	templateRef, err := parseTemplateRef(annotations[flaggerv1.AutoCanaryTemplateAnnotation], defaultTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	port, err := discoverServicePort(annotations[flaggerv1.AutoCanaryPortAnnotation], podTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels map[string]string
+	if shard, ok := workload.GetLabels()[flaggerv1.ShardLabel]; ok {
+		labels = map[string]string{flaggerv1.ShardLabel: shard}
+	}
+
+	apiVersion, kind := gvk.ToAPIVersionAndKind()
+	return &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      workload.GetName(),
+			Namespace: workload.GetNamespace(),
+			Labels:    labels,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(workload, gvk),
+			},
+		},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{
+				APIVersion: apiVersion,
+				Kind:       kind,
+				Name:       workload.GetName(),
+			},
+			Service: flaggerv1.CanaryService{
+				Port: port,
+			},
+			TemplateRef: templateRef,
+			// scale the workload back up before the primary is garbage collected
+			RevertOnDeletion: true,
+		},
+	}, nil
+}
+
+// parseTemplateRef parses a template reference in the format [kind/]name
+func parseTemplateRef(value string, defaultTemplate string) (*flaggerv1.CanaryTemplateRef, error) {
+	if value == "" {
+		value = defaultTemplate
+	}
+	if value == "" {
+		return nil, fmt.Errorf("%s annotation not found and no default template set", flaggerv1.AutoCanaryTemplateAnnotation)
+	}
+
+	ref := &flaggerv1.CanaryTemplateRef{Name: value}
+	if parts := strings.SplitN(value, "/", 2); len(parts) == 2 {
+		ref.Kind, ref.Name = parts[0], parts[1]
+	}
+
+	switch ref.Kind {
+	case "", flaggerv1.CanaryTemplateKind, flaggerv1.ClusterCanaryTemplateKind:
+		return ref, nil
+	default:
+		return nil, fmt.Errorf("template kind %s not supported", ref.Kind)
+	}
+}
+
+// discoverServicePort returns the annotation port or the port named http,
+// if there is no http port the lowest container port is used
+func discoverServicePort(value string, podTemplate corev1.PodTemplateSpec) (int32, error) {
+	if value != "" {
+		port, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("%s annotation parsing failed: %w", flaggerv1.AutoCanaryPortAnnotation, err)
+		}
+		return int32(port), nil
+	}
+
+	ports := getPorts(&flaggerv1.Canary{}, podTemplate.Spec.Containers)
+	if port, ok := ports["http"]; ok {
+		return port, nil
+	}
+
+	var res int32
+	for _, port := range ports {
+		if res == 0 || port < res {
+			res = port
+		}
+	}
+	if res == 0 {
+		return 0, fmt.Errorf("no container port found, set the %s annotation", flaggerv1.AutoCanaryPortAnnotation)
+	}
+	return res, nil
+}
@@ -0,0 +1,64 @@
+package canary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewAutoCanary(t *testing.T) {
+	dep := newDeploymentControllerTest()
+	dep.Labels = map[string]string{flaggerv1.AutoCanaryLabel: "enabled"}
+	dep.Annotations = map[string]string{flaggerv1.AutoCanaryTemplateAnnotation: "ClusterCanaryTemplate/progressive"}
+	dep.Spec.Template.Spec.Containers = []corev1.Container{
+		{
+			Name: "podinfo",
+			Ports: []corev1.ContainerPort{
+				{Name: "grpc", ContainerPort: 9999},
+				{Name: "http-metrics", ContainerPort: 8888},
+			},
+		},
+		{
+			Name:  "istio-proxy",
+			Ports: []corev1.ContainerPort{{ContainerPort: 15090}},
+		},
+	}
+
+	assert.True(t, IsAutoCanaryEnabled(dep.Labels))
+
+	cd, err := NewAutoCanary(dep, appsv1.SchemeGroupVersion.WithKind("Deployment"), dep.Spec.Template, "")
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo", cd.Name)
+	assert.Equal(t, "apps/v1", cd.Spec.TargetRef.APIVersion)
+	assert.Equal(t, "Deployment", cd.Spec.TargetRef.Kind)
+	assert.Equal(t, int32(8888), cd.Spec.Service.Port)
+	assert.Equal(t, flaggerv1.ClusterCanaryTemplateKind, cd.Spec.TemplateRef.Kind)
+	assert.Equal(t, "progressive", cd.Spec.TemplateRef.Name)
+	require.Len(t, cd.OwnerReferences, 1)
+	assert.Equal(t, "Deployment", cd.OwnerReferences[0].Kind)
+
+	// http port and default template
+	dep.Annotations = nil
+	dep.Spec.Template.Spec.Containers[0].Ports = append(dep.Spec.Template.Spec.Containers[0].Ports,
+		corev1.ContainerPort{Name: "http", ContainerPort: 9898})
+	cd, err = NewAutoCanary(dep, appsv1.SchemeGroupVersion.WithKind("Deployment"), dep.Spec.Template, "default")
+	require.NoError(t, err)
+	assert.Equal(t, int32(9898), cd.Spec.Service.Port)
+	assert.Equal(t, "", cd.Spec.TemplateRef.Kind)
+	assert.Equal(t, "default", cd.Spec.TemplateRef.Name)
+
+	// port annotation
+	dep.Annotations = map[string]string{flaggerv1.AutoCanaryPortAnnotation: "8080"}
+	cd, err = NewAutoCanary(dep, appsv1.SchemeGroupVersion.WithKind("Deployment"), dep.Spec.Template, "default")
+	require.NoError(t, err)
+	assert.Equal(t, int32(8080), cd.Spec.Service.Port)
+
+	// missing template
+	_, err = NewAutoCanary(dep, appsv1.SchemeGroupVersion.WithKind("Deployment"), dep.Spec.Template, "")
+	require.Error(t, err)
+}
@@ -0,0 +1,217 @@
+package canary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	hpav2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	keda "github.com/weaveworks/flagger/pkg/apis/keda/v1alpha1"
+)
+
+const (
+	HorizontalPodAutoscalerKind = "HorizontalPodAutoscaler"
+	ScaledObjectKind            = "ScaledObject"
+)
+
+// reconcilePrimaryAutoscaler copies the autoscaler referenced by the canary and targets the primary workload
+func (c *DeploymentController) reconcilePrimaryAutoscaler(cd *flaggerv1.Canary, init bool) error {
+	switch cd.Spec.AutoscalerRef.Kind {
+	case HorizontalPodAutoscalerKind:
+		if isHpaV2(cd) {
+			return c.reconcilePrimaryHpaV2(cd, init)
+		}
+		return c.reconcilePrimaryHpa(cd, init)
+	case ScaledObjectKind:
+		return c.reconcilePrimaryScaledObject(cd, init)
+	default:
+		return fmt.Errorf("cd.Spec.AutoscalerRef.Kind is invalid: %s", cd.Spec.AutoscalerRef.Kind)
+	}
+}
+
+// isHpaV2 returns true if the autoscaler reference points to an autoscaling/v2 or autoscaling/v2beta2 HPA,
+// both versions have the same schema and are served through the autoscaling/v2beta2 API
+func isHpaV2(cd *flaggerv1.Canary) bool {
+	switch cd.Spec.AutoscalerRef.APIVersion {
+	case "autoscaling/v2", hpav2.SchemeGroupVersion.String():
+		return true
+	}
+	return false
+}
+
+// reconcilePrimaryHpaV2 copies an autoscaling/v2 HPA including the behavior policies and external metrics
+func (c *DeploymentController) reconcilePrimaryHpaV2(cd *flaggerv1.Canary, init bool) error {
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	hpa, err := c.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers(cd.Namespace).Get(context.TODO(), cd.Spec.AutoscalerRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("HorizontalPodAutoscaler %s.%s get query error: %w",
+			cd.Spec.AutoscalerRef.Name, cd.Namespace, err)
+	}
+
+	hpaSpec := *hpa.Spec.DeepCopy()
+	hpaSpec.ScaleTargetRef.Name = primaryName
+
+	primaryHpaName := fmt.Sprintf("%s-primary", cd.Spec.AutoscalerRef.Name)
+	primaryHpa, err := c.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers(cd.Namespace).Get(context.TODO(), primaryHpaName, metav1.GetOptions{})
+
+	// create HPA
+	if errors.IsNotFound(err) {
+		primaryHpa = &hpav2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            primaryHpaName,
+				Namespace:       cd.Namespace,
+				Labels:          hpa.Labels,
+				OwnerReferences: []metav1.OwnerReference{newCanaryOwnerRef(cd)},
+			},
+			Spec: hpaSpec,
+		}
+
+		_, err = c.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers(cd.Namespace).Create(context.TODO(), primaryHpa, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("creating HorizontalPodAutoscaler %s.%s failed: %w",
+				primaryHpa.Name, primaryHpa.Namespace, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Infof(
+			"HorizontalPodAutoscaler %s.%s created", primaryHpa.GetName(), cd.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("HorizontalPodAutoscaler %s.%s get query failed: %w",
+			primaryHpaName, cd.Namespace, err)
+	}
+
+	// update HPA
+	if !init && cmp.Diff(hpaSpec, primaryHpa.Spec) != "" {
+		hpaClone := primaryHpa.DeepCopy()
+		hpaClone.Spec = hpaSpec
+
+		_, err := c.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers(cd.Namespace).Update(context.TODO(), hpaClone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("updating HorizontalPodAutoscaler %s.%s failed: %w",
+				hpaClone.Name, hpaClone.Namespace, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("HorizontalPodAutoscaler %s.%s updated", primaryHpa.GetName(), cd.Namespace)
+	}
+	return nil
+}
+
+// reconcilePrimaryScaledObject copies a KEDA ScaledObject and targets the primary workload
+func (c *DeploymentController) reconcilePrimaryScaledObject(cd *flaggerv1.Canary, init bool) error {
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	so, err := c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.Namespace).Get(context.TODO(), cd.Spec.AutoscalerRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ScaledObject %s.%s get query error: %w",
+			cd.Spec.AutoscalerRef.Name, cd.Namespace, err)
+	}
+
+	soSpec := *so.Spec.DeepCopy()
+	if soSpec.ScaleTargetRef == nil {
+		return fmt.Errorf("ScaledObject %s.%s spec.scaleTargetRef is missing", so.Name, so.Namespace)
+	}
+	soSpec.ScaleTargetRef.Name = primaryName
+
+	// KEDA names the HPA it manages, a custom name would clash with the one of the canary
+	if soSpec.Advanced != nil && soSpec.Advanced.HorizontalPodAutoscalerConfig != nil &&
+		soSpec.Advanced.HorizontalPodAutoscalerConfig.Name != "" {
+		soSpec.Advanced.HorizontalPodAutoscalerConfig.Name = fmt.Sprintf("%s-primary", soSpec.Advanced.HorizontalPodAutoscalerConfig.Name)
+	}
+
+	primarySoName := fmt.Sprintf("%s-primary", cd.Spec.AutoscalerRef.Name)
+	primarySo, err := c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.Namespace).Get(context.TODO(), primarySoName, metav1.GetOptions{})
+
+	// create ScaledObject
+	if errors.IsNotFound(err) {
+		primarySo = &keda.ScaledObject{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            primarySoName,
+				Namespace:       cd.Namespace,
+				Labels:          so.Labels,
+				OwnerReferences: []metav1.OwnerReference{newCanaryOwnerRef(cd)},
+			},
+			Spec: soSpec,
+		}
+
+		_, err = c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.Namespace).Create(context.TODO(), primarySo, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("creating ScaledObject %s.%s failed: %w",
+				primarySo.Name, primarySo.Namespace, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Infof(
+			"ScaledObject %s.%s created", primarySo.GetName(), cd.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("ScaledObject %s.%s get query failed: %w",
+			primarySoName, cd.Namespace, err)
+	}
+
+	// update ScaledObject, the spec is replaced with a patch so that the metadata
+	// and status fields not modelled by Flagger are left untouched
+	if !init && cmp.Diff(soSpec, primarySo.Spec) != "" {
+		patch, err := json.Marshal([]map[string]interface{}{
+			{"op": "replace", "path": "/spec", "value": soSpec},
+		})
+		if err != nil {
+			return fmt.Errorf("ScaledObject %s.%s patch encoding failed: %w", primarySoName, cd.Namespace, err)
+		}
+
+		_, err = c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.Namespace).Patch(context.TODO(), primarySoName, types.JSONPatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			return fmt.Errorf("updating ScaledObject %s.%s failed: %w",
+				primarySoName, cd.Namespace, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("ScaledObject %s.%s updated", primarySo.GetName(), cd.Namespace)
+	}
+	return nil
+}
+
+// setScaledObjectPausedReplicas sets the KEDA paused replicas annotation on a ScaledObject,
+// while paused KEDA keeps the workload at the given replicas, a nil value resumes the autoscaling,
+// the annotation is set with a merge patch so that the fields not modelled by Flagger are left untouched
+func (c *DeploymentController) setScaledObjectPausedReplicas(cd *flaggerv1.Canary, name string, replicas *int32) error {
+	so, err := c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ScaledObject %s.%s get query error: %w", name, cd.Namespace, err)
+	}
+
+	current, found := so.Annotations[keda.PausedReplicasAnnotation]
+	if (replicas == nil && !found) || (replicas != nil && current == fmt.Sprint(*replicas)) {
+		return nil
+	}
+
+	var value interface{}
+	if replicas != nil {
+		value = fmt.Sprint(*replicas)
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				keda.PausedReplicasAnnotation: value,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("ScaledObject %s.%s patch encoding failed: %w", name, cd.Namespace, err)
+	}
+
+	_, err = c.flaggerClient.KedaV1alpha1().ScaledObjects(cd.Namespace).Patch(context.TODO(), name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("ScaledObject %s.%s update query error: %w", name, cd.Namespace, err)
+	}
+	return nil
+}
+
+func newCanaryOwnerRef(cd *flaggerv1.Canary) metav1.OwnerReference {
+	return *metav1.NewControllerRef(cd, schema.GroupVersionKind{
+		Group:   flaggerv1.SchemeGroupVersion.Group,
+		Version: flaggerv1.SchemeGroupVersion.Version,
+		Kind:    flaggerv1.CanaryKind,
+	})
+}
@@ -0,0 +1,177 @@
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8stesting "k8s.io/client-go/testing"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	keda "github.com/weaveworks/flagger/pkg/apis/keda/v1alpha1"
+	fakeFlagger "github.com/weaveworks/flagger/pkg/client/clientset/versioned/fake"
+)
+
+func TestDeploymentController_ScaledObject(t *testing.T) {
+	mocks := newDeploymentFixture()
+	mocks.canary.Spec.AutoscalerRef = &flaggerv1.CrossNamespaceObjectReference{
+		APIVersion: keda.SchemeGroupVersion.String(),
+		Kind:       ScaledObjectKind,
+		Name:       "podinfo",
+	}
+	_, err := mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Create(context.TODO(), newDeploymentControllerTestScaledObject(), metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	mocks.initializeCanary(t)
+
+	primarySo, err := mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary", primarySo.Spec.ScaleTargetRef.Name)
+	assert.Equal(t, "keda-hpa-podinfo-primary", primarySo.Spec.Advanced.HorizontalPodAutoscalerConfig.Name)
+	assert.Equal(t, "prometheus", primarySo.Spec.Triggers[0].Type)
+	assert.NotContains(t, primarySo.Annotations, keda.PausedReplicasAnnotation)
+
+	so, err := mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "0", so.Annotations[keda.PausedReplicasAnnotation])
+
+	soClone := so.DeepCopy()
+	soClone.Spec.MaxReplicaCount = int32p(8)
+	_, err = mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Update(context.TODO(), soClone, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.flaggerClient.(*fakeFlagger.Clientset).ClearActions()
+
+	err = mocks.controller.Promote(mocks.canary)
+	require.NoError(t, err)
+
+	primarySo, err = mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(8), *primarySo.Spec.MaxReplicaCount)
+
+	err = mocks.controller.ScaleFromZero(mocks.canary)
+	require.NoError(t, err)
+
+	so, err = mocks.flaggerClient.KedaV1alpha1().ScaledObjects("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, so.Annotations, keda.PausedReplicasAnnotation)
+
+	// the ScaledObjects are patched to keep the fields unknown to Flagger
+	for _, action := range mocks.flaggerClient.(*fakeFlagger.Clientset).Actions() {
+		if action.GetResource().Resource != "scaledobjects" {
+			continue
+		}
+		assert.NotEqual(t, "update", action.GetVerb())
+		if patch, ok := action.(k8stesting.PatchAction); ok && patch.GetName() == "podinfo" {
+			assert.Equal(t, types.MergePatchType, patch.GetPatchType())
+			assert.Contains(t, string(patch.GetPatch()), `{"metadata":{"annotations":{"autoscaling.keda.sh/paused-replicas":`)
+		}
+	}
+}
+
+func TestDeploymentController_HpaV2(t *testing.T) {
+	mocks := newDeploymentFixture()
+	mocks.canary.Spec.AutoscalerRef = &flaggerv1.CrossNamespaceObjectReference{
+		APIVersion: "autoscaling/v2",
+		Kind:       HorizontalPodAutoscalerKind,
+		Name:       "podinfo-v2",
+	}
+	_, err := mocks.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers("default").Create(context.TODO(), newDeploymentControllerTestHPAV2(), metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	mocks.initializeCanary(t)
+
+	primaryHpa, err := mocks.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers("default").Get(context.TODO(), "podinfo-v2-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary", primaryHpa.Spec.ScaleTargetRef.Name)
+	require.NotNil(t, primaryHpa.Spec.Behavior)
+	assert.Equal(t, int32(300), *primaryHpa.Spec.Behavior.ScaleDown.StabilizationWindowSeconds)
+	assert.Equal(t, autoscalingv2beta2.ExternalMetricSourceType, primaryHpa.Spec.Metrics[0].Type)
+
+	hpa, err := mocks.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers("default").Get(context.TODO(), "podinfo-v2", metav1.GetOptions{})
+	require.NoError(t, err)
+	hpaClone := hpa.DeepCopy()
+	hpaClone.Spec.Behavior.ScaleDown.StabilizationWindowSeconds = int32p(60)
+	_, err = mocks.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers("default").Update(context.TODO(), hpaClone, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = mocks.controller.Promote(mocks.canary)
+	require.NoError(t, err)
+
+	primaryHpa, err = mocks.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers("default").Get(context.TODO(), "podinfo-v2-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(60), *primaryHpa.Spec.Behavior.ScaleDown.StabilizationWindowSeconds)
+}
+
+func newDeploymentControllerTestScaledObject() *keda.ScaledObject {
+	return &keda.ScaledObject{
+		TypeMeta: metav1.TypeMeta{APIVersion: keda.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo",
+		},
+		Spec: keda.ScaledObjectSpec{
+			ScaleTargetRef: &keda.ScaleTarget{
+				Name: "podinfo",
+			},
+			MinReplicaCount: int32p(1),
+			MaxReplicaCount: int32p(4),
+			Advanced: &keda.AdvancedConfig{
+				HorizontalPodAutoscalerConfig: &keda.HorizontalPodAutoscalerConfig{
+					Name: "keda-hpa-podinfo",
+				},
+			},
+			Triggers: []keda.ScaleTriggers{
+				{
+					Type: "prometheus",
+					Metadata: map[string]string{
+						"serverAddress": "http://prometheus.istio-system:9090",
+						"query":         `sum(rate(http_requests_total{app="podinfo"}[2m]))`,
+						"threshold":     "100",
+					},
+				},
+			},
+		},
+	}
+}
+
+func newDeploymentControllerTestHPAV2() *autoscalingv2beta2.HorizontalPodAutoscaler {
+	return &autoscalingv2beta2.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{APIVersion: autoscalingv2beta2.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo-v2",
+		},
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{
+				Name:       "podinfo",
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+			},
+			MaxReplicas: 4,
+			Metrics: []autoscalingv2beta2.MetricSpec{
+				{
+					Type: autoscalingv2beta2.ExternalMetricSourceType,
+					External: &autoscalingv2beta2.ExternalMetricSource{
+						Metric: autoscalingv2beta2.MetricIdentifier{
+							Name: "queue_messages_ready",
+						},
+						Target: autoscalingv2beta2.MetricTarget{
+							Type:         autoscalingv2beta2.AverageValueMetricType,
+							AverageValue: resource.NewQuantity(30, resource.DecimalSI),
+						},
+					},
+				},
+			},
+			Behavior: &autoscalingv2beta2.HorizontalPodAutoscalerBehavior{
+				ScaleDown: &autoscalingv2beta2.HPAScalingRules{
+					StabilizationWindowSeconds: int32p(300),
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,232 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	monitoringv1 "github.com/weaveworks/flagger/pkg/apis/monitoring/v1"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+const (
+	PodDisruptionBudgetKind = "PodDisruptionBudget"
+	NetworkPolicyKind       = "NetworkPolicy"
+	ServiceMonitorKind      = "ServiceMonitor"
+)
+
+// companionReconciler clones the resources listed in the canary companion refs
+// and relabels their selectors to match the primary pods or services
+type companionReconciler struct {
+	kubeClient    kubernetes.Interface
+	flaggerClient clientset.Interface
+	logger        *zap.SugaredLogger
+}
+
+// Reconcile creates the primary companions, on promotion it also updates them to match the targets
+func (cr *companionReconciler) Reconcile(cd *flaggerv1.Canary, label string, init bool) error {
+	for _, ref := range cd.Spec.CompanionRefs {
+		var err error
+		switch ref.Kind {
+		case PodDisruptionBudgetKind:
+			err = cr.reconcilePodDisruptionBudget(cd, ref.Name, label, init)
+		case NetworkPolicyKind:
+			err = cr.reconcileNetworkPolicy(cd, ref.Name, label, init)
+		case ServiceMonitorKind:
+			err = cr.reconcileServiceMonitor(cd, ref.Name, label, init)
+		default:
+			err = fmt.Errorf("companion kind %s is not supported", ref.Kind)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Finalize deletes the primary companions
+func (cr *companionReconciler) Finalize(cd *flaggerv1.Canary) error {
+	for _, ref := range cd.Spec.CompanionRefs {
+		name := fmt.Sprintf("%s-primary", ref.Name)
+		var err error
+		switch ref.Kind {
+		case PodDisruptionBudgetKind:
+			err = cr.kubeClient.PolicyV1beta1().PodDisruptionBudgets(cd.Namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		case NetworkPolicyKind:
+			err = cr.kubeClient.NetworkingV1().NetworkPolicies(cd.Namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		case ServiceMonitorKind:
+			err = cr.flaggerClient.MonitoringV1().ServiceMonitors(cd.Namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		default:
+			continue
+		}
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("deleting %s %s.%s failed: %w", ref.Kind, name, cd.Namespace, err)
+		}
+	}
+	return nil
+}
+
+func (cr *companionReconciler) reconcilePodDisruptionBudget(cd *flaggerv1.Canary, name string, label string, init bool) error {
+	pdb, err := cr.kubeClient.PolicyV1beta1().PodDisruptionBudgets(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("PodDisruptionBudget %s.%s get query error: %w", name, cd.Namespace, err)
+	}
+
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	spec := *pdb.Spec.DeepCopy()
+	spec.Selector = makePrimarySelector(spec.Selector, label, primaryName)
+
+	primaryPdbName := fmt.Sprintf("%s-primary", name)
+	primaryPdb, err := cr.kubeClient.PolicyV1beta1().PodDisruptionBudgets(cd.Namespace).Get(context.TODO(), primaryPdbName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		primaryPdb = &policyv1.PodDisruptionBudget{
+			ObjectMeta: makePrimaryCompanionMeta(cd, pdb.ObjectMeta, label, primaryName),
+			Spec:       spec,
+		}
+		_, err = cr.kubeClient.PolicyV1beta1().PodDisruptionBudgets(cd.Namespace).Create(context.TODO(), primaryPdb, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("creating PodDisruptionBudget %s.%s failed: %w", primaryPdbName, cd.Namespace, err)
+		}
+		cr.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("PodDisruptionBudget %s.%s created", primaryPdbName, cd.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("PodDisruptionBudget %s.%s get query error: %w", primaryPdbName, cd.Namespace, err)
+	}
+
+	if !init && cmp.Diff(spec, primaryPdb.Spec) != "" {
+		pdbClone := primaryPdb.DeepCopy()
+		pdbClone.Spec = spec
+		_, err = cr.kubeClient.PolicyV1beta1().PodDisruptionBudgets(cd.Namespace).Update(context.TODO(), pdbClone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("updating PodDisruptionBudget %s.%s failed: %w", primaryPdbName, cd.Namespace, err)
+		}
+		cr.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("PodDisruptionBudget %s.%s updated", primaryPdbName, cd.Namespace)
+	}
+	return nil
+}
+
+func (cr *companionReconciler) reconcileNetworkPolicy(cd *flaggerv1.Canary, name string, label string, init bool) error {
+	np, err := cr.kubeClient.NetworkingV1().NetworkPolicies(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("NetworkPolicy %s.%s get query error: %w", name, cd.Namespace, err)
+	}
+
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	spec := *np.Spec.DeepCopy()
+	spec.PodSelector = *makePrimarySelector(&spec.PodSelector, label, primaryName)
+
+	primaryNpName := fmt.Sprintf("%s-primary", name)
+	primaryNp, err := cr.kubeClient.NetworkingV1().NetworkPolicies(cd.Namespace).Get(context.TODO(), primaryNpName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		primaryNp = &networkingv1.NetworkPolicy{
+			ObjectMeta: makePrimaryCompanionMeta(cd, np.ObjectMeta, label, primaryName),
+			Spec:       spec,
+		}
+		_, err = cr.kubeClient.NetworkingV1().NetworkPolicies(cd.Namespace).Create(context.TODO(), primaryNp, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("creating NetworkPolicy %s.%s failed: %w", primaryNpName, cd.Namespace, err)
+		}
+		cr.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("NetworkPolicy %s.%s created", primaryNpName, cd.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("NetworkPolicy %s.%s get query error: %w", primaryNpName, cd.Namespace, err)
+	}
+
+	if !init && cmp.Diff(spec, primaryNp.Spec) != "" {
+		npClone := primaryNp.DeepCopy()
+		npClone.Spec = spec
+		_, err = cr.kubeClient.NetworkingV1().NetworkPolicies(cd.Namespace).Update(context.TODO(), npClone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("updating NetworkPolicy %s.%s failed: %w", primaryNpName, cd.Namespace, err)
+		}
+		cr.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("NetworkPolicy %s.%s updated", primaryNpName, cd.Namespace)
+	}
+	return nil
+}
+
+// reconcileServiceMonitor selects the primary ClusterIP service instead of the pods
+func (cr *companionReconciler) reconcileServiceMonitor(cd *flaggerv1.Canary, name string, label string, init bool) error {
+	sm, err := cr.flaggerClient.MonitoringV1().ServiceMonitors(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ServiceMonitor %s.%s get query error: %w", name, cd.Namespace, err)
+	}
+
+	_, primaryName, _ := cd.GetServiceNames()
+	spec := *sm.Spec.DeepCopy()
+	spec.Selector = *makePrimarySelector(&spec.Selector, label, primaryName)
+
+	primarySmName := fmt.Sprintf("%s-primary", name)
+	primarySm, err := cr.flaggerClient.MonitoringV1().ServiceMonitors(cd.Namespace).Get(context.TODO(), primarySmName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		primarySm = &monitoringv1.ServiceMonitor{
+			ObjectMeta: makePrimaryCompanionMeta(cd, sm.ObjectMeta, label, primaryName),
+			Spec:       spec,
+		}
+		_, err = cr.flaggerClient.MonitoringV1().ServiceMonitors(cd.Namespace).Create(context.TODO(), primarySm, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("creating ServiceMonitor %s.%s failed: %w", primarySmName, cd.Namespace, err)
+		}
+		cr.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("ServiceMonitor %s.%s created", primarySmName, cd.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("ServiceMonitor %s.%s get query error: %w", primarySmName, cd.Namespace, err)
+	}
+
+	if !init && cmp.Diff(spec, primarySm.Spec) != "" {
+		smClone := primarySm.DeepCopy()
+		smClone.Spec = spec
+		_, err = cr.flaggerClient.MonitoringV1().ServiceMonitors(cd.Namespace).Update(context.TODO(), smClone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("updating ServiceMonitor %s.%s failed: %w", primarySmName, cd.Namespace, err)
+		}
+		cr.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("ServiceMonitor %s.%s updated", primarySmName, cd.Namespace)
+	}
+	return nil
+}
+
+// makePrimaryCompanionMeta copies the labels of a companion
+// and makes the canary the owner of the primary copy
+func makePrimaryCompanionMeta(cd *flaggerv1.Canary, meta metav1.ObjectMeta, label string, primaryName string) metav1.ObjectMeta {
+	labels := meta.Labels
+	if _, ok := labels[label]; ok {
+		labels = makePrimaryLabels(labels, primaryName, label)
+	}
+	return metav1.ObjectMeta{
+		Name:            fmt.Sprintf("%s-primary", meta.Name),
+		Namespace:       cd.Namespace,
+		Labels:          labels,
+		OwnerReferences: []metav1.OwnerReference{newCanaryOwnerRef(cd)},
+	}
+}
+
+// makePrimarySelector replaces the value of the selector label with the primary name
+func makePrimarySelector(selector *metav1.LabelSelector, label string, primaryName string) *metav1.LabelSelector {
+	if selector == nil {
+		return nil
+	}
+
+	res := selector.DeepCopy()
+	if _, ok := res.MatchLabels[label]; ok {
+		res.MatchLabels[label] = primaryName
+	}
+	for i, expr := range res.MatchExpressions {
+		if expr.Key == label && len(expr.Values) > 0 {
+			res.MatchExpressions[i].Values = []string{primaryName}
+		}
+	}
+	return res
+}
@@ -0,0 +1,131 @@
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	monitoringv1 "github.com/weaveworks/flagger/pkg/apis/monitoring/v1"
+)
+
+func TestDeploymentController_Companions(t *testing.T) {
+	mocks := newDeploymentFixture()
+	mocks.canary.Spec.CompanionRefs = []flaggerv1.CrossNamespaceObjectReference{
+		{Kind: PodDisruptionBudgetKind, Name: "podinfo"},
+		{Kind: NetworkPolicyKind, Name: "podinfo"},
+		{Kind: ServiceMonitorKind, Name: "podinfo"},
+	}
+	createCompanions(t, mocks)
+
+	mocks.initializeCanary(t)
+
+	pdb, err := mocks.kubeClient.PolicyV1beta1().PodDisruptionBudgets("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary", pdb.Spec.Selector.MatchLabels["name"])
+	assert.Equal(t, "podinfo-primary", pdb.Labels["name"])
+	assert.Equal(t, 1, pdb.Spec.MinAvailable.IntValue())
+
+	np, err := mocks.kubeClient.NetworkingV1().NetworkPolicies("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"podinfo-primary"}, np.Spec.PodSelector.MatchExpressions[0].Values)
+	assert.Len(t, np.Spec.Ingress, 1)
+
+	sm, err := mocks.flaggerClient.MonitoringV1().ServiceMonitors("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary", sm.Spec.Selector.MatchLabels["name"])
+	assert.Equal(t, "http-metrics", sm.Spec.Endpoints[0].Port)
+
+	// sync on promotion
+	pdbClone := newDeploymentControllerTestPDB()
+	pdbClone.Spec.MinAvailable = &intstr.IntOrString{Type: intstr.Int, IntVal: 2}
+	_, err = mocks.kubeClient.PolicyV1beta1().PodDisruptionBudgets("default").Update(context.TODO(), pdbClone, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = mocks.controller.Promote(mocks.canary)
+	require.NoError(t, err)
+
+	pdb, err = mocks.kubeClient.PolicyV1beta1().PodDisruptionBudgets("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, pdb.Spec.MinAvailable.IntValue())
+	assert.Equal(t, "podinfo-primary", pdb.Spec.Selector.MatchLabels["name"])
+
+	// remove on finalize
+	err = mocks.controller.Finalize(mocks.canary)
+	require.NoError(t, err)
+
+	_, err = mocks.kubeClient.PolicyV1beta1().PodDisruptionBudgets("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = mocks.kubeClient.NetworkingV1().NetworkPolicies("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = mocks.flaggerClient.MonitoringV1().ServiceMonitors("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestDeploymentController_CompanionsInvalidKind(t *testing.T) {
+	mocks := newDeploymentFixture()
+	mocks.canary.Spec.CompanionRefs = []flaggerv1.CrossNamespaceObjectReference{
+		{Kind: "Ingress", Name: "podinfo"},
+	}
+
+	err := mocks.controller.companions().Reconcile(mocks.canary, "name", true)
+	require.Error(t, err)
+}
+
+func createCompanions(t *testing.T, mocks deploymentControllerFixture) {
+	_, err := mocks.kubeClient.PolicyV1beta1().PodDisruptionBudgets("default").Create(context.TODO(), newDeploymentControllerTestPDB(), metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	np := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "podinfo"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "name", Operator: metav1.LabelSelectorOpIn, Values: []string{"podinfo"}},
+				},
+			},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					From: []networkingv1.NetworkPolicyPeer{
+						{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "frontend"}}},
+					},
+				},
+			},
+		},
+	}
+	_, err = mocks.kubeClient.NetworkingV1().NetworkPolicies("default").Create(context.TODO(), np, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	sm := &monitoringv1.ServiceMonitor{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "podinfo"},
+		Spec: monitoringv1.ServiceMonitorSpec{
+			Selector: metav1.LabelSelector{MatchLabels: map[string]string{"name": "podinfo"}},
+			Endpoints: []monitoringv1.Endpoint{
+				{Port: "http-metrics", Interval: "15s"},
+			},
+		},
+	}
+	_, err = mocks.flaggerClient.MonitoringV1().ServiceMonitors("default").Create(context.TODO(), sm, metav1.CreateOptions{})
+	require.NoError(t, err)
+}
+
+func newDeploymentControllerTestPDB() *policyv1.PodDisruptionBudget {
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo",
+			Labels:    map[string]string{"name": "podinfo"},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 1},
+			Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"name": "podinfo"}},
+		},
+	}
+}
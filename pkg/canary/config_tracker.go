@@ -11,6 +11,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
@@ -21,6 +22,7 @@ import (
 type ConfigTracker struct {
 	KubeClient    kubernetes.Interface
 	FlaggerClient clientset.Interface
+	DynamicClient dynamic.Interface
 	Logger        *zap.SugaredLogger
 }
 
@@ -31,18 +33,35 @@ const (
 	ConfigRefSecret ConfigRefType = "secret"
 )
 
-// ConfigRef holds the reference to a tracked Kubernetes ConfigMap or Secret
+// ConfigRef holds the reference to a tracked Kubernetes ConfigMap or Secret,
+// external refs are declared with annotations on the target and are only checked for changes
 type ConfigRef struct {
-	Name     string
-	Type     ConfigRefType
-	Checksum string
+	Name      string
+	Namespace string
+	Type      ConfigRefType
+	Checksum  string
+	Versioned bool
+	External  bool
 }
 
-// GetName returns the config ref type and name
+// GetName returns the config ref type and name,
+// the namespace is included for refs outside of the canary namespace
 func (c *ConfigRef) GetName() string {
+	if c.Namespace != "" {
+		return fmt.Sprintf("%s/%s/%s", c.Type, c.Namespace, c.Name)
+	}
 	return fmt.Sprintf("%s/%s", c.Type, c.Name)
 }
 
+// GetPrimaryName returns the name of the primary copy,
+// versioned copies are suffixed with the content checksum
+func (c *ConfigRef) GetPrimaryName() string {
+	if c.Versioned {
+		return fmt.Sprintf("%s-primary-%s", c.Name, c.Checksum)
+	}
+	return fmt.Sprintf("%s-primary", c.Name)
+}
+
 func checksum(data interface{}) string {
 	jsonBytes, _ := json.Marshal(data)
 	hashBytes := sha256.Sum256(jsonBytes)
@@ -97,6 +116,7 @@ func (ct *ConfigTracker) GetTargetConfigs(cd *flaggerv1.Canary) (map[string]Conf
 
 	var vs []corev1.Volume
 	var cs []corev1.Container
+	var annotations map[string]string
 	switch cd.Spec.TargetRef.Kind {
 	case "Deployment":
 		targetDep, err := ct.KubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
@@ -105,6 +125,7 @@ func (ct *ConfigTracker) GetTargetConfigs(cd *flaggerv1.Canary) (map[string]Conf
 		}
 		vs = targetDep.Spec.Template.Spec.Volumes
 		cs = targetDep.Spec.Template.Spec.Containers
+		annotations = targetDep.Annotations
 	case "DaemonSet":
 		targetDae, err := ct.KubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
 		if err != nil {
@@ -112,6 +133,7 @@ func (ct *ConfigTracker) GetTargetConfigs(cd *flaggerv1.Canary) (map[string]Conf
 		}
 		vs = targetDae.Spec.Template.Spec.Volumes
 		cs = targetDae.Spec.Template.Spec.Containers
+		annotations = targetDae.Annotations
 	default:
 		return nil, fmt.Errorf("TargetRef.Kind invalid: %s", cd.Spec.TargetRef.Kind)
 	}
@@ -214,6 +236,24 @@ func (ct *ConfigTracker) GetTargetConfigs(cd *flaggerv1.Canary) (map[string]Conf
 		}
 	}
 
+	// mark the configs to be copied per revision
+	if cd.HasVersionedConfigs() {
+		for name, ref := range res {
+			ref.Versioned = true
+			res[name] = ref
+		}
+	}
+
+	// add the configs declared with annotations and remove the ignored ones
+	for name, ref := range ct.getAnnotatedConfigs(cd, annotations) {
+		if _, exists := res[name]; !exists {
+			res[name] = ref
+		}
+	}
+	for _, name := range splitAnnotationList(annotations[flaggerv1.IgnoreConfigsAnnotation]) {
+		delete(res, name)
+	}
+
 	return res, nil
 }
 
@@ -269,13 +309,17 @@ func (ct *ConfigTracker) HasConfigChanged(cd *flaggerv1.Canary) (bool, error) {
 // with those found in the target deployment
 func (ct *ConfigTracker) CreatePrimaryConfigs(cd *flaggerv1.Canary, refs map[string]ConfigRef) error {
 	for _, ref := range refs {
+		if ref.External {
+			continue
+		}
+
 		switch ref.Type {
 		case ConfigRefMap:
 			config, err := ct.KubeClient.CoreV1().ConfigMaps(cd.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
 			if err != nil {
 				return fmt.Errorf("configmap %s.%s get query failed : %w", ref.Name, cd.Name, err)
 			}
-			primaryName := fmt.Sprintf("%s-primary", config.GetName())
+			primaryName := ref.GetPrimaryName()
 			primaryConfigMap := &corev1.ConfigMap{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      primaryName,
@@ -292,6 +336,13 @@ func (ct *ConfigTracker) CreatePrimaryConfigs(cd *flaggerv1.Canary, refs map[str
 				Data: config.Data,
 			}
 
+			if ref.Versioned {
+				if err := ct.createVersionedConfigMap(cd, ref, primaryConfigMap); err != nil {
+					return err
+				}
+				continue
+			}
+
 			// update or insert primary ConfigMap
 			_, err = ct.KubeClient.CoreV1().ConfigMaps(cd.Namespace).Update(context.TODO(), primaryConfigMap, metav1.UpdateOptions{})
 			if err != nil {
@@ -312,7 +363,7 @@ func (ct *ConfigTracker) CreatePrimaryConfigs(cd *flaggerv1.Canary, refs map[str
 			if err != nil {
 				return fmt.Errorf("secret %s.%s get query failed : %w", ref.Name, cd.Name, err)
 			}
-			primaryName := fmt.Sprintf("%s-primary", secret.GetName())
+			primaryName := ref.GetPrimaryName()
 			primarySecret := &corev1.Secret{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      primaryName,
@@ -330,6 +381,13 @@ func (ct *ConfigTracker) CreatePrimaryConfigs(cd *flaggerv1.Canary, refs map[str
 				Data: secret.Data,
 			}
 
+			if ref.Versioned {
+				if err := ct.createVersionedSecret(cd, ref, primarySecret); err != nil {
+					return err
+				}
+				continue
+			}
+
 			// update or insert primary Secret
 			_, err = ct.KubeClient.CoreV1().Secrets(cd.Namespace).Update(context.TODO(), primarySecret, metav1.UpdateOptions{})
 			if err != nil {
@@ -351,21 +409,80 @@ func (ct *ConfigTracker) CreatePrimaryConfigs(cd *flaggerv1.Canary, refs map[str
 	return nil
 }
 
-// ApplyPrimaryConfigs appends the primary suffix to all ConfigMaps and Secretes found in the PodSpec
+// createVersionedConfigMap creates the immutable copy of a ConfigMap for its content checksum,
+// an existing copy is left untouched as it can be referenced by other revisions
+func (ct *ConfigTracker) createVersionedConfigMap(cd *flaggerv1.Canary, ref ConfigRef, config *corev1.ConfigMap) error {
+	if checksum(config.Data) != ref.Checksum {
+		return fmt.Errorf("configmap %s.%s has changed during promotion", ref.Name, cd.Namespace)
+	}
+
+	immutable := true
+	config.Immutable = &immutable
+	config.Labels = makeVersionedConfigLabels(cd, config.Labels)
+
+	_, err := ct.KubeClient.CoreV1().ConfigMaps(cd.Namespace).Create(context.TODO(), config, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("creating configmap %s.%s failed: %w", config.Name, cd.Namespace, err)
+	}
+
+	ct.Logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+		Infof("ConfigMap %s created", config.GetName())
+	return nil
+}
+
+// createVersionedSecret creates the immutable copy of a Secret for its content checksum,
+// an existing copy is left untouched as it can be referenced by other revisions
+func (ct *ConfigTracker) createVersionedSecret(cd *flaggerv1.Canary, ref ConfigRef, secret *corev1.Secret) error {
+	if checksum(secret.Data) != ref.Checksum {
+		return fmt.Errorf("secret %s.%s has changed during promotion", ref.Name, cd.Namespace)
+	}
+
+	immutable := true
+	secret.Immutable = &immutable
+	secret.Labels = makeVersionedConfigLabels(cd, secret.Labels)
+
+	_, err := ct.KubeClient.CoreV1().Secrets(cd.Namespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("creating secret %s.%s failed: %w", secret.Name, cd.Namespace, err)
+	}
+
+	ct.Logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+		Infof("Secret %s created", secret.GetName())
+	return nil
+}
+
+// makeVersionedConfigLabels copies the config labels and adds the canary label
+// used to garbage collect the versioned copies
+func makeVersionedConfigLabels(cd *flaggerv1.Canary, labels map[string]string) map[string]string {
+	res := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		res[k] = v
+	}
+	res[revisionCanaryLabel] = cd.Name
+	return res
+}
+
+// ApplyPrimaryConfigs replaces all ConfigMaps and Secretes found in the PodSpec with their primary copies
 func (ct *ConfigTracker) ApplyPrimaryConfigs(spec corev1.PodSpec, refs map[string]ConfigRef) corev1.PodSpec {
 	// update volumes
 	for i, volume := range spec.Volumes {
 		if cmv := volume.ConfigMap; cmv != nil {
 			name := fmt.Sprintf("%s/%s", ConfigRefMap, cmv.Name)
-			if _, exists := refs[name]; exists {
-				spec.Volumes[i].ConfigMap.Name += "-primary"
+			if ref, exists := refs[name]; exists {
+				spec.Volumes[i].ConfigMap.Name = ref.GetPrimaryName()
 			}
 		}
 
 		if sv := volume.Secret; sv != nil {
 			name := fmt.Sprintf("%s/%s", ConfigRefSecret, sv.SecretName)
-			if _, exists := refs[name]; exists {
-				spec.Volumes[i].Secret.SecretName += "-primary"
+			if ref, exists := refs[name]; exists {
+				spec.Volumes[i].Secret.SecretName = ref.GetPrimaryName()
 			}
 		}
 
@@ -373,15 +490,15 @@ func (ct *ConfigTracker) ApplyPrimaryConfigs(spec corev1.PodSpec, refs map[strin
 			for s, source := range projected.Sources {
 				if cmv := source.ConfigMap; cmv != nil {
 					name := fmt.Sprintf("%s/%s", ConfigRefMap, cmv.Name)
-					if _, exists := refs[name]; exists {
-						spec.Volumes[i].Projected.Sources[s].ConfigMap.Name += "-primary"
+					if ref, exists := refs[name]; exists {
+						spec.Volumes[i].Projected.Sources[s].ConfigMap.Name = ref.GetPrimaryName()
 					}
 				}
 
 				if sv := source.Secret; sv != nil {
 					name := fmt.Sprintf("%s/%s", ConfigRefSecret, sv.Name)
-					if _, exists := refs[name]; exists {
-						spec.Volumes[i].Projected.Sources[s].Secret.Name += "-primary"
+					if ref, exists := refs[name]; exists {
+						spec.Volumes[i].Projected.Sources[s].Secret.Name = ref.GetPrimaryName()
 					}
 				}
 			}
@@ -396,13 +513,13 @@ func (ct *ConfigTracker) ApplyPrimaryConfigs(spec corev1.PodSpec, refs map[strin
 				switch {
 				case env.ValueFrom.ConfigMapKeyRef != nil:
 					name := fmt.Sprintf("%s/%s", ConfigRefMap, env.ValueFrom.ConfigMapKeyRef.Name)
-					if _, exists := refs[name]; exists {
-						container.Env[i].ValueFrom.ConfigMapKeyRef.Name += "-primary"
+					if ref, exists := refs[name]; exists {
+						container.Env[i].ValueFrom.ConfigMapKeyRef.Name = ref.GetPrimaryName()
 					}
 				case env.ValueFrom.SecretKeyRef != nil:
 					name := fmt.Sprintf("%s/%s", ConfigRefSecret, env.ValueFrom.SecretKeyRef.Name)
-					if _, exists := refs[name]; exists {
-						container.Env[i].ValueFrom.SecretKeyRef.Name += "-primary"
+					if ref, exists := refs[name]; exists {
+						container.Env[i].ValueFrom.SecretKeyRef.Name = ref.GetPrimaryName()
 					}
 				}
 			}
@@ -412,13 +529,13 @@ func (ct *ConfigTracker) ApplyPrimaryConfigs(spec corev1.PodSpec, refs map[strin
 			switch {
 			case envFrom.ConfigMapRef != nil:
 				name := fmt.Sprintf("%s/%s", ConfigRefMap, envFrom.ConfigMapRef.Name)
-				if _, exists := refs[name]; exists {
-					container.EnvFrom[i].ConfigMapRef.Name += "-primary"
+				if ref, exists := refs[name]; exists {
+					container.EnvFrom[i].ConfigMapRef.Name = ref.GetPrimaryName()
 				}
 			case envFrom.SecretRef != nil:
 				name := fmt.Sprintf("%s/%s", ConfigRefSecret, envFrom.SecretRef.Name)
-				if _, exists := refs[name]; exists {
-					container.EnvFrom[i].SecretRef.Name += "-primary"
+				if ref, exists := refs[name]; exists {
+					container.EnvFrom[i].SecretRef.Name = ref.GetPrimaryName()
 				}
 			}
 		}
@@ -0,0 +1,113 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// getAnnotatedConfigs returns the external config refs declared with the tracking annotations of the target,
+// objects that can't be found are logged and skipped in the same way as the pod spec refs
+func (ct *ConfigTracker) getAnnotatedConfigs(cd *flaggerv1.Canary, annotations map[string]string) map[string]ConfigRef {
+	res := make(map[string]ConfigRef)
+	add := func(ref *ConfigRef, namespace string) {
+		if ref == nil {
+			return
+		}
+		ref.External = true
+		if namespace != cd.Namespace {
+			ref.Namespace = namespace
+		}
+		res[ref.GetName()] = *ref
+	}
+
+	for _, item := range splitAnnotationList(annotations[flaggerv1.TrackConfigMapsAnnotation]) {
+		namespace, name := splitNamespacedName(item, cd.Namespace)
+		config, err := ct.getRefFromConfigMap(name, namespace)
+		if err != nil {
+			ct.Logger.Errorf("getRefFromConfigMap failed: %v", err)
+			continue
+		}
+		add(config, namespace)
+	}
+
+	for _, item := range splitAnnotationList(annotations[flaggerv1.TrackSecretsAnnotation]) {
+		namespace, name := splitNamespacedName(item, cd.Namespace)
+		secret, err := ct.getRefFromSecret(name, namespace)
+		if err != nil {
+			ct.Logger.Errorf("getRefFromSecret failed: %v", err)
+			continue
+		}
+		add(secret, namespace)
+	}
+
+	for _, item := range splitAnnotationList(annotations[flaggerv1.TrackResourcesAnnotation]) {
+		parts := strings.SplitN(item, "/", 2)
+		if len(parts) != 2 {
+			ct.Logger.Errorf("invalid %s value %s", flaggerv1.TrackResourcesAnnotation, item)
+			continue
+		}
+		namespace, name := splitNamespacedName(parts[1], cd.Namespace)
+		resource, err := ct.getRefFromResource(parts[0], name, namespace)
+		if err != nil {
+			ct.Logger.Errorf("getRefFromResource failed: %v", err)
+			continue
+		}
+		add(resource, namespace)
+	}
+
+	return res
+}
+
+// getRefFromResource transforms a custom resource into a ConfigRef
+// and computes the checksum of the object content without its metadata and status
+func (ct *ConfigTracker) getRefFromResource(kind string, name string, namespace string) (*ConfigRef, error) {
+	gvk, _ := schema.ParseKindArg(kind)
+	if gvk == nil {
+		return nil, fmt.Errorf("invalid resource kind %s, expected kind.version.group", kind)
+	}
+	if ct.DynamicClient == nil {
+		return nil, fmt.Errorf("%s %s.%s can't be tracked without a dynamic client", kind, name, namespace)
+	}
+
+	gvr, _ := meta.UnsafeGuessKindToResource(*gvk)
+	obj, err := ct.DynamicClient.Resource(gvr).Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("%s %s.%s get query error: %w", kind, name, namespace, err)
+	}
+
+	content := obj.DeepCopy().Object
+	delete(content, "metadata")
+	delete(content, "status")
+
+	return &ConfigRef{
+		Name:     obj.GetName(),
+		Type:     ConfigRefType(kind),
+		Checksum: checksum(content),
+	}, nil
+}
+
+// splitAnnotationList returns the non-empty items of a comma separated annotation value
+func splitAnnotationList(value string) []string {
+	var res []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// splitNamespacedName parses a [namespace/]name value
+func splitNamespacedName(value string, defaultNamespace string) (string, string) {
+	if parts := strings.SplitN(value, "/", 2); len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return defaultNamespace, value
+}
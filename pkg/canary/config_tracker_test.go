@@ -2,12 +2,20 @@ package canary
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakeDynamic "k8s.io/client-go/dynamic/fake"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 )
 
 func TestConfigTracker_ConfigMaps(t *testing.T) {
@@ -162,3 +170,132 @@ func TestConfigTracker_Secrets(t *testing.T) {
 		}
 	})
 }
+
+func TestConfigTracker_VersionedConfigs(t *testing.T) {
+	mocks := newDeploymentFixture()
+	mocks.canary.Spec.ConfigTracking = &flaggerv1.CanaryConfigTracking{Versioned: true}
+	mocks.canary.Spec.RevisionHistoryLimit = int32p(2)
+	mocks.initializeCanary(t)
+
+	getPrimaryConfigName := func() string {
+		depPrimary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+		require.NoError(t, err)
+		for name := range getPodSpecConfigNames(depPrimary.Spec.Template.Spec) {
+			if strings.HasPrefix(name, "configmap/podinfo-config-env-primary-") {
+				return strings.TrimPrefix(name, "configmap/")
+			}
+		}
+		return ""
+	}
+
+	updateConfig := func(color string) {
+		config := newDeploymentControllerTestConfigMap()
+		config.Data["color"] = color
+		_, err := mocks.kubeClient.CoreV1().ConfigMaps("default").Update(context.TODO(), config, metav1.UpdateOptions{})
+		require.NoError(t, err)
+		require.NoError(t, mocks.controller.Promote(mocks.canary))
+	}
+
+	name1 := getPrimaryConfigName()
+	require.NotEmpty(t, name1)
+	config1, err := mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), name1, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "red", config1.Data["color"])
+	assert.True(t, *config1.Immutable)
+	assert.Equal(t, "podinfo", config1.Labels[revisionCanaryLabel])
+
+	// promote a config change
+	updateConfig("blue")
+	name2 := getPrimaryConfigName()
+	assert.NotEqual(t, name1, name2)
+	config2, err := mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), name2, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "blue", config2.Data["color"])
+	_, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), name1, metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// the copy of the oldest revision is garbage collected
+	updateConfig("green")
+	_, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), name1, metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), name2, metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// rollback restores the config of the previous revision
+	require.NoError(t, mocks.controller.RevertPrimary(mocks.canary, 0))
+	assert.Equal(t, name2, getPrimaryConfigName())
+}
+
+func TestConfigTracker_AnnotatedConfigs(t *testing.T) {
+	mocks := newDeploymentFixture()
+	mocks.initializeCanary(t)
+
+	_, err := mocks.kubeClient.CoreV1().ConfigMaps("shared").Create(context.TODO(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "shared-config", Namespace: "shared"},
+		Data:       map[string]string{"region": "eu"},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	externalSecret := &unstructured.Unstructured{}
+	externalSecret.SetAPIVersion("external-secrets.io/v1beta1")
+	externalSecret.SetKind("ExternalSecret")
+	externalSecret.SetName("podinfo-db")
+	externalSecret.SetNamespace("default")
+	externalSecret.Object["spec"] = map[string]interface{}{"refreshInterval": "1h"}
+	dynamicClient := fakeDynamic.NewSimpleDynamicClient(runtime.NewScheme(), externalSecret)
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	dep.Annotations = map[string]string{
+		flaggerv1.TrackConfigMapsAnnotation: "shared/shared-config",
+		flaggerv1.TrackResourcesAnnotation:  "ExternalSecret.v1beta1.external-secrets.io/podinfo-db",
+		flaggerv1.IgnoreConfigsAnnotation:   "configmap/podinfo-config-env",
+	}
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	tracker := &ConfigTracker{
+		Logger:        mocks.logger,
+		KubeClient:    mocks.kubeClient,
+		FlaggerClient: mocks.flaggerClient,
+		DynamicClient: dynamicClient,
+	}
+
+	configs, err := tracker.GetTargetConfigs(mocks.canary)
+	require.NoError(t, err)
+	assert.NotContains(t, configs, "configmap/podinfo-config-env")
+	require.Contains(t, configs, "configmap/shared/shared-config")
+	assert.True(t, configs["configmap/shared/shared-config"].External)
+	require.Contains(t, configs, "ExternalSecret.v1beta1.external-secrets.io/podinfo-db")
+	assert.True(t, configs["ExternalSecret.v1beta1.external-secrets.io/podinfo-db"].External)
+
+	// external configs are not copied to the primary
+	require.NoError(t, tracker.CreatePrimaryConfigs(mocks.canary, configs))
+	_, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "shared-config-primary", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+
+	refs, err := tracker.GetConfigRefs(mocks.canary)
+	require.NoError(t, err)
+	mocks.canary.Status.TrackedConfigs = refs
+
+	changed, err := tracker.HasConfigChanged(mocks.canary)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// a change to an ignored config doesn't trigger a rollout
+	_, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Update(context.TODO(),
+		newDeploymentControllerTestConfigMapV2(), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	changed, err = tracker.HasConfigChanged(mocks.canary)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// a change to a tracked resource triggers a rollout
+	externalSecret.Object["spec"] = map[string]interface{}{"refreshInterval": "5m"}
+	gvr := schema.GroupVersionResource{Group: "external-secrets.io", Version: "v1beta1", Resource: "externalsecrets"}
+	_, err = dynamicClient.Resource(gvr).Namespace("default").Update(context.TODO(), externalSecret, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	changed, err = tracker.HasConfigChanged(mocks.canary)
+	require.NoError(t, err)
+	assert.True(t, changed)
+}
@@ -13,11 +13,15 @@ type Controller interface {
 	SetStatusWeight(canary *flaggerv1.Canary, val int) error
 	SetStatusIterations(canary *flaggerv1.Canary, val int) error
 	SetStatusPhase(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error
+	SetStatusMetrics(canary *flaggerv1.Canary, metrics []flaggerv1.CanaryMetricStatus) error
 	Initialize(canary *flaggerv1.Canary) error
 	Promote(canary *flaggerv1.Canary) error
+	RevertPrimary(canary *flaggerv1.Canary, revision int64) error
+	SyncIgnoredChanges(canary *flaggerv1.Canary) error
 	HasTargetChanged(canary *flaggerv1.Canary) (bool, error)
 	HaveDependenciesChanged(canary *flaggerv1.Canary) (bool, error)
 	ScaleToZero(canary *flaggerv1.Canary) error
 	ScaleFromZero(canary *flaggerv1.Canary) error
+	ScaleByWeight(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int) error
 	Finalize(canary *flaggerv1.Canary) error
 }
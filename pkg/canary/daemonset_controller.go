@@ -11,6 +11,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
@@ -73,6 +74,11 @@ func (c *DaemonSetController) ScaleFromZero(cd *flaggerv1.Canary) error {
 	return nil
 }
 
+// ScaleByWeight is a noop, DaemonSets run one pod per node
+func (c *DaemonSetController) ScaleByWeight(_ *flaggerv1.Canary, _ int, _ int) error {
+	return nil
+}
+
 // Initialize creates the primary DaemonSet, scales down the canary DaemonSet,
 // and returns the pod selector label and container ports
 func (c *DaemonSetController) Initialize(cd *flaggerv1.Canary) (err error) {
@@ -94,6 +100,12 @@ func (c *DaemonSetController) Initialize(cd *flaggerv1.Canary) (err error) {
 			return fmt.Errorf("ScaleToZero failed: %w", err)
 		}
 	}
+
+	if len(cd.Spec.CompanionRefs) > 0 {
+		if err := c.reconcilePrimaryCompanions(cd, true); err != nil {
+			return fmt.Errorf("initial reconcilePrimaryCompanions failed: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -117,6 +129,11 @@ func (c *DaemonSetController) Promote(cd *flaggerv1.Canary) error {
 		return fmt.Errorf("daemonset %s.%s get query error: %w", primaryName, cd.Namespace, err)
 	}
 
+	// keep the current primary template in the revision history
+	if err := c.revisions().Record(cd, primary.Spec.Template); err != nil {
+		return fmt.Errorf("recording revision for %s.%s failed: %w", primaryName, cd.Namespace, err)
+	}
+
 	// promote secrets and config maps
 	configRefs, err := c.configTracker.GetTargetConfigs(cd)
 	if err != nil {
@@ -149,11 +166,92 @@ func (c *DaemonSetController) Promote(cd *flaggerv1.Canary) error {
 	primaryCopy.Spec.Template.Labels = makePrimaryLabels(canary.Spec.Template.Labels, primaryName, label)
 
 	// apply update
-	_, err = c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+	promoted, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
 	if err != nil {
 		return fmt.Errorf("updating daemonset %s.%s template spec failed: %w",
 			primaryCopy.GetName(), primaryCopy.Namespace, err)
 	}
+
+	if err := c.revisions().Record(cd, promoted.Spec.Template); err != nil {
+		return fmt.Errorf("recording revision for %s.%s failed: %w", primaryName, cd.Namespace, err)
+	}
+
+	// update PDBs, network policies and service monitors
+	if len(cd.Spec.CompanionRefs) > 0 {
+		if err := c.companions().Reconcile(cd, label, false); err != nil {
+			return fmt.Errorf("reconcilePrimaryCompanions failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// RevertPrimary rolls the primary DaemonSet back to the pod template of a promoted revision,
+// zero selects the revision promoted before the last one.
+// The revision history is left unchanged so that reverting again to the same revision is a no-op.
+func (c *DaemonSetController) RevertPrimary(cd *flaggerv1.Canary, revision int64) error {
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	template, err := c.revisions().Revert(cd, revision)
+	if err != nil {
+		return err
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		primary, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		primaryCopy := primary.DeepCopy()
+		primaryCopy.Spec.Template = *template
+		_, err = c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("reverting daemonset %s.%s failed: %w", primaryName, cd.Namespace, err)
+	}
+
+	return nil
+}
+
+// SyncIgnoredChanges applies the changes made to the ignored pod template paths
+// of the canary daemonset to the primary without running the analysis
+func (c *DaemonSetController) SyncIgnoredChanges(cd *flaggerv1.Canary) error {
+	if cd.Spec.IgnoredChanges == nil {
+		return nil
+	}
+
+	targetName := cd.Spec.TargetRef.Name
+	primaryName := fmt.Sprintf("%s-primary", targetName)
+	canary, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("daemonset %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+
+	primary, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("daemonset %s.%s get query error: %w", primaryName, cd.Namespace, err)
+	}
+
+	// the ignored env vars read the primary copies of the ConfigMaps and Secrets
+	configRefs, err := c.configTracker.GetTargetConfigs(cd)
+	if err != nil {
+		return fmt.Errorf("GetTargetConfigs failed: %w", err)
+	}
+	template := canary.Spec.Template.DeepCopy()
+	template.Spec = c.configTracker.ApplyPrimaryConfigs(template.Spec, configRefs)
+
+	primaryCopy := primary.DeepCopy()
+	if !applyIgnoredChanges(cd, *template, &primaryCopy.Spec.Template) {
+		return nil
+	}
+
+	_, err = c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("updating daemonset %s.%s failed: %w", primaryName, cd.Namespace, err)
+	}
+
+	c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+		Infof("DaemonSet %s.%s ignored changes applied", primaryName, cd.Namespace)
 	return nil
 }
 
@@ -175,7 +273,7 @@ func (c *DaemonSetController) HasTargetChanged(cd *flaggerv1.Canary) (bool, erro
 		canary.Spec.Template.Spec.NodeSelector = map[string]string{}
 	}
 
-	return hasSpecChanged(cd, canary.Spec.Template)
+	return hasSpecChanged(cd, filterIgnoredChanges(cd, canary.Spec.Template))
 }
 
 // GetMetadata returns the pod label selector and svc ports
@@ -280,6 +378,35 @@ func (c *DaemonSetController) createPrimaryDaemonSet(cd *flaggerv1.Canary) error
 	return nil
 }
 
+func (c *DaemonSetController) reconcilePrimaryCompanions(cd *flaggerv1.Canary, init bool) error {
+	canary, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("daemonset %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+	}
+
+	label, err := c.getSelectorLabel(canary)
+	if err != nil {
+		return fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+
+	return c.companions().Reconcile(cd, label, init)
+}
+
+func (c *DaemonSetController) revisions() *revisionHistory {
+	return &revisionHistory{
+		kubeClient: c.kubeClient,
+		logger:     c.logger,
+	}
+}
+
+func (c *DaemonSetController) companions() *companionReconciler {
+	return &companionReconciler{
+		kubeClient:    c.kubeClient,
+		flaggerClient: c.flaggerClient,
+		logger:        c.logger,
+	}
+}
+
 // getSelectorLabel returns the selector match label
 func (c *DaemonSetController) getSelectorLabel(daemonSet *appsv1.DaemonSet) (string, error) {
 	for _, l := range c.labels {
@@ -300,6 +427,9 @@ func (c *DaemonSetController) HaveDependenciesChanged(cd *flaggerv1.Canary) (boo
 
 //Finalize scale the reference instance from zero
 func (c *DaemonSetController) Finalize(cd *flaggerv1.Canary) error {
+	if err := c.companions().Finalize(cd); err != nil {
+		return err
+	}
 	if err := c.ScaleFromZero(cd); err != nil {
 		return fmt.Errorf("ScaleFromZero failed: %w", err)
 	}
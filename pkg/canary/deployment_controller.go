@@ -13,6 +13,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
@@ -50,13 +51,16 @@ func (c *DeploymentController) Initialize(cd *flaggerv1.Canary) (err error) {
 	}
 
 	if cd.Spec.AutoscalerRef != nil {
-		if cd.Spec.AutoscalerRef.Kind == "HorizontalPodAutoscaler" {
-			if err := c.reconcilePrimaryHpa(cd, true); err != nil {
-				return fmt.Errorf(
-					"initial reconcilePrimaryHpa for %s.%s failed: %w", primaryName, cd.Namespace, err)
-			}
-		} else {
-			return fmt.Errorf("cd.Spec.AutoscalerRef.Kind is invalid: %s", cd.Spec.AutoscalerRef.Kind)
+		if err := c.reconcilePrimaryAutoscaler(cd, true); err != nil {
+			return fmt.Errorf(
+				"initial reconcilePrimaryAutoscaler for %s.%s failed: %w", primaryName, cd.Namespace, err)
+		}
+	}
+
+	if len(cd.Spec.CompanionRefs) > 0 {
+		if err := c.reconcilePrimaryCompanions(cd, true); err != nil {
+			return fmt.Errorf(
+				"initial reconcilePrimaryCompanions for %s.%s failed: %w", primaryName, cd.Namespace, err)
 		}
 	}
 	return nil
@@ -82,6 +86,11 @@ func (c *DeploymentController) Promote(cd *flaggerv1.Canary) error {
 		return fmt.Errorf("deployment %s.%s get query error: %w", primaryName, cd.Namespace, err)
 	}
 
+	// keep the current primary template in the revision history
+	if err := c.revisions().Record(cd, primary.Spec.Template); err != nil {
+		return fmt.Errorf("recording revision for %s.%s failed: %w", primaryName, cd.Namespace, err)
+	}
+
 	// promote secrets and config maps
 	configRefs, err := c.configTracker.GetTargetConfigs(cd)
 	if err != nil {
@@ -110,23 +119,106 @@ func (c *DeploymentController) Promote(cd *flaggerv1.Canary) error {
 	primaryCopy.Spec.Template.Labels = makePrimaryLabels(canary.Spec.Template.Labels, primaryName, label)
 
 	// apply update
-	_, err = c.kubeClient.AppsV1().Deployments(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+	promoted, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
 	if err != nil {
 		return fmt.Errorf("updating deployment %s.%s template spec failed: %w",
 			primaryCopy.GetName(), primaryCopy.Namespace, err)
 	}
 
-	// update HPA
+	if err := c.revisions().Record(cd, promoted.Spec.Template); err != nil {
+		return fmt.Errorf("recording revision for %s.%s failed: %w", primaryName, cd.Namespace, err)
+	}
+
+	// update HPA or ScaledObject
 	if cd.Spec.AutoscalerRef != nil {
-		if cd.Spec.AutoscalerRef.Kind == "HorizontalPodAutoscaler" {
-			if err := c.reconcilePrimaryHpa(cd, false); err != nil {
-				return fmt.Errorf(
-					"reconcilePrimaryHpa for %s.%s failed: %w", primaryName, cd.Namespace, err)
-			}
-		} else {
-			return fmt.Errorf("cd.Spec.AutoscalerRef.Kind is invalid: %s", cd.Spec.AutoscalerRef.Kind)
+		if err := c.reconcilePrimaryAutoscaler(cd, false); err != nil {
+			return fmt.Errorf(
+				"reconcilePrimaryAutoscaler for %s.%s failed: %w", primaryName, cd.Namespace, err)
 		}
 	}
+
+	// update PDBs, network policies and service monitors
+	if len(cd.Spec.CompanionRefs) > 0 {
+		if err := c.companions().Reconcile(cd, label, false); err != nil {
+			return fmt.Errorf(
+				"reconcilePrimaryCompanions for %s.%s failed: %w", primaryName, cd.Namespace, err)
+		}
+	}
+
+	// scale the primary back up before routing all the traffic to it
+	if err := c.restorePrimaryReplicas(cd); err != nil {
+		return fmt.Errorf("restorePrimaryReplicas for %s.%s failed: %w", primaryName, cd.Namespace, err)
+	}
+	return nil
+}
+
+// RevertPrimary rolls the primary deployment back to the pod template of a promoted revision,
+// zero selects the revision promoted before the last one.
+// The revision history is left unchanged so that reverting again to the same revision is a no-op.
+func (c *DeploymentController) RevertPrimary(cd *flaggerv1.Canary, revision int64) error {
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	template, err := c.revisions().Revert(cd, revision)
+	if err != nil {
+		return err
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		primary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		primaryCopy := primary.DeepCopy()
+		primaryCopy.Spec.Template = *template
+		_, err = c.kubeClient.AppsV1().Deployments(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("reverting deployment %s.%s failed: %w", primaryName, cd.Namespace, err)
+	}
+
+	return nil
+}
+
+// SyncIgnoredChanges applies the changes made to the ignored pod template paths
+// of the canary deployment to the primary without running the analysis
+func (c *DeploymentController) SyncIgnoredChanges(cd *flaggerv1.Canary) error {
+	if cd.Spec.IgnoredChanges == nil {
+		return nil
+	}
+
+	targetName := cd.Spec.TargetRef.Name
+	primaryName := fmt.Sprintf("%s-primary", targetName)
+	canary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+
+	primary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s get query error: %w", primaryName, cd.Namespace, err)
+	}
+
+	// the ignored env vars read the primary copies of the ConfigMaps and Secrets
+	configRefs, err := c.configTracker.GetTargetConfigs(cd)
+	if err != nil {
+		return fmt.Errorf("GetTargetConfigs failed: %w", err)
+	}
+	template := canary.Spec.Template.DeepCopy()
+	template.Spec = c.configTracker.ApplyPrimaryConfigs(template.Spec, configRefs)
+
+	primaryCopy := primary.DeepCopy()
+	if !applyIgnoredChanges(cd, *template, &primaryCopy.Spec.Template) {
+		return nil
+	}
+
+	_, err = c.kubeClient.AppsV1().Deployments(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("updating deployment %s.%s failed: %w", primaryName, cd.Namespace, err)
+	}
+
+	c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+		Infof("Deployment %s.%s ignored changes applied", primaryName, cd.Namespace)
 	return nil
 }
 
@@ -138,7 +230,7 @@ func (c *DeploymentController) HasTargetChanged(cd *flaggerv1.Canary) (bool, err
 		return false, fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
 	}
 
-	return hasSpecChanged(cd, canary.Spec.Template)
+	return hasSpecChanged(cd, filterIgnoredChanges(cd, canary.Spec.Template))
 }
 
 // Scale sets the canary deployment replicas
@@ -156,6 +248,13 @@ func (c *DeploymentController) ScaleToZero(cd *flaggerv1.Canary) error {
 	if err != nil {
 		return fmt.Errorf("deployment %s.%s update query error: %w", targetName, cd.Namespace, err)
 	}
+
+	// prevent KEDA from scaling the canary back up
+	if cd.Spec.AutoscalerRef != nil && cd.Spec.AutoscalerRef.Kind == ScaledObjectKind {
+		if err := c.setScaledObjectPausedReplicas(cd, cd.Spec.AutoscalerRef.Name, int32p(0)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -170,6 +269,13 @@ func (c *DeploymentController) ScaleFromZero(cd *flaggerv1.Canary) error {
 	if dep.Spec.Replicas != nil && *dep.Spec.Replicas > 0 {
 		replicas = dep.Spec.Replicas
 	}
+
+	// start at the replicas floor, the canary grows with the traffic weight
+	scaling := cd.GetProportionalScaling()
+	if scaling != nil {
+		replicas = int32p(scaling.GetReplicas(*replicas, 0))
+	}
+
 	depCopy := dep.DeepCopy()
 	depCopy.Spec.Replicas = replicas
 
@@ -177,6 +283,16 @@ func (c *DeploymentController) ScaleFromZero(cd *flaggerv1.Canary) error {
 	if err != nil {
 		return fmt.Errorf("scaling up %s.%s to %v failed: %v", depCopy.GetName(), depCopy.Namespace, replicas, err)
 	}
+
+	if cd.Spec.AutoscalerRef != nil && cd.Spec.AutoscalerRef.Kind == ScaledObjectKind {
+		var paused *int32
+		if scaling != nil {
+			paused = replicas
+		}
+		if err := c.setScaledObjectPausedReplicas(cd, cd.Spec.AutoscalerRef.Name, paused); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -360,6 +476,35 @@ func (c *DeploymentController) reconcilePrimaryHpa(cd *flaggerv1.Canary, init bo
 	return nil
 }
 
+func (c *DeploymentController) reconcilePrimaryCompanions(cd *flaggerv1.Canary, init bool) error {
+	canary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+	}
+
+	label, err := c.getSelectorLabel(canary)
+	if err != nil {
+		return fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+
+	return c.companions().Reconcile(cd, label, init)
+}
+
+func (c *DeploymentController) revisions() *revisionHistory {
+	return &revisionHistory{
+		kubeClient: c.kubeClient,
+		logger:     c.logger,
+	}
+}
+
+func (c *DeploymentController) companions() *companionReconciler {
+	return &companionReconciler{
+		kubeClient:    c.kubeClient,
+		flaggerClient: c.flaggerClient,
+		logger:        c.logger,
+	}
+}
+
 // getSelectorLabel returns the selector match label
 func (c *DeploymentController) getSelectorLabel(deployment *appsv1.Deployment) (string, error) {
 	for _, l := range c.labels {
@@ -382,6 +527,9 @@ func (c *DeploymentController) HaveDependenciesChanged(cd *flaggerv1.Canary) (bo
 // during a delete to attempt to revert the deployment back to the original state.  Error is returned if unable
 // update the reference deployment replicas to the primary replicas
 func (c *DeploymentController) Finalize(cd *flaggerv1.Canary) error {
+	if err := c.companions().Finalize(cd); err != nil {
+		return err
+	}
 
 	// get ref deployment
 	refDep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
@@ -409,6 +557,13 @@ func (c *DeploymentController) Finalize(cd *flaggerv1.Canary) error {
 			return fmt.Errorf("scale failed: %w", err)
 		}
 	}
+
+	// hand the reference deployment back to KEDA
+	if cd.Spec.AutoscalerRef != nil && cd.Spec.AutoscalerRef.Kind == ScaledObjectKind {
+		if err := c.setScaledObjectPausedReplicas(cd, cd.Spec.AutoscalerRef.Name, nil); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,195 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// referenceReplicasAnnotation holds the primary replicas recorded before the primary was scaled down
+const referenceReplicasAnnotation = "flagger.app/reference-replicas"
+
+// ScaleByWeight sizes the canary deployment in proportion to the canary weight,
+// when enabled the primary deployment is scaled down while the canary receives most of the traffic.
+// The replicas are computed from the primary replicas at the start of the analysis,
+// if the canary is autoscaled by a HPA the canary replicas are left to the HPA.
+func (c *DeploymentController) ScaleByWeight(cd *flaggerv1.Canary, primaryWeight int, canaryWeight int) error {
+	scaling := cd.GetProportionalScaling()
+	if scaling == nil {
+		return nil
+	}
+
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	primary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s get query error: %w", primaryName, cd.Namespace, err)
+	}
+
+	reference := int32Default(primary.Spec.Replicas)
+	if v, ok := primary.Annotations[referenceReplicasAnnotation]; ok {
+		if r, err := strconv.ParseInt(v, 10, 32); err == nil {
+			reference = int32(r)
+		}
+	}
+
+	canaryReplicas := scaling.GetReplicas(reference, canaryWeight)
+	switch {
+	case cd.Spec.AutoscalerRef == nil:
+		if err := c.scale(cd, canaryReplicas); err != nil {
+			return err
+		}
+	case cd.Spec.AutoscalerRef.Kind == ScaledObjectKind:
+		if err := c.setScaledObjectPausedReplicas(cd, cd.Spec.AutoscalerRef.Name, &canaryReplicas); err != nil {
+			return err
+		}
+	}
+
+	if scaling.ScaleDownPrimary && primaryWeight < 50 {
+		return c.scalePrimary(cd, reference, scaling.GetReplicas(reference, primaryWeight))
+	}
+	return c.restorePrimaryReplicas(cd)
+}
+
+// scalePrimary records the reference replicas on the primary deployment and scales it down,
+// for autoscaled primaries the autoscaler is capped at the given replicas
+func (c *DeploymentController) scalePrimary(cd *flaggerv1.Canary, reference int32, replicas int32) error {
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		primary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		_, recorded := primary.Annotations[referenceReplicasAnnotation]
+		if recorded && (cd.Spec.AutoscalerRef != nil || int32Default(primary.Spec.Replicas) == replicas) {
+			return nil
+		}
+
+		primaryCopy := primary.DeepCopy()
+		if primaryCopy.Annotations == nil {
+			primaryCopy.Annotations = make(map[string]string)
+		}
+		primaryCopy.Annotations[referenceReplicasAnnotation] = strconv.Itoa(int(reference))
+		if cd.Spec.AutoscalerRef == nil {
+			primaryCopy.Spec.Replicas = int32p(replicas)
+		}
+
+		_, err = c.kubeClient.AppsV1().Deployments(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("scaling down %s.%s to %v failed: %w", primaryName, cd.Namespace, replicas, err)
+	}
+
+	if cd.Spec.AutoscalerRef == nil {
+		return nil
+	}
+
+	primaryAutoscalerName := fmt.Sprintf("%s-primary", cd.Spec.AutoscalerRef.Name)
+	switch cd.Spec.AutoscalerRef.Kind {
+	case ScaledObjectKind:
+		return c.setScaledObjectPausedReplicas(cd, primaryAutoscalerName, &replicas)
+	case HorizontalPodAutoscalerKind:
+		if isHpaV2(cd) {
+			return c.capPrimaryHpaV2(cd, primaryAutoscalerName, replicas)
+		}
+		return c.capPrimaryHpa(cd, primaryAutoscalerName, replicas)
+	}
+	return nil
+}
+
+// restorePrimaryReplicas scales the primary deployment back to the reference replicas
+// and resets the primary autoscaler to match the canary one
+func (c *DeploymentController) restorePrimaryReplicas(cd *flaggerv1.Canary) error {
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	restored := false
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		primary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		v, ok := primary.Annotations[referenceReplicasAnnotation]
+		if !ok {
+			return nil
+		}
+
+		primaryCopy := primary.DeepCopy()
+		delete(primaryCopy.Annotations, referenceReplicasAnnotation)
+		if r, err := strconv.ParseInt(v, 10, 32); err == nil && cd.Spec.AutoscalerRef == nil {
+			primaryCopy.Spec.Replicas = int32p(int32(r))
+		}
+
+		_, err = c.kubeClient.AppsV1().Deployments(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+		if err == nil {
+			restored = true
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("scaling up %s.%s failed: %w", primaryName, cd.Namespace, err)
+	}
+
+	if !restored || cd.Spec.AutoscalerRef == nil {
+		return nil
+	}
+
+	if cd.Spec.AutoscalerRef.Kind == ScaledObjectKind {
+		primaryAutoscalerName := fmt.Sprintf("%s-primary", cd.Spec.AutoscalerRef.Name)
+		return c.setScaledObjectPausedReplicas(cd, primaryAutoscalerName, nil)
+	}
+	return c.reconcilePrimaryAutoscaler(cd, false)
+}
+
+func (c *DeploymentController) capPrimaryHpa(cd *flaggerv1.Canary, name string, replicas int32) error {
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		hpa, err := c.kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if hpa.Spec.MaxReplicas == replicas {
+			return nil
+		}
+
+		hpaClone := hpa.DeepCopy()
+		hpaClone.Spec.MaxReplicas = replicas
+		if int32Default(hpaClone.Spec.MinReplicas) > replicas {
+			hpaClone.Spec.MinReplicas = int32p(replicas)
+		}
+		_, err = c.kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers(cd.Namespace).Update(context.TODO(), hpaClone, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("updating HorizontalPodAutoscaler %s.%s failed: %w", name, cd.Namespace, err)
+	}
+	return nil
+}
+
+func (c *DeploymentController) capPrimaryHpaV2(cd *flaggerv1.Canary, name string, replicas int32) error {
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		hpa, err := c.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if hpa.Spec.MaxReplicas == replicas {
+			return nil
+		}
+
+		hpaClone := hpa.DeepCopy()
+		hpaClone.Spec.MaxReplicas = replicas
+		if int32Default(hpaClone.Spec.MinReplicas) > replicas {
+			hpaClone.Spec.MinReplicas = int32p(replicas)
+		}
+		_, err = c.kubeClient.AutoscalingV2beta2().HorizontalPodAutoscalers(cd.Namespace).Update(context.TODO(), hpaClone, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("updating HorizontalPodAutoscaler %s.%s failed: %w", name, cd.Namespace, err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestCanaryProportionalScaling_GetReplicas(t *testing.T) {
+	scaling := &flaggerv1.CanaryProportionalScaling{MinReplicas: 2, Headroom: 20}
+
+	tests := []struct {
+		weight   int
+		expected int32
+	}{
+		{weight: 0, expected: 2},
+		{weight: 10, expected: 2},
+		{weight: 30, expected: 4},
+		{weight: 50, expected: 6},
+		{weight: 90, expected: 10},
+		{weight: 100, expected: 10},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, scaling.GetReplicas(10, tt.weight), "weight %d", tt.weight)
+	}
+
+	// the floor defaults to one replica
+	assert.Equal(t, int32(1), (&flaggerv1.CanaryProportionalScaling{}).GetReplicas(10, 0))
+}
+
+func TestDeploymentController_ScaleByWeight(t *testing.T) {
+	mocks := newDeploymentFixture()
+	mocks.canary.Spec.AutoscalerRef = nil
+	mocks.canary.Spec.Analysis.ProportionalScaling = &flaggerv1.CanaryProportionalScaling{
+		ScaleDownPrimary: true,
+	}
+	mocks.initializeCanary(t)
+	setPrimaryReplicas(t, mocks, 10)
+
+	err := mocks.controller.ScaleFromZero(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), getReplicas(t, mocks, "podinfo"))
+
+	err = mocks.controller.ScaleByWeight(mocks.canary, 80, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), getReplicas(t, mocks, "podinfo"))
+	assert.Equal(t, int32(10), getReplicas(t, mocks, "podinfo-primary"))
+
+	err = mocks.controller.ScaleByWeight(mocks.canary, 30, 70)
+	require.NoError(t, err)
+	assert.Equal(t, int32(7), getReplicas(t, mocks, "podinfo"))
+	assert.Equal(t, int32(3), getReplicas(t, mocks, "podinfo-primary"))
+
+	// the reference is kept while the primary is scaled down
+	err = mocks.controller.ScaleByWeight(mocks.canary, 10, 90)
+	require.NoError(t, err)
+	assert.Equal(t, int32(9), getReplicas(t, mocks, "podinfo"))
+	assert.Equal(t, int32(1), getReplicas(t, mocks, "podinfo-primary"))
+
+	err = mocks.controller.Promote(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), getReplicas(t, mocks, "podinfo-primary"))
+
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, primary.Annotations, referenceReplicasAnnotation)
+}
+
+func TestDeploymentController_ScaleByWeightHPA(t *testing.T) {
+	mocks := newDeploymentFixture()
+	mocks.canary.Spec.Analysis.ProportionalScaling = &flaggerv1.CanaryProportionalScaling{
+		ScaleDownPrimary: true,
+	}
+
+	hpa, err := mocks.kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	hpa.Spec.MinReplicas = int32p(4)
+	hpa.Spec.MaxReplicas = 10
+	_, err = mocks.kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers("default").Update(context.TODO(), hpa, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.initializeCanary(t)
+	setPrimaryReplicas(t, mocks, 10)
+
+	err = mocks.controller.ScaleByWeight(mocks.canary, 20, 80)
+	require.NoError(t, err)
+
+	primaryHpa, err := mocks.kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), primaryHpa.Spec.MaxReplicas)
+	assert.Equal(t, int32(2), *primaryHpa.Spec.MinReplicas)
+
+	// rollback restores the primary autoscaler
+	err = mocks.controller.ScaleByWeight(mocks.canary, 100, 0)
+	require.NoError(t, err)
+
+	primaryHpa, err = mocks.kubeClient.AutoscalingV2beta1().HorizontalPodAutoscalers("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), primaryHpa.Spec.MaxReplicas)
+	assert.Equal(t, int32(4), *primaryHpa.Spec.MinReplicas)
+}
+
+func setPrimaryReplicas(t *testing.T, mocks deploymentControllerFixture, replicas int32) {
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	primary.Spec.Replicas = int32p(replicas)
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), primary, metav1.UpdateOptions{})
+	require.NoError(t, err)
+}
+
+func getReplicas(t *testing.T, mocks deploymentControllerFixture, name string) int32 {
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	return int32Default(dep.Spec.Replicas)
+}
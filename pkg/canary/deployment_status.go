@@ -21,7 +21,7 @@ func (c *DeploymentController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1
 		return fmt.Errorf("GetConfigRefs failed: %w", err)
 	}
 
-	return syncCanaryStatus(c.flaggerClient, cd, status, dep.Spec.Template, func(cdCopy *flaggerv1.Canary) {
+	return syncCanaryStatus(c.flaggerClient, cd, status, filterIgnoredChanges(cd, dep.Spec.Template), func(cdCopy *flaggerv1.Canary) {
 		cdCopy.Status.TrackedConfigs = configs
 	})
 }
@@ -41,6 +41,11 @@ func (c *DeploymentController) SetStatusIterations(cd *flaggerv1.Canary, val int
 	return setStatusIterations(c.flaggerClient, cd, val)
 }
 
+// SetStatusMetrics updates the last measured values of the analysis metrics
+func (c *DeploymentController) SetStatusMetrics(cd *flaggerv1.Canary, metrics []flaggerv1.CanaryMetricStatus) error {
+	return setStatusMetrics(c.flaggerClient, cd, metrics)
+}
+
 // SetStatusPhase updates the canary status phase
 func (c *DeploymentController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
 	return setStatusPhase(c.flaggerClient, cd, phase)
@@ -4,6 +4,7 @@ import (
 	"go.uber.org/zap"
 	"k8s.io/client-go/kubernetes"
 
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
 )
 
@@ -29,7 +30,8 @@ func NewFactory(kubeClient kubernetes.Interface,
 	}
 }
 
-func (factory *Factory) Controller(kind string) Controller {
+// Controller returns the canary controller for the target kind
+func (factory *Factory) Controller(targetRef flaggerv1.CrossNamespaceObjectReference) Controller {
 	deploymentCtrl := &DeploymentController{
 		logger:        factory.logger,
 		kubeClient:    factory.kubeClient,
@@ -50,7 +52,16 @@ func (factory *Factory) Controller(kind string) Controller {
 		flaggerClient: factory.flaggerClient,
 	}
 
-	switch kind {
+	knativeCtrl := &KnativeController{
+		logger:        factory.logger,
+		flaggerClient: factory.flaggerClient,
+	}
+
+	if targetRef.IsKnativeService() {
+		return knativeCtrl
+	}
+
+	switch targetRef.Kind {
 	case "DaemonSet":
 		return daemonSetCtrl
 	case "Deployment":
@@ -0,0 +1,156 @@
+package canary
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// filterIgnoredChanges returns a copy of the pod template without the ignored paths,
+// the copy is used to compute the spec hash so that changes to those paths don't trigger an analysis,
+// empty annotations and env lists are set to nil as nil and empty values have different hashes
+func filterIgnoredChanges(cd *flaggerv1.Canary, template corev1.PodTemplateSpec) corev1.PodTemplateSpec {
+	ignored := cd.Spec.IgnoredChanges
+	if ignored == nil {
+		return template
+	}
+
+	res := *template.DeepCopy()
+	for key := range res.Annotations {
+		if hasIgnoredPrefix(ignored.AnnotationPrefixes, key) {
+			delete(res.Annotations, key)
+		}
+	}
+	if len(res.Annotations) == 0 {
+		res.Annotations = nil
+	}
+
+	for i, container := range res.Spec.Containers {
+		var env []corev1.EnvVar
+		for _, e := range container.Env {
+			if !containsString(ignored.Env, e.Name) {
+				env = append(env, e)
+			}
+		}
+		res.Spec.Containers[i].Env = env
+
+		if ignored.Resources {
+			res.Spec.Containers[i].Resources = corev1.ResourceRequirements{}
+		}
+	}
+
+	return res
+}
+
+// applyIgnoredChanges copies the ignored paths from the canary pod template to the primary one
+// and returns true if the primary template has changed
+func applyIgnoredChanges(cd *flaggerv1.Canary, canary corev1.PodTemplateSpec, primary *corev1.PodTemplateSpec) bool {
+	ignored := cd.Spec.IgnoredChanges
+	if ignored == nil {
+		return false
+	}
+
+	changed := false
+	for key := range primary.Annotations {
+		if _, ok := canary.Annotations[key]; !ok && hasIgnoredPrefix(ignored.AnnotationPrefixes, key) {
+			delete(primary.Annotations, key)
+			changed = true
+		}
+	}
+	for key, value := range canary.Annotations {
+		if hasIgnoredPrefix(ignored.AnnotationPrefixes, key) && primary.Annotations[key] != value {
+			if primary.Annotations == nil {
+				primary.Annotations = make(map[string]string)
+			}
+			primary.Annotations[key] = value
+			changed = true
+		}
+	}
+
+	for i, container := range primary.Spec.Containers {
+		var source *corev1.Container
+		for j := range canary.Spec.Containers {
+			if canary.Spec.Containers[j].Name == container.Name {
+				source = &canary.Spec.Containers[j]
+			}
+		}
+		if source == nil {
+			continue
+		}
+
+		for _, name := range ignored.Env {
+			if env, ok := setEnvVar(container.Env, findEnvVar(source.Env, name), name); ok {
+				primary.Spec.Containers[i].Env = env
+				container.Env = env
+				changed = true
+			}
+		}
+
+		if ignored.Resources && !equality.Semantic.DeepEqual(container.Resources, source.Resources) {
+			primary.Spec.Containers[i].Resources = *source.Resources.DeepCopy()
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// setEnvVar replaces, adds or removes the named variable and returns true if the list has changed
+func setEnvVar(env []corev1.EnvVar, value *corev1.EnvVar, name string) ([]corev1.EnvVar, bool) {
+	current := findEnvVar(env, name)
+	switch {
+	case value == nil && current == nil:
+		return env, false
+	case value == nil:
+		var res []corev1.EnvVar
+		for _, e := range env {
+			if e.Name != name {
+				res = append(res, e)
+			}
+		}
+		return res, true
+	case current == nil:
+		return append(env, *value.DeepCopy()), true
+	case equality.Semantic.DeepEqual(*current, *value):
+		return env, false
+	}
+
+	res := make([]corev1.EnvVar, len(env))
+	for i, e := range env {
+		if e.Name == name {
+			e = *value.DeepCopy()
+		}
+		res[i] = e
+	}
+	return res, true
+}
+
+func findEnvVar(env []corev1.EnvVar, name string) *corev1.EnvVar {
+	for i := range env {
+		if env[i].Name == name {
+			return &env[i]
+		}
+	}
+	return nil
+}
+
+func hasIgnoredPrefix(prefixes []string, key string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
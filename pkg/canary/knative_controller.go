@@ -0,0 +1,253 @@
+package canary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	knative "github.com/weaveworks/flagger/pkg/apis/knative/v1"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+// KnativeController is managing the operations for Knative Service kind,
+// the primary is the revision recorded in the service annotations and
+// the canary is the latest revision created from the service template
+type KnativeController struct {
+	flaggerClient clientset.Interface
+	logger        *zap.SugaredLogger
+}
+
+// SetStatusFailedChecks updates the canary failed checks counter
+func (c *KnativeController) SetStatusFailedChecks(cd *flaggerv1.Canary, val int) error {
+	return setStatusFailedChecks(c.flaggerClient, cd, val)
+}
+
+// SetStatusWeight updates the canary status weight value
+func (c *KnativeController) SetStatusWeight(cd *flaggerv1.Canary, val int) error {
+	return setStatusWeight(c.flaggerClient, cd, val)
+}
+
+// SetStatusIterations updates the canary status iterations value
+func (c *KnativeController) SetStatusIterations(cd *flaggerv1.Canary, val int) error {
+	return setStatusIterations(c.flaggerClient, cd, val)
+}
+
+// SetStatusMetrics updates the last measured values of the analysis metrics
+func (c *KnativeController) SetStatusMetrics(cd *flaggerv1.Canary, metrics []flaggerv1.CanaryMetricStatus) error {
+	return setStatusMetrics(c.flaggerClient, cd, metrics)
+}
+
+// SetStatusPhase updates the canary status phase
+func (c *KnativeController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
+	return setStatusPhase(c.flaggerClient, cd, phase)
+}
+
+// GetMetadata returns an empty selector, the Knative Services are managed by Knative
+func (c *KnativeController) GetMetadata(_ *flaggerv1.Canary) (string, map[string]int32, error) {
+	return "", nil, nil
+}
+
+// Initialize records the latest ready revision as primary if the service is not managed by Flagger yet
+func (c *KnativeController) Initialize(cd *flaggerv1.Canary) error {
+	svc, err := c.getService(cd)
+	if err != nil {
+		return err
+	}
+
+	if svc.Annotations[flaggerv1.KnativePrimaryRevisionAnnotation] != "" {
+		return nil
+	}
+
+	if svc.Status.LatestReadyRevisionName == "" {
+		return fmt.Errorf("knative service %s.%s has no ready revision", svc.Name, svc.Namespace)
+	}
+
+	if err := c.setPrimaryRevision(cd, svc.Status.LatestReadyRevisionName); err != nil {
+		return err
+	}
+
+	c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+		Infof("Knative service %s.%s primary revision set to %s", svc.Name, svc.Namespace, svc.Status.LatestReadyRevisionName)
+	return nil
+}
+
+// Promote records the latest ready revision as primary
+func (c *KnativeController) Promote(cd *flaggerv1.Canary) error {
+	svc, err := c.getService(cd)
+	if err != nil {
+		return err
+	}
+
+	if svc.Status.LatestReadyRevisionName == "" {
+		return fmt.Errorf("knative service %s.%s has no ready revision", svc.Name, svc.Namespace)
+	}
+
+	return c.setPrimaryRevision(cd, svc.Status.LatestReadyRevisionName)
+}
+
+// RevertPrimary is not supported for Knative Service targets
+func (c *KnativeController) RevertPrimary(cd *flaggerv1.Canary, _ int64) error {
+	return fmt.Errorf("reverting the primary of %s.%s is not supported for Knative Service targets", cd.Name, cd.Namespace)
+}
+
+// SyncIgnoredChanges is a no-op for Knative Service targets as the primary is a Knative revision
+func (c *KnativeController) SyncIgnoredChanges(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// HasTargetChanged returns true if the service revision template has changed
+func (c *KnativeController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
+	svc, err := c.getService(cd)
+	if err != nil {
+		return false, err
+	}
+	return hasSpecChanged(cd, svc.Spec.Template)
+}
+
+// ScaleToZero is a noop, the Knative autoscaler removes the pods of the revisions without traffic
+func (c *KnativeController) ScaleToZero(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// ScaleFromZero is a noop, the Knative autoscaler starts the pods when the revision receives traffic
+func (c *KnativeController) ScaleFromZero(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// ScaleByWeight is a noop, the Knative autoscaler sizes the revisions based on the traffic they receive
+func (c *KnativeController) ScaleByWeight(_ *flaggerv1.Canary, _ int, _ int) error {
+	return nil
+}
+
+// SyncStatus encodes the revision template and updates the canary status
+func (c *KnativeController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.CanaryStatus) error {
+	svc, err := c.getService(cd)
+	if err != nil {
+		return err
+	}
+	return syncCanaryStatus(c.flaggerClient, cd, status, svc.Spec.Template, func(cdCopy *flaggerv1.Canary) {})
+}
+
+// HaveDependenciesChanged returns false, config tracking is not supported for Knative Services
+func (c *KnativeController) HaveDependenciesChanged(_ *flaggerv1.Canary) (bool, error) {
+	return false, nil
+}
+
+// IsPrimaryReady checks the ready condition of the primary revision
+func (c *KnativeController) IsPrimaryReady(cd *flaggerv1.Canary) error {
+	svc, err := c.getService(cd)
+	if err != nil {
+		return err
+	}
+
+	primary := svc.Annotations[flaggerv1.KnativePrimaryRevisionAnnotation]
+	if primary == "" {
+		return fmt.Errorf("knative service %s.%s primary revision not found", svc.Name, svc.Namespace)
+	}
+
+	if _, err := c.isRevisionReady(cd, primary); err != nil {
+		return fmt.Errorf("primary revision %s.%s not ready: %w", primary, cd.Namespace, err)
+	}
+	return nil
+}
+
+// IsCanaryReady checks the ready condition of the latest created revision,
+// it returns a non retriable error if the revision failed
+func (c *KnativeController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error) {
+	svc, err := c.getService(cd)
+	if err != nil {
+		return true, err
+	}
+
+	if svc.Generation > svc.Status.ObservedGeneration {
+		return true, fmt.Errorf("knative service %s.%s not ready: waiting for the latest generation to be observed",
+			svc.Name, svc.Namespace)
+	}
+
+	canary := svc.Status.LatestCreatedRevisionName
+	if canary == "" {
+		return true, fmt.Errorf("knative service %s.%s not ready: no revision created", svc.Name, svc.Namespace)
+	}
+
+	retriable, err := c.isRevisionReady(cd, canary)
+	if err != nil {
+		return retriable, fmt.Errorf("canary revision %s.%s not ready: %w", canary, cd.Namespace, err)
+	}
+	return true, nil
+}
+
+// Finalize removes the primary revision annotation from the Knative Service
+func (c *KnativeController) Finalize(cd *flaggerv1.Canary) error {
+	return c.setPrimaryRevision(cd, "")
+}
+
+func (c *KnativeController) getService(cd *flaggerv1.Canary) (*knative.Service, error) {
+	targetName := cd.Spec.TargetRef.Name
+	svc, err := c.flaggerClient.ServingV1().Services(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("knative service %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+	return svc, nil
+}
+
+// isRevisionReady returns a non retriable error if the revision ready condition is false
+func (c *KnativeController) isRevisionReady(cd *flaggerv1.Canary, name string) (bool, error) {
+	rev, err := c.flaggerClient.ServingV1().Revisions(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return true, fmt.Errorf("knative revision %s.%s get query error: %w", name, cd.Namespace, err)
+	}
+
+	for _, condition := range rev.Status.Conditions {
+		if condition.Type != knative.ConditionReady {
+			continue
+		}
+		switch condition.Status {
+		case corev1.ConditionTrue:
+			return true, nil
+		case corev1.ConditionFalse:
+			return false, fmt.Errorf("revision %q failed: %s %s", name, condition.Reason, condition.Message)
+		}
+	}
+	return true, fmt.Errorf("waiting for revision %q to become ready", name)
+}
+
+// setPrimaryRevision sets the primary revision annotation, an empty revision removes the annotation,
+// the annotation is set with a merge patch so that the service fields not modelled by Flagger are left untouched
+func (c *KnativeController) setPrimaryRevision(cd *flaggerv1.Canary, revision string) error {
+	targetName := cd.Spec.TargetRef.Name
+	svc, err := c.getService(cd)
+	if err != nil {
+		return err
+	}
+
+	if svc.Annotations[flaggerv1.KnativePrimaryRevisionAnnotation] == revision {
+		return nil
+	}
+
+	var value interface{}
+	if revision != "" {
+		value = revision
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				flaggerv1.KnativePrimaryRevisionAnnotation: value,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("knative service %s.%s patch encoding failed: %w", targetName, cd.Namespace, err)
+	}
+
+	_, err = c.flaggerClient.ServingV1().Services(cd.Namespace).Patch(context.TODO(), targetName, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("knative service %s.%s update query error: %w", targetName, cd.Namespace, err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestKnativeController_Initialize(t *testing.T) {
+	mocks := newKnativeFixture()
+	err := mocks.controller.Initialize(mocks.canary)
+	require.NoError(t, err)
+
+	svc, err := mocks.flaggerClient.ServingV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-00001", svc.Annotations[flaggerv1.KnativePrimaryRevisionAnnotation])
+
+	err = mocks.controller.IsPrimaryReady(mocks.canary)
+	require.NoError(t, err)
+}
+
+func TestKnativeController_Promote(t *testing.T) {
+	mocks := newKnativeFixture()
+	require.NoError(t, mocks.controller.Initialize(mocks.canary))
+
+	// new revision created from the updated template
+	svc, err := mocks.flaggerClient.ServingV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	svc.Spec.Template.Spec.Containers[0].Image = "quay.io/stefanprodan/podinfo:1.2.1"
+	svc.Generation = 2
+	svc.Status.ObservedGeneration = 2
+	svc.Status.LatestCreatedRevisionName = "podinfo-00002"
+	_, err = mocks.flaggerClient.ServingV1().Services("default").Update(context.TODO(), svc, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	retriable, err := mocks.controller.IsCanaryReady(mocks.canary)
+	require.Error(t, err)
+	assert.True(t, retriable)
+
+	mocks.setRevisionReady(t, "podinfo-00002", corev1.ConditionTrue, "")
+
+	retriable, err = mocks.controller.IsCanaryReady(mocks.canary)
+	require.NoError(t, err)
+	assert.True(t, retriable)
+
+	svc.Status.LatestReadyRevisionName = "podinfo-00002"
+	_, err = mocks.flaggerClient.ServingV1().Services("default").Update(context.TODO(), svc, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, mocks.controller.Promote(mocks.canary))
+
+	svc, err = mocks.flaggerClient.ServingV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-00002", svc.Annotations[flaggerv1.KnativePrimaryRevisionAnnotation])
+}
+
+func TestKnativeController_IsCanaryReady_Failed(t *testing.T) {
+	mocks := newKnativeFixture()
+
+	svc, err := mocks.flaggerClient.ServingV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	svc.Status.LatestCreatedRevisionName = "podinfo-00002"
+	_, err = mocks.flaggerClient.ServingV1().Services("default").Update(context.TODO(), svc, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.setRevisionReady(t, "podinfo-00002", corev1.ConditionFalse, "ContainerMissing")
+
+	retriable, err := mocks.controller.IsCanaryReady(mocks.canary)
+	require.Error(t, err)
+	assert.False(t, retriable)
+}
+
+func TestKnativeController_HasTargetChanged(t *testing.T) {
+	mocks := newKnativeFixture()
+	require.NoError(t, mocks.controller.Initialize(mocks.canary))
+	require.NoError(t, mocks.controller.SyncStatus(mocks.canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseInitialized}))
+
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	changed, err := mocks.controller.HasTargetChanged(cd)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	svc, err := mocks.flaggerClient.ServingV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	svc.Spec.Template.Spec.Containers[0].Image = "quay.io/stefanprodan/podinfo:1.2.1"
+	_, err = mocks.flaggerClient.ServingV1().Services("default").Update(context.TODO(), svc, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	changed, err = mocks.controller.HasTargetChanged(cd)
+	require.NoError(t, err)
+	assert.True(t, changed)
+}
+
+func TestKnativeController_Finalize(t *testing.T) {
+	mocks := newKnativeFixture()
+	require.NoError(t, mocks.controller.Initialize(mocks.canary))
+	require.NoError(t, mocks.controller.Finalize(mocks.canary))
+
+	svc, err := mocks.flaggerClient.ServingV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	_, exists := svc.Annotations[flaggerv1.KnativePrimaryRevisionAnnotation]
+	assert.False(t, exists)
+}
@@ -0,0 +1,115 @@
+package canary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	knative "github.com/weaveworks/flagger/pkg/apis/knative/v1"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+	fakeFlagger "github.com/weaveworks/flagger/pkg/client/clientset/versioned/fake"
+	"github.com/weaveworks/flagger/pkg/logger"
+)
+
+type knativeControllerFixture struct {
+	canary        *flaggerv1.Canary
+	controller    KnativeController
+	flaggerClient clientset.Interface
+}
+
+func newKnativeFixture() knativeControllerFixture {
+	canary := newKnativeControllerTestCanary()
+	flaggerClient := fakeFlagger.NewSimpleClientset(
+		canary,
+		newKnativeControllerTestService(),
+		newKnativeControllerTestRevision("podinfo-00001", corev1.ConditionTrue),
+		newKnativeControllerTestRevision("podinfo-00002", corev1.ConditionUnknown),
+	)
+
+	logger, _ := logger.NewLogger("debug")
+	return knativeControllerFixture{
+		canary: canary,
+		controller: KnativeController{
+			flaggerClient: flaggerClient,
+			logger:        logger,
+		},
+		flaggerClient: flaggerClient,
+	}
+}
+
+// setRevisionReady updates the ready condition of a revision, the revisions client is read-only
+func (k knativeControllerFixture) setRevisionReady(t *testing.T, name string, ready corev1.ConditionStatus, reason string) {
+	rev := newKnativeControllerTestRevision(name, ready)
+	rev.Status.Conditions[0].Reason = reason
+	tracker := k.flaggerClient.(*fakeFlagger.Clientset).Tracker()
+	err := tracker.Update(knative.SchemeGroupVersion.WithResource("revisions"), rev, rev.Namespace)
+	require.NoError(t, err)
+}
+
+func newKnativeControllerTestCanary() *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		TypeMeta: metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo",
+		},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{
+				Name:       "podinfo",
+				APIVersion: flaggerv1.KnativeServiceAPIVersion,
+				Kind:       "Service",
+			},
+		},
+	}
+}
+
+func newKnativeControllerTestService() *knative.Service {
+	return &knative.Service{
+		TypeMeta: metav1.TypeMeta{APIVersion: knative.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "default",
+			Name:       "podinfo",
+			Generation: 1,
+		},
+		Spec: knative.ServiceSpec{
+			Template: knative.RevisionTemplateSpec{
+				Spec: knative.RevisionSpec{
+					PodSpec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:  "podinfo",
+								Image: "quay.io/stefanprodan/podinfo:1.2.0",
+							},
+						},
+					},
+				},
+			},
+		},
+		Status: knative.ServiceStatus{
+			ObservedGeneration:        1,
+			LatestReadyRevisionName:   "podinfo-00001",
+			LatestCreatedRevisionName: "podinfo-00001",
+		},
+	}
+}
+
+func newKnativeControllerTestRevision(name string, ready corev1.ConditionStatus) *knative.Revision {
+	return &knative.Revision{
+		TypeMeta: metav1.TypeMeta{APIVersion: knative.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+		},
+		Status: knative.RevisionStatus{
+			Conditions: []knative.Condition{
+				{
+					Type:   knative.ConditionReady,
+					Status: ready,
+				},
+			},
+		},
+	}
+}
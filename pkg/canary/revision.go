@@ -0,0 +1,432 @@
+package canary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// revisionCanaryLabel selects the ControllerRevisions of a canary
+const revisionCanaryLabel = "flagger.app/canary"
+
+// revisionConfigsAnnotation holds the snapshots of the primary ConfigMaps and Secrets
+// taken when a revision is recorded, in the format {"<type>/<primary name>": "<snapshot name>"}
+const revisionConfigsAnnotation = "flagger.app/primary-configs"
+
+// revisionHistory stores the pod templates applied to the primary workload
+// as ControllerRevisions owned by the canary, the revisions are numbered in the promotion order
+type revisionHistory struct {
+	kubeClient kubernetes.Interface
+	logger     *zap.SugaredLogger
+}
+
+// Record saves the primary pod template as the newest revision,
+// a template that was already recorded is moved to the top of the history.
+// The content of the primary ConfigMaps and Secrets that are not versioned is saved
+// with the revision so that it can be restored when reverting to it.
+func (rh *revisionHistory) Record(cd *flaggerv1.Canary, template corev1.PodTemplateSpec) error {
+	revisions, err := rh.List(cd)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(template)
+	if err != nil {
+		return fmt.Errorf("pod template marshal failed: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s", cd.Name, computeHash(data))
+	next := int64(1)
+	if len(revisions) > 0 {
+		if revisions[0].Name == name {
+			return nil
+		}
+		next = revisions[0].Revision + 1
+	}
+
+	for _, rev := range revisions {
+		if rev.Name == name {
+			revClone := rev.DeepCopy()
+			revClone.Revision = next
+			_, err := rh.kubeClient.AppsV1().ControllerRevisions(cd.Namespace).Update(context.TODO(), revClone, metav1.UpdateOptions{})
+			if err != nil {
+				return fmt.Errorf("updating ControllerRevision %s.%s failed: %w", name, cd.Namespace, err)
+			}
+			return nil
+		}
+	}
+
+	snapshots, err := rh.snapshotConfigs(cd, template)
+	if err != nil {
+		return err
+	}
+	var annotations map[string]string
+	if len(snapshots) > 0 {
+		value, err := json.Marshal(snapshots)
+		if err != nil {
+			return fmt.Errorf("primary configs marshal failed: %w", err)
+		}
+		annotations = map[string]string{revisionConfigsAnnotation: string(value)}
+	}
+
+	rev := &appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       cd.Namespace,
+			Labels:          map[string]string{revisionCanaryLabel: cd.Name},
+			Annotations:     annotations,
+			OwnerReferences: []metav1.OwnerReference{newCanaryOwnerRef(cd)},
+		},
+		Data:     runtime.RawExtension{Raw: data},
+		Revision: next,
+	}
+	_, err = rh.kubeClient.AppsV1().ControllerRevisions(cd.Namespace).Create(context.TODO(), rev, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("creating ControllerRevision %s.%s failed: %w", name, cd.Namespace, err)
+	}
+	rh.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+		Infof("ControllerRevision %s.%s created", name, cd.Namespace)
+
+	// garbage collect the oldest revisions
+	revisions = append([]appsv1.ControllerRevision{*rev}, revisions...)
+	for i := cd.GetRevisionHistoryLimit(); i < len(revisions); i++ {
+		err := rh.kubeClient.AppsV1().ControllerRevisions(cd.Namespace).Delete(context.TODO(), revisions[i].Name, metav1.DeleteOptions{})
+		if err != nil {
+			return fmt.Errorf("deleting ControllerRevision %s.%s failed: %w", revisions[i].Name, cd.Namespace, err)
+		}
+	}
+	if len(revisions) > cd.GetRevisionHistoryLimit() {
+		revisions = revisions[:cd.GetRevisionHistoryLimit()]
+	}
+
+	return rh.collectVersionedConfigs(cd, revisions)
+}
+
+// collectVersionedConfigs deletes the versioned ConfigMaps and Secrets and the snapshots
+// that are not referenced by any of the kept revisions
+func (rh *revisionHistory) collectVersionedConfigs(cd *flaggerv1.Canary, revisions []appsv1.ControllerRevision) error {
+	inUse := make(map[string]bool)
+	for _, rev := range revisions {
+		template, err := decodeRevision(rev)
+		if err != nil {
+			return err
+		}
+		for name := range getPodSpecConfigNames(template.Spec) {
+			inUse[name] = true
+		}
+
+		snapshots, err := decodeRevisionConfigs(rev)
+		if err != nil {
+			return err
+		}
+		for ref, snapshot := range snapshots {
+			inUse[fmt.Sprintf("%s/%s", strings.SplitN(ref, "/", 2)[0], snapshot)] = true
+		}
+	}
+
+	selector := metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", revisionCanaryLabel, cd.Name)}
+	configs, err := rh.kubeClient.CoreV1().ConfigMaps(cd.Namespace).List(context.TODO(), selector)
+	if err != nil {
+		return fmt.Errorf("configmaps list query error for %s.%s: %w", cd.Name, cd.Namespace, err)
+	}
+	for _, config := range configs.Items {
+		if inUse[fmt.Sprintf("%s/%s", ConfigRefMap, config.Name)] {
+			continue
+		}
+		err := rh.kubeClient.CoreV1().ConfigMaps(cd.Namespace).Delete(context.TODO(), config.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("deleting configmap %s.%s failed: %w", config.Name, cd.Namespace, err)
+		}
+		rh.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("ConfigMap %s deleted", config.Name)
+	}
+
+	secrets, err := rh.kubeClient.CoreV1().Secrets(cd.Namespace).List(context.TODO(), selector)
+	if err != nil {
+		return fmt.Errorf("secrets list query error for %s.%s: %w", cd.Name, cd.Namespace, err)
+	}
+	for _, secret := range secrets.Items {
+		if inUse[fmt.Sprintf("%s/%s", ConfigRefSecret, secret.Name)] {
+			continue
+		}
+		err := rh.kubeClient.CoreV1().Secrets(cd.Namespace).Delete(context.TODO(), secret.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("deleting secret %s.%s failed: %w", secret.Name, cd.Namespace, err)
+		}
+		rh.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("Secret %s deleted", secret.Name)
+	}
+	return nil
+}
+
+// List returns the canary revisions ordered from the newest to the oldest
+func (rh *revisionHistory) List(cd *flaggerv1.Canary) ([]appsv1.ControllerRevision, error) {
+	list, err := rh.kubeClient.AppsV1().ControllerRevisions(cd.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", revisionCanaryLabel, cd.Name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ControllerRevisions list query error for %s.%s: %w", cd.Name, cd.Namespace, err)
+	}
+
+	revisions := list.Items
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision > revisions[j].Revision
+	})
+	return revisions, nil
+}
+
+// Get returns the pod template of a promoted revision,
+// zero selects the revision promoted before the last one
+func (rh *revisionHistory) Get(cd *flaggerv1.Canary, revision int64) (*corev1.PodTemplateSpec, error) {
+	rev, err := rh.get(cd, revision)
+	if err != nil {
+		return nil, err
+	}
+	return decodeRevision(*rev)
+}
+
+// Revert restores the primary ConfigMaps and Secrets saved with a promoted revision
+// and returns its pod template, zero selects the revision promoted before the last one
+func (rh *revisionHistory) Revert(cd *flaggerv1.Canary, revision int64) (*corev1.PodTemplateSpec, error) {
+	rev, err := rh.get(cd, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := decodeRevision(*rev)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := decodeRevisionConfigs(*rev)
+	if err != nil {
+		return nil, err
+	}
+	for ref, snapshot := range snapshots {
+		parts := strings.SplitN(ref, "/", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("ControllerRevision %s.%s invalid config %s", rev.Name, rev.Namespace, ref)
+		}
+		if err := rh.restoreConfig(cd, ConfigRefType(parts[0]), parts[1], snapshot); err != nil {
+			return nil, err
+		}
+	}
+	return template, nil
+}
+
+func (rh *revisionHistory) get(cd *flaggerv1.Canary, revision int64) (*appsv1.ControllerRevision, error) {
+	revisions, err := rh.List(cd)
+	if err != nil {
+		return nil, err
+	}
+
+	if revision == 0 {
+		if len(revisions) < 2 {
+			return nil, fmt.Errorf("no previous revision found for %s.%s", cd.Name, cd.Namespace)
+		}
+		return &revisions[1], nil
+	}
+
+	for i := range revisions {
+		if revisions[i].Revision == revision {
+			return &revisions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("revision %v not found for %s.%s", revision, cd.Name, cd.Namespace)
+}
+
+// snapshotConfigs copies the primary ConfigMaps and Secrets referenced by the pod template
+// to immutable copies suffixed with the content checksum, the versioned copies
+// and the configs not managed by Flagger are skipped as their content doesn't change
+func (rh *revisionHistory) snapshotConfigs(cd *flaggerv1.Canary, template corev1.PodTemplateSpec) (map[string]string, error) {
+	res := make(map[string]string)
+	for ref := range getPodSpecConfigNames(template.Spec) {
+		parts := strings.SplitN(ref, "/", 2)
+		switch ConfigRefType(parts[0]) {
+		case ConfigRefMap:
+			config, err := rh.kubeClient.CoreV1().ConfigMaps(cd.Namespace).Get(context.TODO(), parts[1], metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, fmt.Errorf("configmap %s.%s get query error: %w", parts[1], cd.Namespace, err)
+			}
+			if !metav1.IsControlledBy(config, cd) || config.Labels[revisionCanaryLabel] != "" {
+				continue
+			}
+
+			immutable := true
+			snapshot := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            fmt.Sprintf("%s-%s", config.Name, checksum(config.Data)),
+					Namespace:       cd.Namespace,
+					Labels:          makeVersionedConfigLabels(cd, config.Labels),
+					OwnerReferences: config.OwnerReferences,
+				},
+				Immutable:  &immutable,
+				Data:       config.Data,
+				BinaryData: config.BinaryData,
+			}
+			_, err = rh.kubeClient.CoreV1().ConfigMaps(cd.Namespace).Create(context.TODO(), snapshot, metav1.CreateOptions{})
+			if err != nil && !errors.IsAlreadyExists(err) {
+				return nil, fmt.Errorf("creating configmap %s.%s failed: %w", snapshot.Name, cd.Namespace, err)
+			}
+			res[ref] = snapshot.Name
+		case ConfigRefSecret:
+			secret, err := rh.kubeClient.CoreV1().Secrets(cd.Namespace).Get(context.TODO(), parts[1], metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, fmt.Errorf("secret %s.%s get query error: %w", parts[1], cd.Namespace, err)
+			}
+			if !metav1.IsControlledBy(secret, cd) || secret.Labels[revisionCanaryLabel] != "" {
+				continue
+			}
+
+			immutable := true
+			snapshot := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            fmt.Sprintf("%s-%s", secret.Name, checksum(secret.Data)),
+					Namespace:       cd.Namespace,
+					Labels:          makeVersionedConfigLabels(cd, secret.Labels),
+					OwnerReferences: secret.OwnerReferences,
+				},
+				Immutable: &immutable,
+				Type:      secret.Type,
+				Data:      secret.Data,
+			}
+			_, err = rh.kubeClient.CoreV1().Secrets(cd.Namespace).Create(context.TODO(), snapshot, metav1.CreateOptions{})
+			if err != nil && !errors.IsAlreadyExists(err) {
+				return nil, fmt.Errorf("creating secret %s.%s failed: %w", snapshot.Name, cd.Namespace, err)
+			}
+			res[ref] = snapshot.Name
+		}
+	}
+	return res, nil
+}
+
+// restoreConfig copies the content of a snapshot to the primary ConfigMap or Secret
+func (rh *revisionHistory) restoreConfig(cd *flaggerv1.Canary, kind ConfigRefType, name string, snapshot string) error {
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		switch kind {
+		case ConfigRefMap:
+			source, err := rh.kubeClient.CoreV1().ConfigMaps(cd.Namespace).Get(context.TODO(), snapshot, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			config, err := rh.kubeClient.CoreV1().ConfigMaps(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+
+			configCopy := config.DeepCopy()
+			configCopy.Data = source.Data
+			configCopy.BinaryData = source.BinaryData
+			_, err = rh.kubeClient.CoreV1().ConfigMaps(cd.Namespace).Update(context.TODO(), configCopy, metav1.UpdateOptions{})
+			return err
+		case ConfigRefSecret:
+			source, err := rh.kubeClient.CoreV1().Secrets(cd.Namespace).Get(context.TODO(), snapshot, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			secret, err := rh.kubeClient.CoreV1().Secrets(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+
+			secretCopy := secret.DeepCopy()
+			secretCopy.Data = source.Data
+			_, err = rh.kubeClient.CoreV1().Secrets(cd.Namespace).Update(context.TODO(), secretCopy, metav1.UpdateOptions{})
+			return err
+		default:
+			return fmt.Errorf("config type %s not supported", kind)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("restoring %s %s.%s from %s failed: %w", kind, name, cd.Namespace, snapshot, err)
+	}
+
+	rh.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+		Infof("%s %s.%s restored from %s", kind, name, cd.Namespace, snapshot)
+	return nil
+}
+
+// getPodSpecConfigNames returns the ConfigMaps and Secrets referenced by a pod spec
+// in the same format as the ConfigRef names
+func getPodSpecConfigNames(spec corev1.PodSpec) map[string]bool {
+	res := make(map[string]bool)
+	for _, volume := range spec.Volumes {
+		if cmv := volume.ConfigMap; cmv != nil {
+			res[fmt.Sprintf("%s/%s", ConfigRefMap, cmv.Name)] = true
+		}
+		if sv := volume.Secret; sv != nil {
+			res[fmt.Sprintf("%s/%s", ConfigRefSecret, sv.SecretName)] = true
+		}
+		if projected := volume.Projected; projected != nil {
+			for _, source := range projected.Sources {
+				if cmv := source.ConfigMap; cmv != nil {
+					res[fmt.Sprintf("%s/%s", ConfigRefMap, cmv.Name)] = true
+				}
+				if sv := source.Secret; sv != nil {
+					res[fmt.Sprintf("%s/%s", ConfigRefSecret, sv.Name)] = true
+				}
+			}
+		}
+	}
+
+	for _, container := range spec.Containers {
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+				res[fmt.Sprintf("%s/%s", ConfigRefMap, ref.Name)] = true
+			}
+			if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+				res[fmt.Sprintf("%s/%s", ConfigRefSecret, ref.Name)] = true
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if ref := envFrom.ConfigMapRef; ref != nil {
+				res[fmt.Sprintf("%s/%s", ConfigRefMap, ref.Name)] = true
+			}
+			if ref := envFrom.SecretRef; ref != nil {
+				res[fmt.Sprintf("%s/%s", ConfigRefSecret, ref.Name)] = true
+			}
+		}
+	}
+	return res
+}
+
+// decodeRevisionConfigs returns the snapshots of the primary configs saved with a revision
+func decodeRevisionConfigs(rev appsv1.ControllerRevision) (map[string]string, error) {
+	res := make(map[string]string)
+	value, ok := rev.Annotations[revisionConfigsAnnotation]
+	if !ok {
+		return res, nil
+	}
+	if err := json.Unmarshal([]byte(value), &res); err != nil {
+		return nil, fmt.Errorf("ControllerRevision %s.%s %s unmarshal failed: %w", rev.Name, rev.Namespace, revisionConfigsAnnotation, err)
+	}
+	return res, nil
+}
+
+func decodeRevision(rev appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error) {
+	template := &corev1.PodTemplateSpec{}
+	if err := json.Unmarshal(rev.Data.Raw, template); err != nil {
+		return nil, fmt.Errorf("ControllerRevision %s.%s unmarshal failed: %w", rev.Name, rev.Namespace, err)
+	}
+	return template, nil
+}
@@ -0,0 +1,149 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestRevisionHistory_Record(t *testing.T) {
+	mocks := newDeploymentFixture()
+	rh := mocks.controller.revisions()
+
+	templates := make([]corev1.PodTemplateSpec, flaggerv1.RevisionHistoryLimit+2)
+	for i := range templates {
+		templates[i] = corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "podinfo", Image: fmt.Sprintf("podinfo:%d", i)}},
+			},
+		}
+		require.NoError(t, rh.Record(mocks.canary, templates[i]))
+	}
+
+	// the oldest revisions are garbage collected
+	revisions, err := rh.List(mocks.canary)
+	require.NoError(t, err)
+	require.Len(t, revisions, flaggerv1.RevisionHistoryLimit)
+	assert.Equal(t, int64(len(templates)), revisions[0].Revision)
+
+	// recording the current template is a no-op
+	require.NoError(t, rh.Record(mocks.canary, templates[len(templates)-1]))
+	revisions, err = rh.List(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(templates)), revisions[0].Revision)
+
+	// recording a known template moves it to the top
+	require.NoError(t, rh.Record(mocks.canary, templates[len(templates)-2]))
+	previous, err := rh.Get(mocks.canary, 0)
+	require.NoError(t, err)
+	assert.Equal(t, templates[len(templates)-1].Spec.Containers[0].Image, previous.Spec.Containers[0].Image)
+}
+
+func TestDeploymentController_RevertPrimary(t *testing.T) {
+	mocks := newDeploymentFixture()
+	mocks.initializeCanary(t)
+
+	// no revision to revert to
+	err := mocks.controller.RevertPrimary(mocks.canary, 0)
+	require.Error(t, err)
+
+	dep2 := newDeploymentControllerTestV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = mocks.controller.Promote(mocks.canary)
+	require.NoError(t, err)
+
+	depPrimary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, dep2.Spec.Template.Spec.Containers[0].Image, depPrimary.Spec.Template.Spec.Containers[0].Image)
+
+	err = mocks.controller.RevertPrimary(mocks.canary, 0)
+	require.NoError(t, err)
+
+	depPrimary, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, newDeploymentControllerTest().Spec.Template.Spec.Containers[0].Image,
+		depPrimary.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, "podinfo-primary", depPrimary.Spec.Template.Labels["name"])
+}
+
+func TestRevisionHistory_Limit(t *testing.T) {
+	mocks := newDeploymentFixture()
+	mocks.canary.Spec.RevisionHistoryLimit = int32p(2)
+	rh := mocks.controller.revisions()
+
+	for i := 0; i < 4; i++ {
+		template := corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "podinfo", Image: fmt.Sprintf("podinfo:%d", i)}},
+			},
+		}
+		require.NoError(t, rh.Record(mocks.canary, template))
+	}
+
+	revisions, err := rh.List(mocks.canary)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+
+	template, err := rh.Get(mocks.canary, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo:2", template.Spec.Containers[0].Image)
+
+	_, err = rh.Get(mocks.canary, 1)
+	require.Error(t, err)
+}
+
+func TestDeploymentController_RevertPrimaryConfigs(t *testing.T) {
+	mocks := newDeploymentFixture()
+	mocks.initializeCanary(t)
+
+	dep2 := newDeploymentControllerTestV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	config2 := newDeploymentControllerTestConfigMapV2()
+	_, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Update(context.TODO(), config2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = mocks.controller.Promote(mocks.canary)
+	require.NoError(t, err)
+
+	configPrimary, err := mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "podinfo-config-env-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, config2.Data["color"], configPrimary.Data["color"])
+
+	// the primary configs are restored with the pod template
+	err = mocks.controller.RevertPrimary(mocks.canary, 0)
+	require.NoError(t, err)
+
+	configPrimary, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "podinfo-config-env-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, newDeploymentControllerTestConfigMap().Data, configPrimary.Data)
+
+	// reverting doesn't change the revision numbers
+	err = mocks.controller.RevertPrimary(mocks.canary, 0)
+	require.NoError(t, err)
+	revisions, err := mocks.controller.revisions().List(mocks.canary)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, int64(2), revisions[0].Revision)
+
+	configPrimary, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "podinfo-config-env-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, newDeploymentControllerTestConfigMap().Data, configPrimary.Data)
+
+	// reverting to the promoted revision restores the new configs
+	err = mocks.controller.RevertPrimary(mocks.canary, 2)
+	require.NoError(t, err)
+
+	configPrimary, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "podinfo-config-env-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, config2.Data, configPrimary.Data)
+}
@@ -37,6 +37,11 @@ func (c *ServiceController) SetStatusIterations(cd *flaggerv1.Canary, val int) e
 	return setStatusIterations(c.flaggerClient, cd, val)
 }
 
+// SetStatusMetrics updates the last measured values of the analysis metrics
+func (c *ServiceController) SetStatusMetrics(cd *flaggerv1.Canary, metrics []flaggerv1.CanaryMetricStatus) error {
+	return setStatusMetrics(c.flaggerClient, cd, metrics)
+}
+
 // SetStatusPhase updates the canary status phase
 func (c *ServiceController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
 	return setStatusPhase(c.flaggerClient, cd, phase)
@@ -183,6 +188,16 @@ func (c *ServiceController) Promote(cd *flaggerv1.Canary) error {
 	return nil
 }
 
+// RevertPrimary is not supported for Service targets
+func (c *ServiceController) RevertPrimary(cd *flaggerv1.Canary, _ int64) error {
+	return fmt.Errorf("reverting the primary of %s.%s is not supported for Service targets", cd.Name, cd.Namespace)
+}
+
+// SyncIgnoredChanges is a no-op for Service targets which have no pod template
+func (c *ServiceController) SyncIgnoredChanges(_ *flaggerv1.Canary) error {
+	return nil
+}
+
 // HasServiceChanged returns true if the canary service spec has changed
 func (c *ServiceController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
 	targetName := cd.Spec.TargetRef.Name
@@ -202,6 +217,10 @@ func (c *ServiceController) ScaleFromZero(_ *flaggerv1.Canary) error {
 	return nil
 }
 
+func (c *ServiceController) ScaleByWeight(_ *flaggerv1.Canary, _ int, _ int) error {
+	return nil
+}
+
 func (c *ServiceController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.CanaryStatus) error {
 	dep, err := c.kubeClient.CoreV1().Services(cd.Namespace).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
 	if err != nil {
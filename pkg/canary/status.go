@@ -124,6 +124,30 @@ func setStatusIterations(flaggerClient clientset.Interface, cd *flaggerv1.Canary
 	return nil
 }
 
+func setStatusMetrics(flaggerClient clientset.Interface, cd *flaggerv1.Canary, metrics []flaggerv1.CanaryMetricStatus) error {
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			cd, err = flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		cdCopy := cd.DeepCopy()
+		cdCopy.Status.Metrics = metrics
+
+		err = updateStatusWithUpgrade(flaggerClient, cdCopy)
+		firstTry = false
+		return
+	})
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	return nil
+}
+
 func setStatusPhase(flaggerClient clientset.Interface, cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
 	firstTry := true
 	name, ns := cd.GetName(), cd.GetNamespace()
@@ -139,11 +163,17 @@ func setStatusPhase(flaggerClient clientset.Interface, cd *flaggerv1.Canary, pha
 		cdCopy.Status.Phase = phase
 		cdCopy.Status.LastTransitionTime = metav1.Now()
 
-		if phase != flaggerv1.CanaryPhaseProgressing && phase != flaggerv1.CanaryPhaseWaiting {
+		if phase != flaggerv1.CanaryPhaseProgressing && phase != flaggerv1.CanaryPhaseWaiting &&
+			phase != flaggerv1.CanaryPhaseShadowing {
 			cdCopy.Status.CanaryWeight = 0
 			cdCopy.Status.Iterations = 0
 		}
 
+		// the bake analysis starts without failed checks
+		if phase == flaggerv1.CanaryPhaseBaking {
+			cdCopy.Status.FailedChecks = 0
+		}
+
 		// on promotion set primary spec hash
 		if phase == flaggerv1.CanaryPhaseInitialized || phase == flaggerv1.CanaryPhaseSucceeded {
 			cdCopy.Status.LastPromotedSpec = cd.Status.LastAppliedSpec
@@ -177,7 +207,10 @@ func getStatusCondition(status flaggerv1.CanaryStatus, conditionType flaggerv1.C
 // MakeStatusCondition updates the canary status conditions based on canary phase
 func MakeStatusConditions(cd *flaggerv1.Canary,
 	phase flaggerv1.CanaryPhase) (bool, []flaggerv1.CanaryCondition) {
-	currentCondition := getStatusCondition(cd.Status, flaggerv1.PromotedType)
+	switch phase {
+	case flaggerv1.CanaryPhaseShadowing, flaggerv1.CanaryPhaseShadowSucceeded, flaggerv1.CanaryPhaseShadowFailed:
+		return makeShadowCondition(cd, phase)
+	}
 
 	message := fmt.Sprintf("New %s detected, starting initialization.", cd.Spec.TargetRef.Kind)
 	status := corev1.ConditionUnknown
@@ -200,6 +233,9 @@ func MakeStatusConditions(cd *flaggerv1.Canary,
 	case flaggerv1.CanaryPhaseFinalising:
 		status = corev1.ConditionUnknown
 		message = "Canary analysis completed, routing all traffic to primary."
+	case flaggerv1.CanaryPhaseBaking:
+		status = corev1.ConditionUnknown
+		message = "Canary promotion finished, baking primary."
 	case flaggerv1.CanaryPhaseSucceeded:
 		status = corev1.ConditionTrue
 		message = "Canary analysis completed successfully, promotion finished."
@@ -217,6 +253,40 @@ func MakeStatusConditions(cd *flaggerv1.Canary,
 		Reason:             string(phase),
 	}
 
+	return setStatusCondition(cd.Status, newCondition)
+}
+
+// makeShadowCondition updates the shadow analysis condition based on the shadow phase,
+// the promoted condition is left untouched as the canary is never promoted
+func makeShadowCondition(cd *flaggerv1.Canary,
+	phase flaggerv1.CanaryPhase) (bool, []flaggerv1.CanaryCondition) {
+	status := corev1.ConditionUnknown
+	message := "New revision detected, starting shadow analysis."
+	switch phase {
+	case flaggerv1.CanaryPhaseShadowSucceeded:
+		status = corev1.ConditionTrue
+		message = "Shadow analysis completed successfully, canary not promoted."
+	case flaggerv1.CanaryPhaseShadowFailed:
+		status = corev1.ConditionFalse
+		message = fmt.Sprintf("Shadow analysis failed, %s scaled to zero.", cd.Spec.TargetRef.Kind)
+	}
+
+	newCondition := &flaggerv1.CanaryCondition{
+		Type:               flaggerv1.ShadowAnalysisType,
+		Status:             status,
+		LastUpdateTime:     metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+		Message:            message,
+		Reason:             string(phase),
+	}
+
+	return setStatusCondition(cd.Status, newCondition)
+}
+
+// setStatusCondition replaces the condition of the same type and returns false if the condition hasn't changed
+func setStatusCondition(canaryStatus flaggerv1.CanaryStatus,
+	newCondition *flaggerv1.CanaryCondition) (bool, []flaggerv1.CanaryCondition) {
+	currentCondition := getStatusCondition(canaryStatus, newCondition.Type)
 	if currentCondition != nil &&
 		currentCondition.Status == newCondition.Status &&
 		currentCondition.Reason == newCondition.Reason {
@@ -227,7 +297,17 @@ func MakeStatusConditions(cd *flaggerv1.Canary,
 		newCondition.LastTransitionTime = currentCondition.LastTransitionTime
 	}
 
-	return true, []flaggerv1.CanaryCondition{*newCondition}
+	conditions := make([]flaggerv1.CanaryCondition, 0, len(canaryStatus.Conditions)+1)
+	for _, c := range canaryStatus.Conditions {
+		if c.Type == newCondition.Type {
+			c = *newCondition
+		}
+		conditions = append(conditions, c)
+	}
+	if currentCondition == nil {
+		conditions = append(conditions, *newCondition)
+	}
+	return true, conditions
 }
 
 // updateStatusWithUpgrade tries to update the status sub-resource
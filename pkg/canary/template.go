@@ -0,0 +1,117 @@
+package canary
+
+import (
+	"reflect"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// MergeTemplate returns a copy of the canary with the template merged into its spec,
+// the fields set in the canary take precedence over the template ones and
+// the analysis metrics, webhooks and alerts are merged by name
+func MergeTemplate(cd *flaggerv1.Canary, template flaggerv1.CanaryTemplateSpec) *flaggerv1.Canary {
+	res := cd.DeepCopy()
+
+	if res.Spec.Provider == "" {
+		res.Spec.Provider = template.Provider
+	}
+	if res.Spec.MetricsServer == "" {
+		res.Spec.MetricsServer = template.MetricsServer
+	}
+	if res.Spec.ProgressDeadlineSeconds == nil && template.ProgressDeadlineSeconds != nil {
+		deadline := *template.ProgressDeadlineSeconds
+		res.Spec.ProgressDeadlineSeconds = &deadline
+	}
+
+	if template.Analysis == nil {
+		return res
+	}
+
+	analysis := template.Analysis.DeepCopy()
+	if current := res.GetAnalysis(); current != nil {
+		analysis = mergeAnalysis(*template.Analysis.DeepCopy(), *current)
+	}
+	res.Spec.Analysis = analysis
+	res.Spec.CanaryAnalysis = nil
+
+	return res
+}
+
+// mergeAnalysis sets the zero value fields of the canary analysis to the template values,
+// a field can't be reset to its zero value by the canary, e.g. mirroring enabled
+// in the template can't be disabled with mirror set to false
+func mergeAnalysis(template flaggerv1.CanaryAnalysis, analysis flaggerv1.CanaryAnalysis) *flaggerv1.CanaryAnalysis {
+	res := analysis.DeepCopy()
+	dst := reflect.ValueOf(res).Elem()
+	src := reflect.ValueOf(template)
+	for i := 0; i < dst.NumField(); i++ {
+		if dst.Field(i).IsZero() {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+
+	res.Metrics = mergeMetrics(template.Metrics, analysis.Metrics)
+	res.Webhooks = mergeWebhooks(template.Webhooks, analysis.Webhooks)
+	res.Alerts = mergeAlerts(template.Alerts, analysis.Alerts)
+	return res
+}
+
+// mergeMetrics replaces the template metrics with the canary ones of the same name
+func mergeMetrics(template []flaggerv1.CanaryMetric, metrics []flaggerv1.CanaryMetric) []flaggerv1.CanaryMetric {
+	var res []flaggerv1.CanaryMetric
+	for _, metric := range template {
+		if !containsMetric(metrics, metric.Name) {
+			res = append(res, metric)
+		}
+	}
+	return append(res, metrics...)
+}
+
+// mergeWebhooks replaces the template webhooks with the canary ones of the same name
+func mergeWebhooks(template []flaggerv1.CanaryWebhook, webhooks []flaggerv1.CanaryWebhook) []flaggerv1.CanaryWebhook {
+	var res []flaggerv1.CanaryWebhook
+	for _, webhook := range template {
+		if !containsWebhook(webhooks, webhook.Name) {
+			res = append(res, webhook)
+		}
+	}
+	return append(res, webhooks...)
+}
+
+// mergeAlerts replaces the template alerts with the canary ones of the same name
+func mergeAlerts(template []flaggerv1.CanaryAlert, alerts []flaggerv1.CanaryAlert) []flaggerv1.CanaryAlert {
+	var res []flaggerv1.CanaryAlert
+	for _, alert := range template {
+		if !containsAlert(alerts, alert.Name) {
+			res = append(res, alert)
+		}
+	}
+	return append(res, alerts...)
+}
+
+func containsMetric(metrics []flaggerv1.CanaryMetric, name string) bool {
+	for _, metric := range metrics {
+		if metric.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func containsWebhook(webhooks []flaggerv1.CanaryWebhook, name string) bool {
+	for _, webhook := range webhooks {
+		if webhook.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAlert(alerts []flaggerv1.CanaryAlert, name string) bool {
+	for _, alert := range alerts {
+		if alert.Name == name {
+			return true
+		}
+	}
+	return false
+}
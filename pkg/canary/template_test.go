@@ -0,0 +1,92 @@
+package canary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestMergeTemplate(t *testing.T) {
+	deadline := int32(120)
+	template := flaggerv1.CanaryTemplateSpec{
+		Provider:                "istio",
+		ProgressDeadlineSeconds: &deadline,
+		Analysis: &flaggerv1.CanaryAnalysis{
+			Interval:   "1m",
+			Threshold:  5,
+			StepWeight: 10,
+			MaxWeight:  50,
+			Metrics: []flaggerv1.CanaryMetric{
+				{Name: "request-success-rate", Threshold: 99},
+				{Name: "request-duration", Threshold: 500},
+			},
+			Webhooks: []flaggerv1.CanaryWebhook{
+				{Name: "load-test", URL: "http://flagger-loadtester.test/"},
+			},
+		},
+	}
+
+	t.Run("without analysis", func(t *testing.T) {
+		cd := &flaggerv1.Canary{}
+		res := MergeTemplate(cd, template)
+
+		assert.Equal(t, "istio", res.Spec.Provider)
+		assert.Equal(t, int32(120), *res.Spec.ProgressDeadlineSeconds)
+		require.NotNil(t, res.GetAnalysis())
+		assert.Equal(t, "1m", res.GetAnalysis().Interval)
+		assert.Len(t, res.GetAnalysis().Metrics, 2)
+		assert.Nil(t, cd.Spec.Analysis)
+	})
+
+	t.Run("with overrides", func(t *testing.T) {
+		cd := &flaggerv1.Canary{
+			Spec: flaggerv1.CanarySpec{
+				Provider: "linkerd",
+				Analysis: &flaggerv1.CanaryAnalysis{
+					StepWeight: 20,
+					Metrics: []flaggerv1.CanaryMetric{
+						{Name: "request-duration", Threshold: 1000},
+						{Name: "error-rate", Threshold: 1},
+					},
+				},
+			},
+		}
+		res := MergeTemplate(cd, template)
+
+		assert.Equal(t, "linkerd", res.Spec.Provider)
+		analysis := res.GetAnalysis()
+		assert.Equal(t, "1m", analysis.Interval)
+		assert.Equal(t, 5, analysis.Threshold)
+		assert.Equal(t, 20, analysis.StepWeight)
+		assert.Equal(t, 50, analysis.MaxWeight)
+		assert.Len(t, analysis.Webhooks, 1)
+
+		require.Len(t, analysis.Metrics, 3)
+		assert.Equal(t, "request-success-rate", analysis.Metrics[0].Name)
+		assert.Equal(t, "request-duration", analysis.Metrics[1].Name)
+		assert.Equal(t, float64(1000), analysis.Metrics[1].Threshold)
+		assert.Equal(t, "error-rate", analysis.Metrics[2].Name)
+
+		assert.Len(t, template.Analysis.Metrics, 2)
+		assert.Len(t, cd.Spec.Analysis.Metrics, 2)
+	})
+
+	t.Run("with boolean fields", func(t *testing.T) {
+		// the canary can enable a boolean field
+		cd := &flaggerv1.Canary{
+			Spec: flaggerv1.CanarySpec{
+				Analysis: &flaggerv1.CanaryAnalysis{Mirror: true},
+			},
+		}
+		assert.True(t, MergeTemplate(cd, template).GetAnalysis().Mirror)
+
+		// but can't disable a boolean field enabled in the template
+		mirror := *template.DeepCopy()
+		mirror.Analysis.Mirror = true
+		cd.Spec.Analysis.Mirror = false
+		assert.True(t, MergeTemplate(cd, mirror).GetAnalysis().Mirror)
+	})
+}
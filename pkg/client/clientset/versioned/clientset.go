@@ -22,9 +22,13 @@ import (
 	"fmt"
 
 	appmeshv1beta1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta1"
+	flaggerv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/flagger/v1"
 	flaggerv1beta1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/flagger/v1beta1"
 	gloov1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/gloo/v1"
 	networkingv1alpha3 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/istio/v1alpha3"
+	kedav1alpha1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/keda/v1alpha1"
+	servingv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/knative/v1"
+	monitoringv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/monitoring/v1"
 	projectcontourv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/projectcontour/v1"
 	splitv1alpha1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha1"
 	splitv1alpha2 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha2"
@@ -37,8 +41,12 @@ type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	AppmeshV1beta1() appmeshv1beta1.AppmeshV1beta1Interface
 	FlaggerV1beta1() flaggerv1beta1.FlaggerV1beta1Interface
+	FlaggerV1() flaggerv1.FlaggerV1Interface
 	GlooV1() gloov1.GlooV1Interface
 	NetworkingV1alpha3() networkingv1alpha3.NetworkingV1alpha3Interface
+	KedaV1alpha1() kedav1alpha1.KedaV1alpha1Interface
+	ServingV1() servingv1.ServingV1Interface
+	MonitoringV1() monitoringv1.MonitoringV1Interface
 	ProjectcontourV1() projectcontourv1.ProjectcontourV1Interface
 	SplitV1alpha1() splitv1alpha1.SplitV1alpha1Interface
 	SplitV1alpha2() splitv1alpha2.SplitV1alpha2Interface
@@ -50,8 +58,12 @@ type Clientset struct {
 	*discovery.DiscoveryClient
 	appmeshV1beta1     *appmeshv1beta1.AppmeshV1beta1Client
 	flaggerV1beta1     *flaggerv1beta1.FlaggerV1beta1Client
+	flaggerV1          *flaggerv1.FlaggerV1Client
 	glooV1             *gloov1.GlooV1Client
 	networkingV1alpha3 *networkingv1alpha3.NetworkingV1alpha3Client
+	kedaV1alpha1       *kedav1alpha1.KedaV1alpha1Client
+	servingV1          *servingv1.ServingV1Client
+	monitoringV1       *monitoringv1.MonitoringV1Client
 	projectcontourV1   *projectcontourv1.ProjectcontourV1Client
 	splitV1alpha1      *splitv1alpha1.SplitV1alpha1Client
 	splitV1alpha2      *splitv1alpha2.SplitV1alpha2Client
@@ -67,6 +79,11 @@ func (c *Clientset) FlaggerV1beta1() flaggerv1beta1.FlaggerV1beta1Interface {
 	return c.flaggerV1beta1
 }
 
+// FlaggerV1 retrieves the FlaggerV1Client
+func (c *Clientset) FlaggerV1() flaggerv1.FlaggerV1Interface {
+	return c.flaggerV1
+}
+
 // GlooV1 retrieves the GlooV1Client
 func (c *Clientset) GlooV1() gloov1.GlooV1Interface {
 	return c.glooV1
@@ -77,6 +94,21 @@ func (c *Clientset) NetworkingV1alpha3() networkingv1alpha3.NetworkingV1alpha3In
 	return c.networkingV1alpha3
 }
 
+// KedaV1alpha1 retrieves the KedaV1alpha1Client
+func (c *Clientset) KedaV1alpha1() kedav1alpha1.KedaV1alpha1Interface {
+	return c.kedaV1alpha1
+}
+
+// ServingV1 retrieves the ServingV1Client
+func (c *Clientset) ServingV1() servingv1.ServingV1Interface {
+	return c.servingV1
+}
+
+// MonitoringV1 retrieves the MonitoringV1Client
+func (c *Clientset) MonitoringV1() monitoringv1.MonitoringV1Interface {
+	return c.monitoringV1
+}
+
 // ProjectcontourV1 retrieves the ProjectcontourV1Client
 func (c *Clientset) ProjectcontourV1() projectcontourv1.ProjectcontourV1Interface {
 	return c.projectcontourV1
@@ -121,6 +153,10 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.flaggerV1, err = flaggerv1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 	cs.glooV1, err = gloov1.NewForConfig(&configShallowCopy)
 	if err != nil {
 		return nil, err
@@ -129,6 +165,18 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.kedaV1alpha1, err = kedav1alpha1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
+	cs.servingV1, err = servingv1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
+	cs.monitoringV1, err = monitoringv1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 	cs.projectcontourV1, err = projectcontourv1.NewForConfig(&configShallowCopy)
 	if err != nil {
 		return nil, err
@@ -155,8 +203,12 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
 	cs.appmeshV1beta1 = appmeshv1beta1.NewForConfigOrDie(c)
 	cs.flaggerV1beta1 = flaggerv1beta1.NewForConfigOrDie(c)
+	cs.flaggerV1 = flaggerv1.NewForConfigOrDie(c)
 	cs.glooV1 = gloov1.NewForConfigOrDie(c)
 	cs.networkingV1alpha3 = networkingv1alpha3.NewForConfigOrDie(c)
+	cs.kedaV1alpha1 = kedav1alpha1.NewForConfigOrDie(c)
+	cs.servingV1 = servingv1.NewForConfigOrDie(c)
+	cs.monitoringV1 = monitoringv1.NewForConfigOrDie(c)
 	cs.projectcontourV1 = projectcontourv1.NewForConfigOrDie(c)
 	cs.splitV1alpha1 = splitv1alpha1.NewForConfigOrDie(c)
 	cs.splitV1alpha2 = splitv1alpha2.NewForConfigOrDie(c)
@@ -170,8 +222,12 @@ func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.appmeshV1beta1 = appmeshv1beta1.New(c)
 	cs.flaggerV1beta1 = flaggerv1beta1.New(c)
+	cs.flaggerV1 = flaggerv1.New(c)
 	cs.glooV1 = gloov1.New(c)
 	cs.networkingV1alpha3 = networkingv1alpha3.New(c)
+	cs.kedaV1alpha1 = kedav1alpha1.New(c)
+	cs.servingV1 = servingv1.New(c)
+	cs.monitoringV1 = monitoringv1.New(c)
 	cs.projectcontourV1 = projectcontourv1.New(c)
 	cs.splitV1alpha1 = splitv1alpha1.New(c)
 	cs.splitV1alpha2 = splitv1alpha2.New(c)
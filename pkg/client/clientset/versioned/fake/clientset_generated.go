@@ -22,12 +22,20 @@ import (
 	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
 	appmeshv1beta1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta1"
 	fakeappmeshv1beta1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta1/fake"
+	flaggerv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/flagger/v1"
+	fakeflaggerv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/flagger/v1/fake"
 	flaggerv1beta1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/flagger/v1beta1"
 	fakeflaggerv1beta1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/flagger/v1beta1/fake"
 	gloov1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/gloo/v1"
 	fakegloov1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/gloo/v1/fake"
 	networkingv1alpha3 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/istio/v1alpha3"
 	fakenetworkingv1alpha3 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/istio/v1alpha3/fake"
+	kedav1alpha1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/keda/v1alpha1"
+	fakekedav1alpha1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/keda/v1alpha1/fake"
+	servingv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/knative/v1"
+	fakeservingv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/knative/v1/fake"
+	monitoringv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/monitoring/v1"
+	fakemonitoringv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/monitoring/v1/fake"
 	projectcontourv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/projectcontour/v1"
 	fakeprojectcontourv1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/projectcontour/v1/fake"
 	splitv1alpha1 "github.com/weaveworks/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha1"
@@ -98,6 +106,11 @@ func (c *Clientset) FlaggerV1beta1() flaggerv1beta1.FlaggerV1beta1Interface {
 	return &fakeflaggerv1beta1.FakeFlaggerV1beta1{Fake: &c.Fake}
 }
 
+// FlaggerV1 retrieves the FlaggerV1Client
+func (c *Clientset) FlaggerV1() flaggerv1.FlaggerV1Interface {
+	return &fakeflaggerv1.FakeFlaggerV1{Fake: &c.Fake}
+}
+
 // GlooV1 retrieves the GlooV1Client
 func (c *Clientset) GlooV1() gloov1.GlooV1Interface {
 	return &fakegloov1.FakeGlooV1{Fake: &c.Fake}
@@ -108,6 +121,21 @@ func (c *Clientset) NetworkingV1alpha3() networkingv1alpha3.NetworkingV1alpha3In
 	return &fakenetworkingv1alpha3.FakeNetworkingV1alpha3{Fake: &c.Fake}
 }
 
+// KedaV1alpha1 retrieves the KedaV1alpha1Client
+func (c *Clientset) KedaV1alpha1() kedav1alpha1.KedaV1alpha1Interface {
+	return &fakekedav1alpha1.FakeKedaV1alpha1{Fake: &c.Fake}
+}
+
+// ServingV1 retrieves the ServingV1Client
+func (c *Clientset) ServingV1() servingv1.ServingV1Interface {
+	return &fakeservingv1.FakeServingV1{Fake: &c.Fake}
+}
+
+// MonitoringV1 retrieves the MonitoringV1Client
+func (c *Clientset) MonitoringV1() monitoringv1.MonitoringV1Interface {
+	return &fakemonitoringv1.FakeMonitoringV1{Fake: &c.Fake}
+}
+
 // ProjectcontourV1 retrieves the ProjectcontourV1Client
 func (c *Clientset) ProjectcontourV1() projectcontourv1.ProjectcontourV1Interface {
 	return &fakeprojectcontourv1.FakeProjectcontourV1{Fake: &c.Fake}
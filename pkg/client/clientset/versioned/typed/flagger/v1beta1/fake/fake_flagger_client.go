@@ -36,6 +36,18 @@ func (c *FakeFlaggerV1beta1) Canaries(namespace string) v1beta1.CanaryInterface
 	return &FakeCanaries{c, namespace}
 }
 
+func (c *FakeFlaggerV1beta1) CanaryFleets(namespace string) v1beta1.CanaryFleetInterface {
+	return &FakeCanaryFleets{c, namespace}
+}
+
+func (c *FakeFlaggerV1beta1) CanaryTemplates(namespace string) v1beta1.CanaryTemplateInterface {
+	return &FakeCanaryTemplates{c, namespace}
+}
+
+func (c *FakeFlaggerV1beta1) ClusterCanaryTemplates() v1beta1.ClusterCanaryTemplateInterface {
+	return &FakeClusterCanaryTemplates{c}
+}
+
 func (c *FakeFlaggerV1beta1) MetricTemplates(namespace string) v1beta1.MetricTemplateInterface {
 	return &FakeMetricTemplates{c, namespace}
 }
@@ -28,6 +28,9 @@ type FlaggerV1beta1Interface interface {
 	RESTClient() rest.Interface
 	AlertProvidersGetter
 	CanariesGetter
+	CanaryFleetsGetter
+	CanaryTemplatesGetter
+	ClusterCanaryTemplatesGetter
 	MetricTemplatesGetter
 }
 
@@ -44,6 +47,18 @@ func (c *FlaggerV1beta1Client) Canaries(namespace string) CanaryInterface {
 	return newCanaries(c, namespace)
 }
 
+func (c *FlaggerV1beta1Client) CanaryFleets(namespace string) CanaryFleetInterface {
+	return newCanaryFleets(c, namespace)
+}
+
+func (c *FlaggerV1beta1Client) CanaryTemplates(namespace string) CanaryTemplateInterface {
+	return newCanaryTemplates(c, namespace)
+}
+
+func (c *FlaggerV1beta1Client) ClusterCanaryTemplates() ClusterCanaryTemplateInterface {
+	return newClusterCanaryTemplates(c)
+}
+
 func (c *FlaggerV1beta1Client) MetricTemplates(namespace string) MetricTemplateInterface {
 	return newMetricTemplates(c, namespace)
 }
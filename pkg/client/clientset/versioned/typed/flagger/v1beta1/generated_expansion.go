@@ -22,4 +22,10 @@ type AlertProviderExpansion interface{}
 
 type CanaryExpansion interface{}
 
+type CanaryFleetExpansion interface{}
+
+type CanaryTemplateExpansion interface{}
+
+type ClusterCanaryTemplateExpansion interface{}
+
 type MetricTemplateExpansion interface{}
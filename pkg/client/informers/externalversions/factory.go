@@ -29,6 +29,9 @@ import (
 	gloo "github.com/weaveworks/flagger/pkg/client/informers/externalversions/gloo"
 	internalinterfaces "github.com/weaveworks/flagger/pkg/client/informers/externalversions/internalinterfaces"
 	istio "github.com/weaveworks/flagger/pkg/client/informers/externalversions/istio"
+	keda "github.com/weaveworks/flagger/pkg/client/informers/externalversions/keda"
+	knative "github.com/weaveworks/flagger/pkg/client/informers/externalversions/knative"
+	monitoring "github.com/weaveworks/flagger/pkg/client/informers/externalversions/monitoring"
 	projectcontour "github.com/weaveworks/flagger/pkg/client/informers/externalversions/projectcontour"
 	smi "github.com/weaveworks/flagger/pkg/client/informers/externalversions/smi"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -181,6 +184,9 @@ type SharedInformerFactory interface {
 	Flagger() flagger.Interface
 	Gloo() gloo.Interface
 	Networking() istio.Interface
+	Keda() keda.Interface
+	Serving() knative.Interface
+	Monitoring() monitoring.Interface
 	Projectcontour() projectcontour.Interface
 	Split() smi.Interface
 }
@@ -201,6 +207,18 @@ func (f *sharedInformerFactory) Networking() istio.Interface {
 	return istio.New(f, f.namespace, f.tweakListOptions)
 }
 
+func (f *sharedInformerFactory) Keda() keda.Interface {
+	return keda.New(f, f.namespace, f.tweakListOptions)
+}
+
+func (f *sharedInformerFactory) Serving() knative.Interface {
+	return knative.New(f, f.namespace, f.tweakListOptions)
+}
+
+func (f *sharedInformerFactory) Monitoring() monitoring.Interface {
+	return monitoring.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Projectcontour() projectcontour.Interface {
 	return projectcontour.New(f, f.namespace, f.tweakListOptions)
 }
@@ -28,6 +28,12 @@ type Interface interface {
 	AlertProviders() AlertProviderInformer
 	// Canaries returns a CanaryInformer.
 	Canaries() CanaryInformer
+	// CanaryFleets returns a CanaryFleetInformer.
+	CanaryFleets() CanaryFleetInformer
+	// CanaryTemplates returns a CanaryTemplateInformer.
+	CanaryTemplates() CanaryTemplateInformer
+	// ClusterCanaryTemplates returns a ClusterCanaryTemplateInformer.
+	ClusterCanaryTemplates() ClusterCanaryTemplateInformer
 	// MetricTemplates returns a MetricTemplateInformer.
 	MetricTemplates() MetricTemplateInformer
 }
@@ -53,6 +59,21 @@ func (v *version) Canaries() CanaryInformer {
 	return &canaryInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// CanaryFleets returns a CanaryFleetInformer.
+func (v *version) CanaryFleets() CanaryFleetInformer {
+	return &canaryFleetInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// CanaryTemplates returns a CanaryTemplateInformer.
+func (v *version) CanaryTemplates() CanaryTemplateInformer {
+	return &canaryTemplateInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// ClusterCanaryTemplates returns a ClusterCanaryTemplateInformer.
+func (v *version) ClusterCanaryTemplates() ClusterCanaryTemplateInformer {
+	return &clusterCanaryTemplateInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // MetricTemplates returns a MetricTemplateInformer.
 func (v *version) MetricTemplates() MetricTemplateInformer {
 	return &metricTemplateInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
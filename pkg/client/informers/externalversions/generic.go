@@ -22,11 +22,15 @@ import (
 	"fmt"
 
 	v1beta1 "github.com/weaveworks/flagger/pkg/apis/appmesh/v1beta1"
+	v1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1"
 	flaggerv1beta1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
-	v1 "github.com/weaveworks/flagger/pkg/apis/gloo/v1"
+	gloov1 "github.com/weaveworks/flagger/pkg/apis/gloo/v1"
 	v1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
+	v1alpha1 "github.com/weaveworks/flagger/pkg/apis/keda/v1alpha1"
+	knativev1 "github.com/weaveworks/flagger/pkg/apis/knative/v1"
+	monitoringv1 "github.com/weaveworks/flagger/pkg/apis/monitoring/v1"
 	projectcontourv1 "github.com/weaveworks/flagger/pkg/apis/projectcontour/v1"
-	v1alpha1 "github.com/weaveworks/flagger/pkg/apis/smi/v1alpha1"
+	smiv1alpha1 "github.com/weaveworks/flagger/pkg/apis/smi/v1alpha1"
 	v1alpha2 "github.com/weaveworks/flagger/pkg/apis/smi/v1alpha2"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
@@ -66,18 +70,36 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case v1beta1.SchemeGroupVersion.WithResource("virtualservices"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Appmesh().V1beta1().VirtualServices().Informer()}, nil
 
+		// Group=flagger.app, Version=v1
+	case v1.SchemeGroupVersion.WithResource("canaries"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1().Canaries().Informer()}, nil
+
 		// Group=flagger.app, Version=v1beta1
 	case flaggerv1beta1.SchemeGroupVersion.WithResource("alertproviders"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().AlertProviders().Informer()}, nil
 	case flaggerv1beta1.SchemeGroupVersion.WithResource("canaries"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().Canaries().Informer()}, nil
+	case flaggerv1beta1.SchemeGroupVersion.WithResource("canaryfleets"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().CanaryFleets().Informer()}, nil
+	case flaggerv1beta1.SchemeGroupVersion.WithResource("canarytemplates"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().CanaryTemplates().Informer()}, nil
+	case flaggerv1beta1.SchemeGroupVersion.WithResource("clustercanarytemplates"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().ClusterCanaryTemplates().Informer()}, nil
 	case flaggerv1beta1.SchemeGroupVersion.WithResource("metrictemplates"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().MetricTemplates().Informer()}, nil
 
 		// Group=gloo.solo.io, Version=v1
-	case v1.SchemeGroupVersion.WithResource("upstreamgroups"):
+	case gloov1.SchemeGroupVersion.WithResource("upstreamgroups"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Gloo().V1().UpstreamGroups().Informer()}, nil
 
+		// Group=keda.sh, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("scaledobjects"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Keda().V1alpha1().ScaledObjects().Informer()}, nil
+
+		// Group=monitoring.coreos.com, Version=v1
+	case monitoringv1.SchemeGroupVersion.WithResource("servicemonitors"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Monitoring().V1().ServiceMonitors().Informer()}, nil
+
 		// Group=networking.istio.io, Version=v1alpha3
 	case v1alpha3.SchemeGroupVersion.WithResource("destinationrules"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1alpha3().DestinationRules().Informer()}, nil
@@ -88,8 +110,14 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case projectcontourv1.SchemeGroupVersion.WithResource("httpproxies"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Projectcontour().V1().HTTPProxies().Informer()}, nil
 
+		// Group=serving.knative.dev, Version=v1
+	case knativev1.SchemeGroupVersion.WithResource("revisions"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Serving().V1().Revisions().Informer()}, nil
+	case knativev1.SchemeGroupVersion.WithResource("services"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Serving().V1().Services().Informer()}, nil
+
 		// Group=split.smi-spec.io, Version=v1alpha1
-	case v1alpha1.SchemeGroupVersion.WithResource("trafficsplits"):
+	case smiv1alpha1.SchemeGroupVersion.WithResource("trafficsplits"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Split().V1alpha1().TrafficSplits().Informer()}, nil
 
 		// Group=split.smi-spec.io, Version=v1alpha2
@@ -34,6 +34,26 @@ type CanaryListerExpansion interface{}
 // CanaryNamespaceLister.
 type CanaryNamespaceListerExpansion interface{}
 
+// CanaryFleetListerExpansion allows custom methods to be added to
+// CanaryFleetLister.
+type CanaryFleetListerExpansion interface{}
+
+// CanaryFleetNamespaceListerExpansion allows custom methods to be added to
+// CanaryFleetNamespaceLister.
+type CanaryFleetNamespaceListerExpansion interface{}
+
+// CanaryTemplateListerExpansion allows custom methods to be added to
+// CanaryTemplateLister.
+type CanaryTemplateListerExpansion interface{}
+
+// CanaryTemplateNamespaceListerExpansion allows custom methods to be added to
+// CanaryTemplateNamespaceLister.
+type CanaryTemplateNamespaceListerExpansion interface{}
+
+// ClusterCanaryTemplateListerExpansion allows custom methods to be added to
+// ClusterCanaryTemplateLister.
+type ClusterCanaryTemplateListerExpansion interface{}
+
 // MetricTemplateListerExpansion allows custom methods to be added to
 // MetricTemplateLister.
 type MetricTemplateListerExpansion interface{}
@@ -0,0 +1,265 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+	flaggerlisters "github.com/weaveworks/flagger/pkg/client/listers/flagger/v1beta1"
+)
+
+// AutoCanaryController generates canaries for the Deployments and DaemonSets labeled with
+// flagger.app/auto-canary, the generated canaries are owned by the workloads and
+// are deleted when the label is removed, after Flagger reverted the workloads
+type AutoCanaryController struct {
+	kubeClient       kubernetes.Interface
+	flaggerClient    clientset.Interface
+	deploymentLister appslisters.DeploymentLister
+	daemonSetLister  appslisters.DaemonSetLister
+	canaryLister     flaggerlisters.CanaryLister
+	synced           []cache.InformerSynced
+	workqueue        workqueue.RateLimitingInterface
+	logger           *zap.SugaredLogger
+	defaultTemplate  string
+}
+
+func NewAutoCanaryController(
+	kubeClient kubernetes.Interface,
+	flaggerClient clientset.Interface,
+	kubeInformerFactory kubeinformers.SharedInformerFactory,
+	flaggerInformers Informers,
+	defaultTemplate string,
+	logger *zap.SugaredLogger,
+) *AutoCanaryController {
+	deploymentInformer := kubeInformerFactory.Apps().V1().Deployments()
+	daemonSetInformer := kubeInformerFactory.Apps().V1().DaemonSets()
+
+	ctrl := &AutoCanaryController{
+		kubeClient:       kubeClient,
+		flaggerClient:    flaggerClient,
+		deploymentLister: deploymentInformer.Lister(),
+		daemonSetLister:  daemonSetInformer.Lister(),
+		canaryLister:     flaggerInformers.CanaryInformer.Lister(),
+		synced: []cache.InformerSynced{
+			deploymentInformer.Informer().HasSynced,
+			daemonSetInformer.Informer().HasSynced,
+			flaggerInformers.CanaryInformer.Informer().HasSynced,
+		},
+		workqueue:       workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "auto-canary"),
+		logger:          logger,
+		defaultTemplate: defaultTemplate,
+	}
+
+	deploymentInformer.Informer().AddEventHandler(ctrl.eventHandler("Deployment"))
+	daemonSetInformer.Informer().AddEventHandler(ctrl.eventHandler("DaemonSet"))
+
+	return ctrl
+}
+
+// Run waits for the informers to sync and processes the workloads until the stop channel is closed
+func (c *AutoCanaryController) Run(stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	if ok := cache.WaitForNamedCacheSync("flagger-auto-canary", stopCh, c.synced...); !ok {
+		c.logger.Errorf("Auto canary failed to wait for caches to sync")
+		return
+	}
+
+	c.logger.Info("Started auto canary worker")
+	go wait.Until(func() {
+		for c.processNextWorkItem() {
+		}
+	}, time.Second, stopCh)
+
+	<-stopCh
+}
+
+// eventHandler enqueues the workloads in the format <kind>/<namespace>/<name>
+func (c *AutoCanaryController) eventHandler(kind string) cache.ResourceEventHandlerFuncs {
+	enqueue := func(obj interface{}) {
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil {
+			utilruntime.HandleError(err)
+			return
+		}
+		c.workqueue.AddRateLimited(fmt.Sprintf("%s/%s", kind, key))
+	}
+
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: enqueue,
+		UpdateFunc: func(old, new interface{}) {
+			oldMeta, ok := old.(metav1.Object)
+			if !ok {
+				return
+			}
+			newMeta, ok := new.(metav1.Object)
+			if !ok {
+				return
+			}
+			if oldMeta.GetGeneration() != newMeta.GetGeneration() ||
+				!equality.Semantic.DeepEqual(oldMeta.GetLabels(), newMeta.GetLabels()) ||
+				!equality.Semantic.DeepEqual(oldMeta.GetAnnotations(), newMeta.GetAnnotations()) {
+				enqueue(new)
+			}
+		},
+	}
+}
+
+func (c *AutoCanaryController) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.workqueue.Done(obj)
+
+	key, ok := obj.(string)
+	if !ok {
+		c.workqueue.Forget(obj)
+		utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+		return true
+	}
+
+	if err := c.syncHandler(key); err != nil {
+		utilruntime.HandleError(fmt.Errorf("error syncing '%s': %w", key, err))
+		c.workqueue.AddRateLimited(key)
+		return true
+	}
+	c.workqueue.Forget(obj)
+	return true
+}
+
+func (c *AutoCanaryController) syncHandler(key string) error {
+	parts := strings.SplitN(key, "/", 2)
+	if len(parts) != 2 {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+	kind := parts[0]
+	namespace, name, err := cache.SplitMetaNamespaceKey(parts[1])
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+
+	var workload metav1.Object
+	var podTemplate corev1.PodTemplateSpec
+	switch kind {
+	case "Deployment":
+		dep, err := c.deploymentLister.Deployments(namespace).Get(name)
+		if errors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("deployment %s.%s get query error: %w", name, namespace, err)
+		}
+		workload, podTemplate = dep, dep.Spec.Template
+	case "DaemonSet":
+		daemonSet, err := c.daemonSetLister.DaemonSets(namespace).Get(name)
+		if errors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("daemonset %s.%s get query error: %w", name, namespace, err)
+		}
+		workload, podTemplate = daemonSet, daemonSet.Spec.Template
+	default:
+		utilruntime.HandleError(fmt.Errorf("invalid resource kind: %s", key))
+		return nil
+	}
+
+	existing, err := c.canaryLister.Canaries(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		existing = nil
+	} else if err != nil {
+		return fmt.Errorf("canary %s.%s get query error: %w", name, namespace, err)
+	}
+
+	// remove the generated canary when the workload is no longer labeled
+	if !canary.IsAutoCanaryEnabled(workload.GetLabels()) {
+		if existing == nil || !metav1.IsControlledBy(existing, workload) {
+			return nil
+		}
+		err := c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("canary %s.%s delete error: %w", name, namespace, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).
+			Infof("Generated canary deleted, %s label removed from %s %s.%s", flaggerv1.AutoCanaryLabel, kind, name, namespace)
+		return nil
+	}
+
+	cd, err := canary.NewAutoCanary(workload, appsv1.SchemeGroupVersion.WithKind(kind), podTemplate, c.defaultTemplate)
+	if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).
+			Errorf("Generating canary for %s %s.%s failed: %v", kind, name, namespace, err)
+		return nil
+	}
+
+	if existing == nil {
+		_, err := c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Create(context.TODO(), cd, metav1.CreateOptions{})
+		if err == nil {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).
+				Infof("Canary generated for %s %s.%s", kind, name, namespace)
+			return nil
+		}
+		if !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("canary %s.%s create error: %w", name, namespace, err)
+		}
+
+		// the canary of a workload moved from another shard is not in the shard cache
+		existing, err = c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("canary %s.%s get query error: %w", name, namespace, err)
+		}
+	}
+
+	if !metav1.IsControlledBy(existing, workload) {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).
+			Warnf("Canary %s.%s is not owned by %s %s.%s, skipping generation", name, namespace, kind, name, namespace)
+		return nil
+	}
+
+	if existing.Spec.Service.Port == cd.Spec.Service.Port &&
+		equality.Semantic.DeepEqual(existing.Spec.TemplateRef, cd.Spec.TemplateRef) &&
+		existing.Spec.RevertOnDeletion == cd.Spec.RevertOnDeletion &&
+		existing.Labels[flaggerv1.ShardLabel] == cd.Labels[flaggerv1.ShardLabel] {
+		return nil
+	}
+
+	cdClone := existing.DeepCopy()
+	cdClone.Spec.Service.Port = cd.Spec.Service.Port
+	cdClone.Spec.TemplateRef = cd.Spec.TemplateRef
+	cdClone.Spec.RevertOnDeletion = cd.Spec.RevertOnDeletion
+	if shard, ok := cd.Labels[flaggerv1.ShardLabel]; ok {
+		if cdClone.Labels == nil {
+			cdClone.Labels = make(map[string]string)
+		}
+		cdClone.Labels[flaggerv1.ShardLabel] = shard
+	} else {
+		delete(cdClone.Labels, flaggerv1.ShardLabel)
+	}
+	_, err = c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Update(context.TODO(), cdClone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("canary %s.%s update error: %w", name, namespace, err)
+	}
+	c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).
+		Infof("Generated canary updated for %s %s.%s", kind, name, namespace)
+	return nil
+}
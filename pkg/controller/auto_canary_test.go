@@ -0,0 +1,199 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+	fakeFlagger "github.com/weaveworks/flagger/pkg/client/clientset/versioned/fake"
+	informers "github.com/weaveworks/flagger/pkg/client/informers/externalversions"
+	"github.com/weaveworks/flagger/pkg/logger"
+)
+
+func TestAutoCanaryController_Sync(t *testing.T) {
+	dep := newDeploymentTestDeployment()
+	dep.Labels = map[string]string{flaggerv1.AutoCanaryLabel: "enabled"}
+
+	kubeClient := fake.NewSimpleClientset(dep)
+	flaggerClient := fakeFlagger.NewSimpleClientset()
+	logger, _ := logger.NewLogger("debug")
+
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, 0)
+	flaggerInformerFactory := informers.NewSharedInformerFactory(flaggerClient, 0)
+	fi := Informers{
+		CanaryInformer: flaggerInformerFactory.Flagger().V1beta1().Canaries(),
+	}
+	ctrl := NewAutoCanaryController(kubeClient, flaggerClient, kubeInformerFactory, fi, "ClusterCanaryTemplate/default", logger)
+
+	deploymentIndexer := kubeInformerFactory.Apps().V1().Deployments().Informer().GetIndexer()
+	canaryIndexer := fi.CanaryInformer.Informer().GetIndexer()
+	require.NoError(t, deploymentIndexer.Add(dep))
+
+	// generate canary
+	require.NoError(t, ctrl.syncHandler("Deployment/default/podinfo"))
+
+	cd, err := flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo", cd.Spec.TargetRef.Name)
+	assert.Equal(t, int32(9898), cd.Spec.Service.Port)
+	assert.Equal(t, "default", cd.Spec.TemplateRef.Name)
+	assert.True(t, cd.Spec.RevertOnDeletion)
+	assert.True(t, metav1.IsControlledBy(cd, dep))
+	require.NoError(t, canaryIndexer.Add(cd))
+
+	// update template
+	dep.Annotations = map[string]string{flaggerv1.AutoCanaryTemplateAnnotation: "podinfo"}
+	require.NoError(t, deploymentIndexer.Update(dep))
+	require.NoError(t, ctrl.syncHandler("Deployment/default/podinfo"))
+
+	cd, err = flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "", cd.Spec.TemplateRef.Kind)
+	assert.Equal(t, "podinfo", cd.Spec.TemplateRef.Name)
+	require.NoError(t, canaryIndexer.Update(cd))
+
+	// remove label
+	dep.Labels = nil
+	require.NoError(t, deploymentIndexer.Update(dep))
+	require.NoError(t, ctrl.syncHandler("Deployment/default/podinfo"))
+
+	_, err = flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.Error(t, err)
+}
+
+func TestAutoCanaryController_SkipNotOwned(t *testing.T) {
+	dep := newDeploymentTestDeployment()
+	dep.Labels = map[string]string{flaggerv1.AutoCanaryLabel: "enabled"}
+	cd := newDeploymentTestCanary()
+
+	kubeClient := fake.NewSimpleClientset(dep)
+	flaggerClient := fakeFlagger.NewSimpleClientset(cd)
+	logger, _ := logger.NewLogger("debug")
+
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, time.Minute)
+	flaggerInformerFactory := informers.NewSharedInformerFactory(flaggerClient, 0)
+	fi := Informers{
+		CanaryInformer: flaggerInformerFactory.Flagger().V1beta1().Canaries(),
+	}
+	ctrl := NewAutoCanaryController(kubeClient, flaggerClient, kubeInformerFactory, fi, "default", logger)
+	require.NoError(t, kubeInformerFactory.Apps().V1().Deployments().Informer().GetIndexer().Add(dep))
+	require.NoError(t, fi.CanaryInformer.Informer().GetIndexer().Add(cd))
+
+	require.NoError(t, ctrl.syncHandler("Deployment/default/podinfo"))
+
+	res, err := flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, res.Spec.TemplateRef)
+	assert.Empty(t, res.OwnerReferences)
+
+	// removing the label doesn't delete canaries created by users
+	dep.Labels = nil
+	require.NoError(t, kubeInformerFactory.Apps().V1().Deployments().Informer().GetIndexer().Update(dep))
+	require.NoError(t, ctrl.syncHandler("Deployment/default/podinfo"))
+
+	_, err = flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+}
+
+func TestAutoCanaryController_Shard(t *testing.T) {
+	dep := newDeploymentTestDeployment()
+	dep.Labels = map[string]string{flaggerv1.AutoCanaryLabel: "enabled", flaggerv1.ShardLabel: "a"}
+
+	kubeClient := fake.NewSimpleClientset(dep)
+	flaggerClient := fakeFlagger.NewSimpleClientset()
+	logger, _ := logger.NewLogger("debug")
+
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, 0)
+	flaggerInformerFactory := informers.NewSharedInformerFactory(flaggerClient, 0)
+	fi := Informers{
+		CanaryInformer: flaggerInformerFactory.Flagger().V1beta1().Canaries(),
+	}
+	ctrl := NewAutoCanaryController(kubeClient, flaggerClient, kubeInformerFactory, fi, "ClusterCanaryTemplate/default", logger)
+
+	deploymentIndexer := kubeInformerFactory.Apps().V1().Deployments().Informer().GetIndexer()
+	require.NoError(t, deploymentIndexer.Add(dep))
+
+	// the generated canary is assigned to the workload shard
+	require.NoError(t, ctrl.syncHandler("Deployment/default/podinfo"))
+
+	cd, err := flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "a", cd.Labels[flaggerv1.ShardLabel])
+
+	// move the workload to another shard, the canary of shard a is not in the cache
+	dep.Labels[flaggerv1.ShardLabel] = "b"
+	require.NoError(t, deploymentIndexer.Update(dep))
+	require.NoError(t, ctrl.syncHandler("Deployment/default/podinfo"))
+
+	cd, err = flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "b", cd.Labels[flaggerv1.ShardLabel])
+}
+
+func TestAutoCanaryController_RevertOnDeletion(t *testing.T) {
+	dep := newDeploymentTestDeployment()
+	dep.Annotations = map[string]string{flaggerv1.AutoCanaryTemplateAnnotation: "podinfo-template"}
+	cd, err := canary.NewAutoCanary(dep, appsv1.SchemeGroupVersion.WithKind("Deployment"), dep.Spec.Template, "")
+	require.NoError(t, err)
+	mocks := newDeploymentFixture(cd)
+	flaggerClient := mocks.flaggerClient.(*fakeFlagger.Clientset)
+
+	// the API server marks the canaries with finalizers for deletion
+	gvr := flaggerv1.SchemeGroupVersion.WithResource("canaries")
+	flaggerClient.PrependReactor("delete", "canaries", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj, err := flaggerClient.Tracker().Get(gvr, action.GetNamespace(), action.(k8stesting.DeleteAction).GetName())
+		if err != nil || len(obj.(*flaggerv1.Canary).Finalizers) == 0 {
+			return false, nil, nil
+		}
+		cd := obj.(*flaggerv1.Canary).DeepCopy()
+		now := metav1.Now()
+		cd.DeletionTimestamp = &now
+		return true, nil, flaggerClient.Tracker().Update(gvr, cd, cd.Namespace)
+	})
+	syncCanary := func() error {
+		cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.NoError(t, mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Update(cd))
+		return mocks.ctrl.syncHandler("default/podinfo")
+	}
+
+	// initialize the canary, the target is scaled to zero
+	require.NoError(t, syncCanary())
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	target, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), *target.Spec.Replicas)
+
+	// remove the label
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(mocks.kubeClient, 0)
+	ctrl := NewAutoCanaryController(mocks.kubeClient, mocks.flaggerClient, kubeInformerFactory, mocks.ctrl.flaggerInformers, "", mocks.logger)
+	require.NoError(t, kubeInformerFactory.Apps().V1().Deployments().Informer().GetIndexer().Add(target))
+	require.NoError(t, syncCanary())
+	require.NoError(t, ctrl.syncHandler("Deployment/default/podinfo"))
+
+	// the target is scaled back up before the canary and the primary are deleted
+	require.Error(t, syncCanary())
+	mocks.makeCanaryReady(t)
+	require.NoError(t, syncCanary())
+	target, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), *target.Spec.Replicas)
+
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.False(t, hasFinalizer(cd))
+}
@@ -27,8 +27,10 @@ import (
 	flaggerinformers "github.com/weaveworks/flagger/pkg/client/informers/externalversions/flagger/v1beta1"
 	"github.com/weaveworks/flagger/pkg/metrics"
 	"github.com/weaveworks/flagger/pkg/metrics/observers"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
 	"github.com/weaveworks/flagger/pkg/notifier"
 	"github.com/weaveworks/flagger/pkg/router"
+	"github.com/weaveworks/flagger/pkg/sink"
 )
 
 const controllerAgentName = "flagger"
@@ -52,12 +54,21 @@ type Controller struct {
 	observerFactory  *observers.Factory
 	meshProvider     string
 	eventWebhook     string
+	eventSink        sink.Interface
+	shard            string
+
+	// webhookCaller and providerFactory replace the webhook calls and the metric template providers
+	// when the analysis is simulated, the HTTP webhooks and the metrics servers are used when nil
+	webhookCaller   func(name string, namespace string, phase flaggerv1.CanaryPhase, w flaggerv1.CanaryWebhook) error
+	providerFactory func(metricInterval string, provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte) (providers.Interface, error)
 }
 
 type Informers struct {
-	CanaryInformer flaggerinformers.CanaryInformer
-	MetricInformer flaggerinformers.MetricTemplateInformer
-	AlertInformer  flaggerinformers.AlertProviderInformer
+	CanaryInformer                flaggerinformers.CanaryInformer
+	MetricInformer                flaggerinformers.MetricTemplateInformer
+	AlertInformer                 flaggerinformers.AlertProviderInformer
+	CanaryTemplateInformer        flaggerinformers.CanaryTemplateInformer
+	ClusterCanaryTemplateInformer flaggerinformers.ClusterCanaryTemplateInformer
 }
 
 func NewController(
@@ -73,6 +84,8 @@ func NewController(
 	meshProvider string,
 	version string,
 	eventWebhook string,
+	eventSink sink.Interface,
+	shard string,
 ) *Controller {
 	logger.Debug("Creating event broadcaster")
 	flaggerscheme.AddToScheme(scheme.Scheme)
@@ -104,6 +117,8 @@ func NewController(
 		routerFactory:    routerFactory,
 		meshProvider:     meshProvider,
 		eventWebhook:     eventWebhook,
+		eventSink:        eventSink,
+		shard:            shard,
 	}
 
 	flaggerInformers.CanaryInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -130,14 +145,14 @@ func NewController(
 
 				ctrl.enqueue(new)
 			} else if !newCanary.DeletionTimestamp.IsZero() && hasFinalizer(&newCanary) ||
-				!hasFinalizer(&newCanary) && newCanary.Spec.RevertOnDeletion {
+				!hasFinalizer(&newCanary) && ctrl.needsFinalizer(&newCanary) {
 				// If this was marked for deletion and has finalizers enqueue for finalizing or
 				// if this canary doesn't have finalizers and RevertOnDeletion is true updated speck enqueue
 				ctrl.enqueue(new)
 			}
 
 			// If canary no longer desires reverting, finalizers should be removed
-			if oldCanary.Spec.RevertOnDeletion && !newCanary.Spec.RevertOnDeletion {
+			if oldCanary.Spec.RevertOnDeletion && !ctrl.needsFinalizer(&newCanary) {
 				ctrl.logger.Infof("%s.%s opting out, deleting finalizers", newCanary.Name, newCanary.Namespace)
 				err := ctrl.removeFinalizer(&newCanary)
 				if err != nil {
@@ -151,6 +166,7 @@ func NewController(
 			if ok {
 				ctrl.logger.Infof("Deleting %s.%s from cache", r.Name, r.Namespace)
 				ctrl.canaries.Delete(fmt.Sprintf("%s.%s", r.Name, r.Namespace))
+				ctrl.releaseCanary(r.Name, r.Namespace)
 			}
 		},
 	})
@@ -233,8 +249,8 @@ func (c *Controller) syncHandler(key string) error {
 		return nil
 	}
 
-	// Finalize if canary has been marked for deletion and revert is desired
-	if cd.Spec.RevertOnDeletion && cd.ObjectMeta.DeletionTimestamp != nil {
+	// Finalize if canary has been marked for deletion and revert or mesh clusters cleanup is desired
+	if cd.ObjectMeta.DeletionTimestamp != nil && (c.needsFinalizer(cd) || hasFinalizer(cd)) {
 		// If finalizers have been previously removed proceed
 		if !hasFinalizer(cd) {
 			c.logger.Infof("Canary %s.%s has been finalized", cd.Name, cd.Namespace)
@@ -280,8 +296,8 @@ func (c *Controller) syncHandler(key string) error {
 
 	c.canaries.Store(fmt.Sprintf("%s.%s", cd.Name, cd.Namespace), cd)
 
-	// If opt in for revertOnDeletion add finalizer if not present
-	if cd.Spec.RevertOnDeletion && !hasFinalizer(cd) {
+	// If opt in for revertOnDeletion or mesh clusters are set add finalizer if not present
+	if c.needsFinalizer(cd) && !hasFinalizer(cd) {
 		if err := c.addFinalizer(cd); err != nil {
 			return fmt.Errorf("unable to add finalizer to canary %s.%s: %w", cd.Name, cd.Namespace, err)
 		}
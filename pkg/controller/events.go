@@ -10,6 +10,7 @@ import (
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 	"github.com/weaveworks/flagger/pkg/notifier"
+	"github.com/weaveworks/flagger/pkg/sink"
 )
 
 func (c *Controller) recordEventInfof(r *flaggerv1.Canary, template string, args ...interface{}) {
@@ -50,6 +51,15 @@ func (c *Controller) sendEventToWebhook(r *flaggerv1.Canary, eventType, template
 	}
 }
 
+func (c *Controller) publishEvent(event sink.Event) {
+	if c.eventSink == nil {
+		return
+	}
+	if err := c.eventSink.Publish(event); err != nil {
+		c.logger.With("canary", event.Key()).Errorf("error publishing event to sink: %v", err)
+	}
+}
+
 func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bool, severity flaggerv1.AlertSeverity) {
 	var fields []notifier.Field
 	if metadata {
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/retry"
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/router"
 )
 
 const finalizer = "finalizer.flagger.app"
@@ -23,8 +25,29 @@ func (c *Controller) finalize(old interface{}) error {
 		return fmt.Errorf("get query error: %w", err)
 	}
 
+	// Revert the routing of the provider set in the template
+	canary, err = c.applyCanaryTemplate(canary)
+	if err != nil {
+		return err
+	}
+
+	// Only the routing objects of the other mesh clusters are deleted if revert is not desired
+	if !canary.Spec.RevertOnDeletion {
+		return c.deleteMeshClusterObjects(canary)
+	}
+
+	// The canaries generated for a workload are garbage collected with it, there is nothing to revert
+	deleted, err := c.isOwnerDeleted(canary)
+	if err != nil {
+		return err
+	}
+	if deleted {
+		c.logger.Infof("%s.%s kind %s deleted, skipping revert", canary.Name, canary.Namespace, canary.Spec.TargetRef.Kind)
+		return c.deleteMeshClusterObjects(canary)
+	}
+
 	// Retrieve a controller
-	canaryController := c.canaryFactory.Controller(canary.Spec.TargetRef.Kind)
+	canaryController := c.canaryFactory.Controller(canary.Spec.TargetRef)
 
 	// Set the status to terminating if not already in that state
 	if canary.Status.Phase != flaggerv1.CanaryPhaseTerminating {
@@ -71,13 +94,66 @@ func (c *Controller) finalize(old interface{}) error {
 	return nil
 }
 
+// isOwnerDeleted returns true if the canary is controlled by its target and the target has been deleted
+func (c *Controller) isOwnerDeleted(cd *flaggerv1.Canary) (bool, error) {
+	ref := metav1.GetControllerOf(cd)
+	if ref == nil || ref.Kind != cd.Spec.TargetRef.Kind || ref.Name != cd.Spec.TargetRef.Name {
+		return false, nil
+	}
+
+	var owner metav1.Object
+	var err error
+	switch ref.Kind {
+	case "Deployment":
+		owner, err = c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	case "DaemonSet":
+		owner, err = c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	default:
+		return false, nil
+	}
+	if errors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, fmt.Errorf("%s %s.%s get query error: %w", ref.Kind, ref.Name, cd.Namespace, err)
+	}
+	return owner.GetUID() != ref.UID || owner.GetDeletionTimestamp() != nil, nil
+}
+
+// needsFinalizer returns true if the canary has to be finalized on deletion, either to revert the target
+// and the routing or to delete the routing objects that the canary can't own in the other mesh clusters
+func (c *Controller) needsFinalizer(cd *flaggerv1.Canary) bool {
+	return cd.Spec.RevertOnDeletion || c.routerFactory.HasMeshClusters()
+}
+
+// deleteMeshClusterObjects removes the routing objects created in the other mesh clusters,
+// they are not garbage collected with the canary
+func (c *Controller) deleteMeshClusterObjects(cd *flaggerv1.Canary) error {
+	meshRouter, ok := c.routerFactory.MeshRouter(c.canaryMeshProvider(cd)).(router.MeshClusterRouter)
+	if !ok {
+		return nil
+	}
+	if err := meshRouter.DeleteMeshClusterObjects(cd); err != nil {
+		return fmt.Errorf("failed to delete mesh cluster objects: %w", err)
+	}
+	return nil
+}
+
+// canaryMeshProvider returns the mesh provider of the canary
+func (c *Controller) canaryMeshProvider(cd *flaggerv1.Canary) string {
+	provider := c.meshProvider
+	if cd.Spec.Provider != "" {
+		provider = cd.Spec.Provider
+	}
+	if cd.Spec.TargetRef.IsKnativeService() {
+		provider = flaggerv1.KnativeProvider
+	}
+	return provider
+}
+
 // revertMesh reverts defined mesh provider based upon the implementation's respective Finalize method.
 // If the Finalize method encounters and error that is returned, else revert is considered successful.
 func (c *Controller) revertMesh(r *flaggerv1.Canary) error {
-	provider := c.meshProvider
-	if r.Spec.Provider != "" {
-		provider = r.Spec.Provider
-	}
+	provider := c.canaryMeshProvider(r)
 
 	meshRouter := c.routerFactory.MeshRouter(provider)
 	if err := meshRouter.Finalize(r); err != nil {
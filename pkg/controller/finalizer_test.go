@@ -1,15 +1,20 @@
 package controller
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	k8sTesting "k8s.io/client-go/testing"
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 	fakeFlagger "github.com/weaveworks/flagger/pkg/client/clientset/versioned/fake"
+	"github.com/weaveworks/flagger/pkg/router"
 )
 
 func TestFinalizer_hasFinalizer(t *testing.T) {
@@ -88,3 +93,80 @@ func TestFinalizer_removeFinalizer(t *testing.T) {
 		}
 	}
 }
+
+func TestFinalizer_finalizeOwnerDeleted(t *testing.T) {
+	// the canary is owned by a previous deployment with the same name
+	owner := newDeploymentTestDeployment()
+	owner.UID = "previous"
+	c := newDeploymentTestCanary()
+	c.Spec.RevertOnDeletion = true
+	c.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(owner, appsv1.SchemeGroupVersion.WithKind("Deployment"))}
+	mocks := newDeploymentFixture(c)
+
+	// there is nothing to revert
+	require.NoError(t, mocks.ctrl.finalize(c))
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotEqual(t, flaggerv1.CanaryPhaseTerminating, cd.Status.Phase)
+}
+
+func TestFinalizer_finalizeCanaryTemplate(t *testing.T) {
+	c := newDeploymentTestCanaryTemplateRef()
+	c.Spec.RevertOnDeletion = true
+	mocks := newDeploymentFixture(c)
+	mocks.ctrl.meshProvider = "kubernetes"
+	template := newDeploymentTestCanaryTemplate()
+	template.Spec.Provider = "istio"
+	require.NoError(t, mocks.ctrl.flaggerInformers.CanaryTemplateInformer.Informer().GetIndexer().Update(template))
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	vs.Annotations = map[string]string{"flagger.kubernetes.io/original-configuration": `{"hosts":["podinfo.example.com"]}`}
+	_, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Update(context.TODO(), vs, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.makeCanaryReady(t)
+
+	// the virtual service of the template provider is reverted
+	require.NoError(t, mocks.ctrl.finalize(c))
+	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"podinfo.example.com"}, vs.Spec.Hosts)
+}
+
+func TestFinalizer_finalizeMeshClusters(t *testing.T) {
+	c := newDeploymentTestCanary()
+	mocks := newDeploymentFixture(c)
+	east := fakeFlagger.NewSimpleClientset()
+	mocks.ctrl.routerFactory = router.NewFactory(nil, mocks.kubeClient, mocks.flaggerClient, "annotationsPrefix",
+		mocks.logger, mocks.flaggerClient, router.MeshCluster{Name: "east", Client: east})
+
+	// the finalizer is added without revertOnDeletion
+	require.NoError(t, mocks.ctrl.syncHandler("default/podinfo"))
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.True(t, hasFinalizer(cd))
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	_, err = east.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// only the objects of the other mesh clusters are deleted
+	require.NoError(t, mocks.ctrl.finalize(c))
+	_, err = east.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.True(t, errors.IsNotFound(err))
+	_, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotEqual(t, flaggerv1.CanaryPhaseTerminating, cd.Status.Phase)
+}
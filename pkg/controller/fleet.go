@@ -0,0 +1,407 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+	"github.com/weaveworks/flagger/pkg/validation"
+)
+
+// FleetClients returns the clientsets of a fleet cluster
+type FleetClients func(fleet *flaggerv1.CanaryFleet, cluster flaggerv1.CanaryFleetCluster) (kubernetes.Interface, clientset.Interface, error)
+
+// FleetController rolls out the canaries of a CanaryFleet wave by wave, the canaries of the clusters
+// outside the current wave are held with the fleet-hold annotation and the analysis underway
+// in all clusters is aborted when the canary fails in one of them
+type FleetController struct {
+	kubeClient     kubernetes.Interface
+	flaggerClient  clientset.Interface
+	clients        FleetClients
+	clientsCache   map[string]fleetClientsCache
+	namespace      string
+	selectorLabels []string
+	listOptions    func(options *metav1.ListOptions)
+	logger         *zap.SugaredLogger
+}
+
+type fleetClientsCache struct {
+	resourceVersion string
+	kubeClient      kubernetes.Interface
+	flaggerClient   clientset.Interface
+}
+
+// fleetMember is the canary state in a fleet cluster
+type fleetMember struct {
+	cluster       string
+	wave          int
+	canary        *flaggerv1.Canary
+	flaggerClient clientset.Interface
+	// pending is set when a new revision waits to be analysed
+	pending bool
+	err     error
+}
+
+func NewFleetController(
+	kubeClient kubernetes.Interface,
+	flaggerClient clientset.Interface,
+	namespace string,
+	selectorLabels []string,
+	listOptions func(options *metav1.ListOptions),
+	logger *zap.SugaredLogger,
+) *FleetController {
+	ctrl := &FleetController{
+		kubeClient:     kubeClient,
+		flaggerClient:  flaggerClient,
+		clientsCache:   make(map[string]fleetClientsCache),
+		namespace:      namespace,
+		selectorLabels: selectorLabels,
+		listOptions:    listOptions,
+		logger:         logger,
+	}
+	ctrl.clients = ctrl.secretClients
+	return ctrl
+}
+
+// Run syncs the fleets at every interval until the stop channel is closed
+func (c *FleetController) Run(interval time.Duration, stopCh <-chan struct{}) {
+	c.logger.Info("Starting canary fleet controller")
+	wait.Until(c.syncFleets, interval, stopCh)
+	c.logger.Info("Stopping canary fleet controller")
+}
+
+// syncFleets advances the fleets selected by the list options,
+// so that the fleets are split between the shards like the canaries
+func (c *FleetController) syncFleets() {
+	opts := metav1.ListOptions{}
+	if c.listOptions != nil {
+		c.listOptions(&opts)
+	}
+	fleets, err := c.flaggerClient.FlaggerV1beta1().CanaryFleets(c.namespace).List(context.TODO(), opts)
+	if err != nil {
+		c.logger.Errorf("Canary fleets list query error: %v", err)
+		return
+	}
+	for i := range fleets.Items {
+		fleet := &fleets.Items[i]
+		if err := c.syncFleet(fleet); err != nil {
+			c.logger.With("fleet", fmt.Sprintf("%s.%s", fleet.Name, fleet.Namespace)).Errorf("%v", err)
+		}
+	}
+}
+
+// syncFleet advances the fleet rollout from the state of the canaries in its clusters
+func (c *FleetController) syncFleet(fleet *flaggerv1.CanaryFleet) error {
+	status := fleet.Status.DeepCopy()
+	if errs := validation.ValidateCanaryFleet(fleet); len(errs) > 0 {
+		status.Message = fmt.Sprintf("spec is invalid: %v", errs.ToAggregate())
+		return c.updateStatus(fleet, status)
+	}
+
+	waves := fleetWaves(fleet.Spec)
+	members := c.fleetMembers(fleet, waves)
+	logger := c.logger.With("fleet", fmt.Sprintf("%s.%s", fleet.Name, fleet.Namespace))
+
+	wave := 0
+	for i := range waves {
+		if waves[i].name == status.Wave {
+			wave = i
+		}
+	}
+
+	abort := false
+	switch status.Phase {
+	case flaggerv1.CanaryPhaseProgressing:
+		if failed := failedFleetClusters(members, wave); len(failed) > 0 {
+			status.Phase = flaggerv1.CanaryPhaseFailed
+			status.Message = fmt.Sprintf("Canary failed in %s, rollout aborted in all clusters", strings.Join(failed, ", "))
+			abort = true
+		} else if isFleetWaveDone(members, wave) {
+			if wave == len(waves)-1 {
+				status.Phase = flaggerv1.CanaryPhaseSucceeded
+				status.Message = fmt.Sprintf("Canary rolled out to %d clusters", len(members))
+			} else {
+				wave++
+				status.Message = fmt.Sprintf("Wave %s succeeded, starting wave %s", waves[wave-1].name, waves[wave].name)
+			}
+		}
+	default:
+		if pending := pendingFleetClusters(members, status.Phase); len(pending) > 0 {
+			wave = 0
+			status.Phase = flaggerv1.CanaryPhaseProgressing
+			status.Message = fmt.Sprintf("New revision detected in %s, starting wave %s", strings.Join(pending, ", "), waves[0].name)
+		} else if status.Phase == "" {
+			status.Phase = flaggerv1.CanaryPhaseInitialized
+		}
+	}
+	if status.Phase != fleet.Status.Phase || waves[wave].name != fleet.Status.Wave {
+		status.LastTransitionTime = metav1.Now()
+		logger.Infof("%s", status.Message)
+	}
+	status.Wave = waves[wave].name
+
+	// hold the clusters outside of the waves being rolled out
+	hold := fmt.Sprintf("%s/%s", fleet.Namespace, fleet.Name)
+	status.Clusters = make([]flaggerv1.CanaryFleetClusterStatus, 0, len(members))
+	for _, m := range members {
+		cs := flaggerv1.CanaryFleetClusterStatus{Name: m.cluster, Wave: waves[m.wave].name}
+		if m.err == nil && m.canary != nil {
+			held := status.Phase != flaggerv1.CanaryPhaseProgressing || m.wave > wave
+			annotations := map[string]interface{}{}
+			if _, ok := m.canary.Annotations[flaggerv1.FleetHoldAnnotation]; ok != held {
+				annotations[flaggerv1.FleetHoldAnnotation] = nil
+				if held {
+					annotations[flaggerv1.FleetHoldAnnotation] = hold
+				}
+			}
+			if abort && isAnalysing(m.canary) {
+				annotations[flaggerv1.AbortAnnotation] = "true"
+			}
+			if len(annotations) > 0 {
+				_, m.err = patchCanaryAnnotations(m.flaggerClient, m.canary, annotations)
+			}
+
+			cs.Phase = m.canary.Status.Phase
+			cs.CanaryWeight = m.canary.Status.CanaryWeight
+			cs.FailedChecks = m.canary.Status.FailedChecks
+			cs.Held = held
+		}
+		if m.err != nil {
+			cs.Message = m.err.Error()
+			logger.Errorf("Cluster %s: %v", m.cluster, m.err)
+		}
+		status.Clusters = append(status.Clusters, cs)
+	}
+
+	return c.updateStatus(fleet, status)
+}
+
+// fleetMembers fetches the canary of each cluster, the clusters are returned in the spec order
+func (c *FleetController) fleetMembers(fleet *flaggerv1.CanaryFleet, waves []fleetWave) []fleetMember {
+	namespace := fleet.Spec.CanaryRef.Namespace
+	if namespace == "" {
+		namespace = fleet.Namespace
+	}
+
+	var members []fleetMember
+	for _, cluster := range fleet.Spec.Clusters {
+		m := fleetMember{cluster: cluster.Name}
+		for i, w := range waves {
+			if w.clusters[cluster.Name] {
+				m.wave = i
+			}
+		}
+
+		kubeClient, flaggerClient, err := c.clients(fleet, cluster)
+		if err != nil {
+			m.err = err
+			members = append(members, m)
+			continue
+		}
+		m.flaggerClient = flaggerClient
+
+		cd, err := flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(context.TODO(), fleet.Spec.CanaryRef.Name, metav1.GetOptions{})
+		if err != nil {
+			m.err = fmt.Errorf("canary %s.%s get query error: %w", fleet.Spec.CanaryRef.Name, namespace, err)
+			members = append(members, m)
+			continue
+		}
+		m.canary = cd
+
+		// the analysis of a new revision is pending if the canary would advance without the fleet hold
+		switch cd.Status.Phase {
+		case flaggerv1.CanaryPhaseInitialized, flaggerv1.CanaryPhaseSucceeded, flaggerv1.CanaryPhaseFailed:
+			configTracker := &canary.ConfigTracker{
+				Logger:        c.logger,
+				KubeClient:    kubeClient,
+				FlaggerClient: flaggerClient,
+			}
+			canaryController := canary.NewFactory(kubeClient, flaggerClient, configTracker, c.selectorLabels, c.logger).
+				Controller(cd.Spec.TargetRef)
+			if m.pending, err = canaryController.HasTargetChanged(cd); err != nil {
+				m.err = err
+			} else if !m.pending {
+				m.pending, m.err = canaryController.HaveDependenciesChanged(cd)
+			}
+		}
+		members = append(members, m)
+	}
+	return members
+}
+
+// secretClients builds the clientsets of the clusters from the kubeconfig secrets,
+// the clientsets are rebuilt when the secrets change
+func (c *FleetController) secretClients(fleet *flaggerv1.CanaryFleet, cluster flaggerv1.CanaryFleetCluster) (kubernetes.Interface, clientset.Interface, error) {
+	if cluster.SecretRef == nil {
+		return c.kubeClient, c.flaggerClient, nil
+	}
+
+	secret, err := c.kubeClient.CoreV1().Secrets(fleet.Namespace).Get(context.TODO(), cluster.SecretRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("secret %s.%s get query error: %w", cluster.SecretRef.Name, fleet.Namespace, err)
+	}
+	key := fmt.Sprintf("%s/%s", secret.Namespace, secret.Name)
+	if cached, ok := c.clientsCache[key]; ok && cached.resourceVersion == secret.ResourceVersion {
+		return cached.kubeClient, cached.flaggerClient, nil
+	}
+
+	kubeconfig, ok := secret.Data[flaggerv1.FleetKubeconfigKey]
+	if !ok {
+		return nil, nil, fmt.Errorf("secret %s.%s does not contain the %s key", secret.Name, secret.Namespace, flaggerv1.FleetKubeconfigKey)
+	}
+	cfg, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("secret %s.%s kubeconfig error: %w", secret.Name, secret.Namespace, err)
+	}
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cluster %s kubernetes clientset error: %w", cluster.Name, err)
+	}
+	flaggerClient, err := clientset.NewForConfig(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cluster %s flagger clientset error: %w", cluster.Name, err)
+	}
+
+	c.clientsCache[key] = fleetClientsCache{
+		resourceVersion: secret.ResourceVersion,
+		kubeClient:      kubeClient,
+		flaggerClient:   flaggerClient,
+	}
+	return kubeClient, flaggerClient, nil
+}
+
+func (c *FleetController) updateStatus(fleet *flaggerv1.CanaryFleet, status *flaggerv1.CanaryFleetStatus) error {
+	if equality.Semantic.DeepEqual(fleet.Status, *status) {
+		return nil
+	}
+	fleetCopy := fleet.DeepCopy()
+	fleetCopy.Status = *status
+	_, err := c.flaggerClient.FlaggerV1beta1().CanaryFleets(fleet.Namespace).UpdateStatus(context.TODO(), fleetCopy, metav1.UpdateOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("fleet %s.%s status update error: %w", fleet.Name, fleet.Namespace, err)
+	}
+	return nil
+}
+
+// fleetWave holds the clusters of a wave
+type fleetWave struct {
+	name     string
+	clusters map[string]bool
+}
+
+// fleetWaves assigns each cluster to a wave, the clusters left out of the waves are added to the last wave
+func fleetWaves(spec flaggerv1.CanaryFleetSpec) []fleetWave {
+	assigned := make(map[string]bool)
+	var waves []fleetWave
+	for _, w := range spec.Waves {
+		wave := fleetWave{name: w.Name, clusters: make(map[string]bool)}
+		for _, name := range w.Clusters {
+			if !assigned[name] {
+				assigned[name] = true
+				wave.clusters[name] = true
+			}
+		}
+		if w.Percentage > 0 {
+			// round up so that every wave with a percentage contains at least one cluster
+			target := (w.Percentage*len(spec.Clusters) + 99) / 100
+			for _, cluster := range spec.Clusters {
+				if len(assigned) >= target {
+					break
+				}
+				if !assigned[cluster.Name] {
+					assigned[cluster.Name] = true
+					wave.clusters[cluster.Name] = true
+				}
+			}
+		}
+		waves = append(waves, wave)
+	}
+
+	if len(waves) == 0 {
+		waves = append(waves, fleetWave{name: "all", clusters: make(map[string]bool)})
+	}
+	for _, cluster := range spec.Clusters {
+		if !assigned[cluster.Name] {
+			waves[len(waves)-1].clusters[cluster.Name] = true
+		}
+	}
+	return waves
+}
+
+// failedFleetClusters returns the clusters of the started waves where the analysis of the revision failed
+func failedFleetClusters(members []fleetMember, wave int) []string {
+	var failed []string
+	for _, m := range members {
+		if m.wave <= wave && m.canary != nil && !m.pending && m.canary.Status.Phase == flaggerv1.CanaryPhaseFailed {
+			failed = append(failed, m.cluster)
+		}
+	}
+	return failed
+}
+
+// isFleetWaveDone returns true when the revision is promoted in all the clusters of the wave
+func isFleetWaveDone(members []fleetMember, wave int) bool {
+	for _, m := range members {
+		if m.wave != wave {
+			continue
+		}
+		if m.err != nil || m.canary == nil || m.pending {
+			return false
+		}
+		switch m.canary.Status.Phase {
+		case flaggerv1.CanaryPhaseInitialized, flaggerv1.CanaryPhaseSucceeded:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// pendingFleetClusters returns the clusters with a new revision that starts a rollout,
+// after a failed rollout only a new revision in the clusters that rolled back restarts it
+func pendingFleetClusters(members []fleetMember, phase flaggerv1.CanaryPhase) []string {
+	var pending []string
+	for _, m := range members {
+		if !m.pending {
+			continue
+		}
+		if phase == flaggerv1.CanaryPhaseFailed && m.canary.Status.Phase != flaggerv1.CanaryPhaseFailed {
+			continue
+		}
+		pending = append(pending, m.cluster)
+	}
+	return pending
+}
+
+func isAnalysing(cd *flaggerv1.Canary) bool {
+	return cd.Status.Phase == flaggerv1.CanaryPhaseProgressing || cd.Status.Phase == flaggerv1.CanaryPhaseWaiting
+}
+
+// patchCanaryAnnotations merge patches the canary annotations, the nil values remove the annotations.
+// The patch is rejected with a conflict error if the canary has changed since it was read.
+func patchCanaryAnnotations(flaggerClient clientset.Interface, cd *flaggerv1.Canary, annotations map[string]interface{}) (*flaggerv1.Canary, error) {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": cd.ResourceVersion,
+			"annotations":     annotations,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("annotations patch marshal error: %w", err)
+	}
+	return flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).Patch(context.TODO(), cd.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+}
@@ -0,0 +1,246 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+	fakeFlagger "github.com/weaveworks/flagger/pkg/client/clientset/versioned/fake"
+	"github.com/weaveworks/flagger/pkg/logger"
+)
+
+type fleetFixture struct {
+	ctrl          *FleetController
+	flaggerClient *fakeFlagger.Clientset
+	clusters      map[string]*fleetTestCluster
+	t             *testing.T
+}
+
+type fleetTestCluster struct {
+	kubeClient    *fake.Clientset
+	flaggerClient *fakeFlagger.Clientset
+	deployer      canary.Controller
+}
+
+func newFleetFixture(t *testing.T, waves []flaggerv1.CanaryFleetWave, names ...string) fleetFixture {
+	fleet := &flaggerv1.CanaryFleet{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "flagger-system"},
+		Spec: flaggerv1.CanaryFleetSpec{
+			CanaryRef: flaggerv1.CrossNamespaceObjectReference{Name: "podinfo", Namespace: "default"},
+			Waves:     waves,
+		},
+	}
+
+	logger, _ := logger.NewLogger("debug")
+	clusters := make(map[string]*fleetTestCluster)
+	for _, name := range names {
+		fleet.Spec.Clusters = append(fleet.Spec.Clusters, flaggerv1.CanaryFleetCluster{Name: name})
+
+		cd := newSimulationTestCanary()
+		kubeClient := fake.NewSimpleClientset(newSimulationTarget(cd))
+		flaggerClient := fakeFlagger.NewSimpleClientset(cd)
+		configTracker := &canary.ConfigTracker{Logger: logger, KubeClient: kubeClient, FlaggerClient: flaggerClient}
+		factory := canary.NewFactory(kubeClient, flaggerClient, configTracker, []string{"app"}, logger)
+		clusters[name] = &fleetTestCluster{
+			kubeClient:    kubeClient,
+			flaggerClient: flaggerClient,
+			deployer:      factory.Controller(cd.Spec.TargetRef),
+		}
+	}
+
+	flaggerClient := fakeFlagger.NewSimpleClientset(fleet)
+	ctrl := NewFleetController(fake.NewSimpleClientset(), flaggerClient, "", []string{"app"}, nil, logger)
+	ctrl.clients = func(_ *flaggerv1.CanaryFleet, cluster flaggerv1.CanaryFleetCluster) (kubernetes.Interface, clientset.Interface, error) {
+		c := clusters[cluster.Name]
+		return c.kubeClient, c.flaggerClient, nil
+	}
+
+	f := fleetFixture{ctrl: ctrl, flaggerClient: flaggerClient, clusters: clusters, t: t}
+	for _, name := range names {
+		f.setPhase(name, flaggerv1.CanaryPhaseSucceeded)
+	}
+	return f
+}
+
+// setPhase sets the canary status as the Flagger controller of the cluster would
+func (f fleetFixture) setPhase(cluster string, phase flaggerv1.CanaryPhase) {
+	c := f.clusters[cluster]
+	cd, err := c.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(f.t, err)
+	require.NoError(f.t, c.deployer.SyncStatus(cd, flaggerv1.CanaryStatus{Phase: phase}))
+}
+
+// deploy updates the target of the clusters with a new revision
+func (f fleetFixture) deploy(image string, clusters ...string) {
+	for _, name := range clusters {
+		deployments := f.clusters[name].kubeClient.AppsV1().Deployments("default")
+		dep, err := deployments.Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(f.t, err)
+		dep.Spec.Template.Spec.Containers[0].Image = image
+		_, err = deployments.Update(context.TODO(), dep, metav1.UpdateOptions{})
+		require.NoError(f.t, err)
+	}
+}
+
+func (f fleetFixture) sync() *flaggerv1.CanaryFleet {
+	f.ctrl.syncFleets()
+	fleet, err := f.flaggerClient.FlaggerV1beta1().CanaryFleets("flagger-system").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(f.t, err)
+	return fleet
+}
+
+func (f fleetFixture) canary(cluster string) *flaggerv1.Canary {
+	cd, err := f.clusters[cluster].flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(f.t, err)
+	return cd
+}
+
+// held returns the clusters where the canary is held by the fleet
+func (f fleetFixture) held(clusters ...string) []string {
+	var held []string
+	for _, name := range clusters {
+		if _, ok := f.canary(name).Annotations[flaggerv1.FleetHoldAnnotation]; ok {
+			held = append(held, name)
+		}
+	}
+	return held
+}
+
+func TestFleet_Waves(t *testing.T) {
+	all := []string{"eu-1", "eu-2", "us-1", "us-2"}
+	f := newFleetFixture(t, []flaggerv1.CanaryFleetWave{
+		{Name: "canary", Clusters: []string{"eu-1"}},
+		{Name: "half", Percentage: 50},
+		{Name: "rest", Percentage: 100},
+	}, all...)
+
+	// all the canaries are held while the fleet is idle
+	fleet := f.sync()
+	assert.Equal(t, flaggerv1.CanaryPhaseInitialized, fleet.Status.Phase)
+	assert.Equal(t, all, f.held(all...))
+	assert.Equal(t, "flagger-system/podinfo", f.canary("eu-1").Annotations[flaggerv1.FleetHoldAnnotation])
+
+	// start with the canary cluster
+	f.deploy("podinfo:v2", all...)
+	fleet = f.sync()
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, fleet.Status.Phase)
+	assert.Equal(t, "canary", fleet.Status.Wave)
+	assert.Equal(t, []string{"eu-2", "us-1", "us-2"}, f.held(all...))
+
+	f.setPhase("eu-1", flaggerv1.CanaryPhaseProgressing)
+	fleet = f.sync()
+	assert.Equal(t, "canary", fleet.Status.Wave)
+	require.Len(t, fleet.Status.Clusters, 4)
+	assert.Equal(t, flaggerv1.CanaryFleetClusterStatus{Name: "eu-1", Wave: "canary", Phase: flaggerv1.CanaryPhaseProgressing},
+		fleet.Status.Clusters[0])
+	assert.True(t, fleet.Status.Clusters[1].Held)
+
+	// the next wave starts once the canary is promoted
+	f.setPhase("eu-1", flaggerv1.CanaryPhaseSucceeded)
+	fleet = f.sync()
+	assert.Equal(t, "half", fleet.Status.Wave)
+	assert.Equal(t, []string{"us-1", "us-2"}, f.held(all...))
+
+	f.setPhase("eu-2", flaggerv1.CanaryPhaseSucceeded)
+	fleet = f.sync()
+	assert.Equal(t, "rest", fleet.Status.Wave)
+	assert.Empty(t, f.held(all...))
+
+	f.setPhase("us-1", flaggerv1.CanaryPhaseSucceeded)
+	fleet = f.sync()
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, fleet.Status.Phase)
+
+	f.setPhase("us-2", flaggerv1.CanaryPhaseSucceeded)
+	fleet = f.sync()
+	assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, fleet.Status.Phase)
+	assert.Equal(t, all, f.held(all...))
+}
+
+func TestFleet_Shard(t *testing.T) {
+	f := newFleetFixture(t, []flaggerv1.CanaryFleetWave{{Name: "all", Percentage: 100}}, "eu-1")
+	f.ctrl.listOptions = func(options *metav1.ListOptions) {
+		options.LabelSelector = flaggerv1.ShardLabel + "=a"
+	}
+
+	// the fleets of the other shards are ignored
+	fleet := f.sync()
+	assert.Empty(t, fleet.Status.Phase)
+	assert.Empty(t, f.held("eu-1"))
+
+	fleet.Labels = map[string]string{flaggerv1.ShardLabel: "a"}
+	_, err := f.flaggerClient.FlaggerV1beta1().CanaryFleets("flagger-system").Update(context.TODO(), fleet, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	fleet = f.sync()
+	assert.Equal(t, flaggerv1.CanaryPhaseInitialized, fleet.Status.Phase)
+	assert.Equal(t, []string{"eu-1"}, f.held("eu-1"))
+}
+
+func TestFleet_Abort(t *testing.T) {
+	all := []string{"eu-1", "eu-2", "us-1"}
+	f := newFleetFixture(t, []flaggerv1.CanaryFleetWave{
+		{Name: "europe", Clusters: []string{"eu-1", "eu-2"}},
+		{Name: "us", Percentage: 100},
+	}, all...)
+
+	f.deploy("podinfo:v2", all...)
+	fleet := f.sync()
+	assert.Equal(t, "europe", fleet.Status.Wave)
+	assert.Equal(t, []string{"us-1"}, f.held(all...))
+
+	// a failure in one cluster aborts the analysis in the others
+	f.setPhase("eu-1", flaggerv1.CanaryPhaseProgressing)
+	f.setPhase("eu-2", flaggerv1.CanaryPhaseFailed)
+	fleet = f.sync()
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, fleet.Status.Phase)
+	assert.Contains(t, fleet.Status.Message, "eu-2")
+	assert.Equal(t, "true", f.canary("eu-1").Annotations[flaggerv1.AbortAnnotation])
+	assert.NotContains(t, f.canary("us-1").Annotations, flaggerv1.AbortAnnotation)
+	assert.Equal(t, all, f.held(all...))
+
+	// the rollout doesn't restart for the clusters that didn't run the failed revision
+	f.setPhase("eu-1", flaggerv1.CanaryPhaseFailed)
+	fleet = f.sync()
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, fleet.Status.Phase)
+
+	// a new revision restarts the rollout from the first wave
+	f.deploy("podinfo:v3", all...)
+	fleet = f.sync()
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, fleet.Status.Phase)
+	assert.Equal(t, "europe", fleet.Status.Wave)
+	assert.Equal(t, []string{"us-1"}, f.held(all...))
+}
+
+func TestFleet_Waves_Assignment(t *testing.T) {
+	spec := flaggerv1.CanaryFleetSpec{
+		Waves: []flaggerv1.CanaryFleetWave{
+			{Name: "canary", Clusters: []string{"c3"}},
+			{Name: "quarter", Percentage: 25},
+			{Name: "half", Percentage: 50},
+		},
+	}
+	for _, name := range []string{"c1", "c2", "c3", "c4", "c5", "c6", "c7", "c8"} {
+		spec.Clusters = append(spec.Clusters, flaggerv1.CanaryFleetCluster{Name: name})
+	}
+
+	waves := fleetWaves(spec)
+	require.Len(t, waves, 3)
+	assert.Equal(t, map[string]bool{"c3": true}, waves[0].clusters)
+	assert.Equal(t, map[string]bool{"c1": true}, waves[1].clusters)
+	// the clusters left out are rolled out with the last wave
+	assert.Equal(t, map[string]bool{"c2": true, "c4": true, "c5": true, "c6": true, "c7": true, "c8": true}, waves[2].clusters)
+
+	spec.Waves = nil
+	waves = fleetWaves(spec)
+	require.Len(t, waves, 1)
+	assert.Equal(t, "all", waves[0].name)
+	assert.Len(t, waves[0].clusters, 8)
+}
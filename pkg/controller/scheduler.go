@@ -10,6 +10,8 @@ import (
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 	"github.com/weaveworks/flagger/pkg/canary"
 	"github.com/weaveworks/flagger/pkg/router"
+	"github.com/weaveworks/flagger/pkg/sink"
+	"github.com/weaveworks/flagger/pkg/validation"
 )
 
 const (
@@ -30,6 +32,16 @@ func (c *Controller) scheduleCanaries() {
 		name := key.(string)
 		current[name] = fmt.Sprintf("%s.%s", cn.Spec.TargetRef.Name, cn.Namespace)
 
+		// use the analysis interval of the template
+		if merged, err := c.applyCanaryTemplate(cn); err == nil {
+			cn = merged
+		} else {
+			c.logger.With("canary", name).Errorf("%v", err)
+		}
+		if cn.GetAnalysis() == nil {
+			return true
+		}
+
 		job, exists := c.jobs[name]
 		// schedule new job for existing job with different analysis interval or non-existing job
 		if (exists && job.GetCanaryAnalysisInterval() != cn.GetAnalysisInterval()) || !exists {
@@ -94,14 +106,41 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		return
 	}
 
+	// skip the canaries driven by another shard
+	cd, ok := c.claimCanary(cd)
+	if !ok {
+		return
+	}
+
+	// merge the canary template
+	cd, err = c.applyCanaryTemplate(cd)
+	if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).Errorf("%v", err)
+		return
+	}
+
+	// halt the canary if the spec is invalid, the same checks are run by the admission webhook,
+	// the canaries initialized before the checks were introduced are only warned about
+	if errs := validation.ValidateCanary(cd); len(errs) > 0 {
+		c.recordEventWarningf(cd, "Canary %s.%s spec is invalid: %v", name, namespace, errs.ToAggregate())
+		if cd.Status.LastAppliedSpec == "" {
+			return
+		}
+	}
+
 	// override the global provider if one is specified in the canary spec
 	provider := c.meshProvider
 	if cd.Spec.Provider != "" {
 		provider = cd.Spec.Provider
 	}
 
+	// Knative Services are routed by Knative
+	if cd.Spec.TargetRef.IsKnativeService() {
+		provider = flaggerv1.KnativeProvider
+	}
+
 	// init controller based on target kind
-	canaryController := c.canaryFactory.Controller(cd.Spec.TargetRef.Kind)
+	canaryController := c.canaryFactory.Controller(cd.Spec.TargetRef)
 	labelSelector, ports, err := canaryController.GetMetadata(cd)
 	if err != nil {
 		c.recordEventWarningf(cd, "%v", err)
@@ -137,6 +176,12 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		return
 	}
 
+	// roll back the primary to the revision requested by the user
+	if _, ok := cd.Annotations[flaggerv1.RollbackRevisionAnnotation]; ok {
+		c.rollbackToRevision(cd, canaryController, meshRouter)
+		return
+	}
+
 	// check for changes
 	shouldAdvance, err := c.shouldAdvance(cd, canaryController)
 	if err != nil {
@@ -145,10 +190,25 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	}
 
 	if !shouldAdvance {
+		// apply the changes that don't require an analysis
+		if err := canaryController.SyncIgnoredChanges(cd); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+		}
 		c.recorder.SetStatus(cd, cd.Status.Phase)
 		return
 	}
 
+	// apply the abort, promote and pause actions requested with the canary annotations
+	if ok := c.runManualActions(cd, canaryController, meshRouter); !ok {
+		return
+	}
+
+	// analyse the promoted primary during the bake time
+	if cd.Status.Phase == flaggerv1.CanaryPhaseBaking {
+		c.runBake(cd, canaryController)
+		return
+	}
+
 	// check gates
 	if isApproved := c.runConfirmRolloutHooks(cd, canaryController); !isApproved {
 		return
@@ -182,6 +242,12 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		return
 	}
 
+	// analyse the canary without shifting traffic
+	if cd.Status.Phase == flaggerv1.CanaryPhaseShadowing {
+		c.runShadow(cd, canaryController, meshRouter, mirrored)
+		return
+	}
+
 	// check if canary revision changed during analysis
 	if restart := c.hasCanaryRevisionChanged(cd, canaryController); restart {
 		c.recordEventInfof(cd, "New revision detected! Restarting analysis for %s.%s",
@@ -190,6 +256,10 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		// route all traffic back to primary
 		primaryWeight = 100
 		canaryWeight = 0
+		if err := canaryController.ScaleByWeight(cd, primaryWeight, canaryWeight); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return
+		}
 		if err := meshRouter.SetRoutes(cd, primaryWeight, canaryWeight, false); err != nil {
 			c.recordEventWarningf(cd, "%v", err)
 			return
@@ -205,6 +275,8 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		if err := canaryController.SyncStatus(cd, status); err != nil {
 			c.recordEventWarningf(cd, "%v", err)
 		}
+		c.publishEvent(sink.NewWeightEvent(cd, primaryWeight, canaryWeight))
+		c.publishEvent(sink.NewPhaseEvent(cd, flaggerv1.CanaryPhaseProgressing))
 		return
 	}
 
@@ -241,6 +313,7 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 				return
 			}
 			c.recorder.SetWeight(cd, 100, 0)
+			c.publishEvent(sink.NewWeightEvent(cd, 100, 0))
 		}
 
 		// update status phase
@@ -248,6 +321,7 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 			c.recordEventWarningf(cd, "%v", err)
 			return
 		}
+		c.publishEvent(sink.NewPhaseEvent(cd, flaggerv1.CanaryPhaseFinalising))
 
 		return
 	}
@@ -259,12 +333,19 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 			return
 		}
 
+		// keep analysing the primary if a bake time is set
+		if cd.GetBakeTime() > 0 {
+			c.startBake(cd, canaryController)
+			return
+		}
+
 		// set status to succeeded
 		if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhaseSucceeded); err != nil {
 			c.recordEventWarningf(cd, "%v", err)
 			return
 		}
 		c.recorder.SetStatus(cd, flaggerv1.CanaryPhaseSucceeded)
+		c.publishEvent(sink.NewPhaseEvent(cd, flaggerv1.CanaryPhaseSucceeded))
 		c.runPostRolloutHooks(cd, flaggerv1.CanaryPhaseSucceeded)
 		c.recordEventInfof(cd, "Promotion completed! Scaling down %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
 		c.alert(cd, "Canary analysis completed successfully, promotion finished.",
@@ -304,7 +385,9 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 			return
 		}
 	} else {
-		if ok := c.runAnalysis(cd); !ok {
+		ok := c.runAnalysis(cd)
+		c.publishEvent(sink.NewAnalysisEvent(cd, ok))
+		if !ok {
 			if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
 				c.recordEventWarningf(cd, "%v", err)
 			}
@@ -350,6 +433,7 @@ func (c *Controller) runCanary(canary *flaggerv1.Canary, canaryController canary
 
 	// increase traffic weight
 	if canaryWeight < maxWeight {
+		currentPrimaryWeight := primaryWeight
 		// If in "mirror" mode, do one step of mirroring before shifting traffic to canary.
 		// When mirroring, all requests go to primary and canary, but only responses from
 		// primary go back to the user.
@@ -377,17 +461,45 @@ func (c *Controller) runCanary(canary *flaggerv1.Canary, canaryController canary
 			}
 		}
 
+		// scale up the canary before shifting the traffic
+		if canary.GetProportionalScaling() != nil {
+			if err := canaryController.ScaleByWeight(canary, currentPrimaryWeight, canaryWeight); err != nil {
+				c.recordEventWarningf(canary, "%v", err)
+				return
+			}
+			if retriable, err := canaryController.IsCanaryReady(canary); !retriable {
+				c.recordEventWarningf(canary, "Rolling back %s.%s progress deadline exceeded %v",
+					canary.Name, canary.Namespace, err)
+				c.alert(canary, fmt.Sprintf("Progress deadline exceeded %v", err),
+					false, flaggerv1.SeverityError)
+				c.rollback(canary, canaryController, meshRouter)
+				return
+			} else if err != nil {
+				c.recordEventInfof(canary, "Waiting for %s.%s to scale up for canary weight %v: %v",
+					canary.Spec.TargetRef.Name, canary.Namespace, canaryWeight, err)
+				return
+			}
+		}
+
 		if err := meshRouter.SetRoutes(canary, primaryWeight, canaryWeight, mirrored); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
 			return
 		}
 
+		// scale down the primary after shifting the traffic
+		if canary.GetProportionalScaling() != nil {
+			if err := canaryController.ScaleByWeight(canary, primaryWeight, canaryWeight); err != nil {
+				c.recordEventWarningf(canary, "%v", err)
+			}
+		}
+
 		if err := canaryController.SetStatusWeight(canary, canaryWeight); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
 			return
 		}
 
 		c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
+		c.publishEvent(sink.NewWeightEvent(canary, primaryWeight, canaryWeight))
 		c.recordEventInfof(canary, "Advance %s.%s canary weight %v", canary.Name, canary.Namespace, canaryWeight)
 		return
 	}
@@ -412,6 +524,7 @@ func (c *Controller) runCanary(canary *flaggerv1.Canary, canaryController canary
 			c.recordEventWarningf(canary, "%v", err)
 			return
 		}
+		c.publishEvent(sink.NewPhaseEvent(canary, flaggerv1.CanaryPhasePromoting))
 	}
 }
 
@@ -426,6 +539,7 @@ func (c *Controller) runAB(canary *flaggerv1.Canary, canaryController canary.Con
 			return
 		}
 		c.recorder.SetWeight(canary, 0, 100)
+		c.publishEvent(sink.NewWeightEvent(canary, 0, 100))
 
 		if err := canaryController.SetStatusIterations(canary, canary.Status.Iterations+1); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
@@ -455,6 +569,7 @@ func (c *Controller) runAB(canary *flaggerv1.Canary, canaryController canary.Con
 			c.recordEventWarningf(canary, "%v", err)
 			return
 		}
+		c.publishEvent(sink.NewPhaseEvent(canary, flaggerv1.CanaryPhasePromoting))
 	}
 }
 
@@ -500,6 +615,7 @@ func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController can
 				return
 			}
 			c.recorder.SetWeight(canary, 0, 100)
+			c.publishEvent(sink.NewWeightEvent(canary, 0, 100))
 		}
 
 		// increment iterations
@@ -524,6 +640,7 @@ func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController can
 			c.recordEventWarningf(canary, "%v", err)
 			return
 		}
+		c.publishEvent(sink.NewPhaseEvent(canary, flaggerv1.CanaryPhasePromoting))
 	}
 
 }
@@ -532,7 +649,7 @@ func (c *Controller) runAnalysis(canary *flaggerv1.Canary) bool {
 	// run external checks
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == "" || webhook.Type == flaggerv1.RolloutHook {
-			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
+			err := c.callWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
 			if err != nil {
 				c.recordEventWarningf(canary, "Halt %s.%s advancement external check %s failed %v",
 					canary.Name, canary.Namespace, webhook.Name, err)
@@ -541,17 +658,9 @@ func (c *Controller) runAnalysis(canary *flaggerv1.Canary) bool {
 		}
 	}
 
-	ok := c.runBuiltinMetricChecks(canary)
-	if !ok {
-		return ok
-	}
-
-	ok = c.runMetricChecks(canary)
-	if !ok {
-		return ok
-	}
-
-	return true
+	ok := c.runBuiltinMetricChecks(canary) && c.runMetricChecks(canary)
+	c.syncMetricStatus(canary)
+	return ok
 }
 
 func (c *Controller) shouldSkipAnalysis(canary *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface) bool {
@@ -567,6 +676,7 @@ func (c *Controller) shouldSkipAnalysis(canary *flaggerv1.Canary, canaryControll
 		return false
 	}
 	c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
+	c.publishEvent(sink.NewWeightEvent(canary, primaryWeight, canaryWeight))
 
 	// copy spec and configs from canary to primary
 	c.recordEventInfof(canary, "Copying %s.%s template spec to %s-primary.%s",
@@ -590,6 +700,7 @@ func (c *Controller) shouldSkipAnalysis(canary *flaggerv1.Canary, canaryControll
 
 	// notify
 	c.recorder.SetStatus(canary, flaggerv1.CanaryPhaseSucceeded)
+	c.publishEvent(sink.NewPhaseEvent(canary, flaggerv1.CanaryPhaseSucceeded))
 	c.recordEventInfof(canary, "Promotion completed! Canary analysis was skipped for %s.%s",
 		canary.Spec.TargetRef.Name, canary.Namespace)
 	c.alert(canary, "Canary analysis was skipped, promotion finished.",
@@ -604,7 +715,15 @@ func (c *Controller) shouldAdvance(canary *flaggerv1.Canary, canaryController ca
 		canary.Status.Phase == flaggerv1.CanaryPhaseProgressing ||
 		canary.Status.Phase == flaggerv1.CanaryPhaseWaiting ||
 		canary.Status.Phase == flaggerv1.CanaryPhasePromoting ||
-		canary.Status.Phase == flaggerv1.CanaryPhaseFinalising {
+		canary.Status.Phase == flaggerv1.CanaryPhaseFinalising ||
+		canary.Status.Phase == flaggerv1.CanaryPhaseBaking ||
+		canary.Status.Phase == flaggerv1.CanaryPhaseShadowing {
+		return true, nil
+	}
+
+	// the revision that passed the shadow analysis is rolled out once the shadow mode is disabled
+	if canary.Status.Phase == flaggerv1.CanaryPhaseShadowSucceeded && !canary.IsShadow() &&
+		canary.Status.LastAppliedSpec != canary.Status.LastPromotedSpec {
 		return true, nil
 	}
 
@@ -629,14 +748,20 @@ func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryControlle
 	c.recorder.SetStatus(canary, canary.Status.Phase)
 	if canary.Status.Phase == flaggerv1.CanaryPhaseProgressing ||
 		canary.Status.Phase == flaggerv1.CanaryPhasePromoting ||
-		canary.Status.Phase == flaggerv1.CanaryPhaseFinalising {
+		canary.Status.Phase == flaggerv1.CanaryPhaseFinalising ||
+		canary.Status.Phase == flaggerv1.CanaryPhaseShadowing {
 		return true
 	}
 
-	var err error
-	canary, err = c.flaggerClient.FlaggerV1beta1().Canaries(canary.Namespace).Get(context.TODO(), canary.Name, metav1.GetOptions{})
+	name, namespace := canary.Name, canary.Namespace
+	canary, err := c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 	if err != nil {
-		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
+		c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).Errorf("%v", err)
+		return false
+	}
+	canary, err = c.applyCanaryTemplate(canary)
+	if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).Errorf("%v", err)
 		return false
 	}
 
@@ -646,6 +771,7 @@ func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryControlle
 			return false
 		}
 		c.recorder.SetStatus(canary, flaggerv1.CanaryPhaseInitialized)
+		c.publishEvent(sink.NewPhaseEvent(canary, flaggerv1.CanaryPhaseInitialized))
 		c.recordEventInfof(canary, "Initialization done! %s.%s", canary.Name, canary.Namespace)
 		c.alert(canary, fmt.Sprintf("New %s detected, initialization completed.", canary.Spec.TargetRef.Kind),
 			true, flaggerv1.SeverityInfo)
@@ -653,28 +779,36 @@ func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryControlle
 	}
 
 	if shouldAdvance {
+		phase := flaggerv1.CanaryPhaseProgressing
+		message := "New revision detected, starting canary analysis."
+		if canary.IsShadow() {
+			phase = flaggerv1.CanaryPhaseShadowing
+			message = "New revision detected, starting shadow analysis."
+		}
+
 		canaryPhaseProgressing := canary.DeepCopy()
-		canaryPhaseProgressing.Status.Phase = flaggerv1.CanaryPhaseProgressing
+		canaryPhaseProgressing.Status.Phase = phase
 		c.recordEventInfof(canaryPhaseProgressing, "New revision detected! Scaling up %s.%s", canaryPhaseProgressing.Spec.TargetRef.Name, canaryPhaseProgressing.Namespace)
-		c.alert(canaryPhaseProgressing, "New revision detected, starting canary analysis.",
-			true, flaggerv1.SeverityInfo)
+		c.alert(canaryPhaseProgressing, message, true, flaggerv1.SeverityInfo)
 
 		if err := canaryController.ScaleFromZero(canary); err != nil {
 			c.recordEventErrorf(canary, "%v", err)
 			return false
 		}
-		if err := canaryController.SyncStatus(canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing}); err != nil {
+		if err := canaryController.SyncStatus(canary, flaggerv1.CanaryStatus{Phase: phase}); err != nil {
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
 			return false
 		}
-		c.recorder.SetStatus(canary, flaggerv1.CanaryPhaseProgressing)
+		c.recorder.SetStatus(canary, phase)
+		c.publishEvent(sink.NewPhaseEvent(canary, phase))
 		return false
 	}
 	return false
 }
 
 func (c *Controller) hasCanaryRevisionChanged(canary *flaggerv1.Canary, canaryController canary.Controller) bool {
-	if canary.Status.Phase == flaggerv1.CanaryPhaseProgressing {
+	if canary.Status.Phase == flaggerv1.CanaryPhaseProgressing ||
+		canary.Status.Phase == flaggerv1.CanaryPhaseShadowing {
 		if diff, _ := canaryController.HasTargetChanged(canary); diff {
 			return true
 		}
@@ -685,6 +819,19 @@ func (c *Controller) hasCanaryRevisionChanged(canary *flaggerv1.Canary, canaryCo
 	return false
 }
 
+// restorePrimary scales the primary back to its replicas before the traffic is routed to it,
+// the canary keeps the replicas of its current weight until it's scaled down
+func (c *Controller) restorePrimary(canary *flaggerv1.Canary, canaryController canary.Controller) error {
+	if err := canaryController.ScaleByWeight(canary, 100, canary.Status.CanaryWeight); err != nil {
+		return err
+	}
+	if err := canaryController.IsPrimaryReady(canary); err != nil {
+		return fmt.Errorf("waiting for %s-primary.%s to scale up before rolling back: %w",
+			canary.Spec.TargetRef.Name, canary.Namespace, err)
+	}
+	return nil
+}
+
 func (c *Controller) rollback(canary *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface) {
 	if canary.Status.FailedChecks >= canary.GetAnalysisThreshold() {
 		c.recordEventWarningf(canary, "Rolling back %s.%s failed checks threshold reached %v",
@@ -696,6 +843,10 @@ func (c *Controller) rollback(canary *flaggerv1.Canary, canaryController canary.
 	// route all traffic back to primary
 	primaryWeight := 100
 	canaryWeight := 0
+	if err := c.restorePrimary(canary, canaryController); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+		return
+	}
 	if err := meshRouter.SetRoutes(canary, primaryWeight, canaryWeight, false); err != nil {
 		c.recordEventWarningf(canary, "%v", err)
 		return
@@ -707,6 +858,7 @@ func (c *Controller) rollback(canary *flaggerv1.Canary, canaryController canary.
 		canaryPhaseFailed.Name, canaryPhaseFailed.Namespace)
 
 	c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
+	c.publishEvent(sink.NewWeightEvent(canary, primaryWeight, canaryWeight))
 
 	// shutdown canary
 	if err := canaryController.ScaleToZero(canary); err != nil {
@@ -721,5 +873,6 @@ func (c *Controller) rollback(canary *flaggerv1.Canary, canaryController canary.
 	}
 
 	c.recorder.SetStatus(canary, flaggerv1.CanaryPhaseFailed)
+	c.publishEvent(sink.NewPhaseEvent(canary, flaggerv1.CanaryPhaseFailed))
 	c.runPostRolloutHooks(canary, flaggerv1.CanaryPhaseFailed)
 }
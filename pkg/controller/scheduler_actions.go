@@ -0,0 +1,82 @@
+package controller
+
+import (
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+	"github.com/weaveworks/flagger/pkg/router"
+	"github.com/weaveworks/flagger/pkg/sink"
+)
+
+// runManualActions applies the abort, promote and pause actions requested with the canary annotations,
+// it returns false if the canary advancement should be halted for this interval
+func (c *Controller) runManualActions(cd *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface) bool {
+	analysing := cd.Status.Phase == flaggerv1.CanaryPhaseProgressing || cd.Status.Phase == flaggerv1.CanaryPhaseWaiting ||
+		cd.Status.Phase == flaggerv1.CanaryPhaseShadowing
+
+	if _, ok := cd.Annotations[flaggerv1.AbortAnnotation]; ok {
+		defer c.removeAnnotation(cd, flaggerv1.AbortAnnotation)
+		if !analysing {
+			c.recordEventWarningf(cd, "Ignoring %s annotation, %s.%s analysis is not underway",
+				flaggerv1.AbortAnnotation, cd.Name, cd.Namespace)
+			return true
+		}
+
+		// the shadowed canary is not routed any traffic, there is nothing to roll back
+		if cd.Status.Phase == flaggerv1.CanaryPhaseShadowing {
+			c.recordEventWarningf(cd, "Shadow analysis failed! %s.%s analysis aborted", cd.Name, cd.Namespace)
+			c.finishShadow(cd, canaryController, meshRouter, cd.GetAnalysis().Mirror, flaggerv1.CanaryPhaseShadowFailed)
+			return false
+		}
+
+		c.recordEventWarningf(cd, "Rolling back %s.%s analysis aborted", cd.Name, cd.Namespace)
+		c.alert(cd, "Canary analysis aborted", false, flaggerv1.SeverityWarn)
+		c.rollback(cd, canaryController, meshRouter)
+		return false
+	}
+
+	if _, ok := cd.Annotations[flaggerv1.PromoteAnnotation]; ok {
+		if cd.Status.Phase != flaggerv1.CanaryPhaseProgressing {
+			c.recordEventWarningf(cd, "Ignoring %s annotation, %s.%s analysis is not progressing",
+				flaggerv1.PromoteAnnotation, cd.Name, cd.Namespace)
+			c.removeAnnotation(cd, flaggerv1.PromoteAnnotation)
+			return true
+		}
+
+		// the annotation is kept until the canary is ready to be promoted
+		if _, err := canaryController.IsCanaryReady(cd); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return false
+		}
+
+		c.recordEventInfof(cd, "Promotion requested! Copying %s.%s template spec to %s-primary.%s",
+			cd.Spec.TargetRef.Name, cd.Namespace, cd.Spec.TargetRef.Name, cd.Namespace)
+		if err := canaryController.Promote(cd); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return false
+		}
+		if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhasePromoting); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return false
+		}
+		c.publishEvent(sink.NewPhaseEvent(cd, flaggerv1.CanaryPhasePromoting))
+		c.alert(cd, "Canary promoted without completing the analysis", false, flaggerv1.SeverityWarn)
+		c.removeAnnotation(cd, flaggerv1.PromoteAnnotation)
+		return false
+	}
+
+	if cd.Annotations[flaggerv1.PauseAnnotation] == "true" {
+		c.recordEventInfof(cd, "Halt %s.%s advancement paused", cd.Name, cd.Namespace)
+		return false
+	}
+
+	// the fleet hold only delays the start of an analysis
+	if fleet, ok := cd.Annotations[flaggerv1.FleetHoldAnnotation]; ok {
+		switch cd.Status.Phase {
+		case flaggerv1.CanaryPhaseInitialized, flaggerv1.CanaryPhaseSucceeded, flaggerv1.CanaryPhaseFailed:
+			c.recordEventInfof(cd, "Halt %s.%s advancement held by canary fleet %s", cd.Name, cd.Namespace, fleet)
+			return false
+		}
+	}
+
+	return true
+}
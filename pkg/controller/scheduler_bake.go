@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"fmt"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+	"github.com/weaveworks/flagger/pkg/sink"
+)
+
+// startBake moves a promoted canary to the baking phase
+func (c *Controller) startBake(cd *flaggerv1.Canary, canaryController canary.Controller) {
+	if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhaseBaking); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+	c.recorder.SetStatus(cd, flaggerv1.CanaryPhaseBaking)
+	c.publishEvent(sink.NewPhaseEvent(cd, flaggerv1.CanaryPhaseBaking))
+	c.recordEventInfof(cd, "Promotion completed! Baking %s-primary.%s for %v",
+		cd.Spec.TargetRef.Name, cd.Namespace, cd.GetBakeTime())
+}
+
+// runBake runs the metric checks against the primary during the bake time,
+// when the checks fail or a rollback is requested the primary is reverted to the previous revision
+func (c *Controller) runBake(cd *flaggerv1.Canary, canaryController canary.Controller) {
+	c.recorder.SetStatus(cd, cd.Status.Phase)
+
+	if ok := c.runRollbackHooks(cd, cd.Status.Phase); ok {
+		c.recordEventWarningf(cd, "Reverting %s.%s manual webhook invoked", cd.Name, cd.Namespace)
+		c.alert(cd, "Reverting primary manual webhook invoked", false, flaggerv1.SeverityWarn)
+		c.revertPrimary(cd, canaryController)
+		return
+	}
+
+	if cd.Status.FailedChecks >= cd.GetAnalysisThreshold() {
+		c.recordEventWarningf(cd, "Reverting %s.%s failed checks threshold reached %v during bake time",
+			cd.Name, cd.Namespace, cd.Status.FailedChecks)
+		c.alert(cd, fmt.Sprintf("Failed checks threshold reached %v during bake time", cd.Status.FailedChecks),
+			false, flaggerv1.SeverityError)
+		c.revertPrimary(cd, canaryController)
+		return
+	}
+
+	if cd.Status.Iterations >= cd.GetBakeIterations() {
+		if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhaseSucceeded); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return
+		}
+		c.recorder.SetStatus(cd, flaggerv1.CanaryPhaseSucceeded)
+		c.publishEvent(sink.NewPhaseEvent(cd, flaggerv1.CanaryPhaseSucceeded))
+		c.runPostRolloutHooks(cd, flaggerv1.CanaryPhaseSucceeded)
+		c.recordEventInfof(cd, "Bake completed! %s-primary.%s passed all checks", cd.Spec.TargetRef.Name, cd.Namespace)
+		c.alert(cd, "Canary analysis completed successfully, promotion finished.",
+			false, flaggerv1.SeverityInfo)
+		return
+	}
+
+	ok := c.runBuiltinMetricChecks(cd) && c.runMetricChecks(cd)
+	c.syncMetricStatus(cd)
+	c.publishEvent(sink.NewAnalysisEvent(cd, ok))
+	if !ok {
+		if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+		}
+		return
+	}
+
+	if err := canaryController.SetStatusIterations(cd, cd.Status.Iterations+1); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+	c.recordEventInfof(cd, "Advance %s.%s bake iteration %v/%v",
+		cd.Name, cd.Namespace, cd.Status.Iterations+1, cd.GetBakeIterations())
+}
+
+// revertPrimary restores the primary pod template promoted before the current one and marks the canary as failed
+func (c *Controller) revertPrimary(cd *flaggerv1.Canary, canaryController canary.Controller) {
+	if err := canaryController.RevertPrimary(cd, 0); err != nil {
+		c.recordEventErrorf(cd, "Reverting %s-primary.%s failed: %v", cd.Spec.TargetRef.Name, cd.Namespace, err)
+	} else {
+		c.recordEventWarningf(cd, "Bake failed! Reverted %s-primary.%s to the previous revision",
+			cd.Spec.TargetRef.Name, cd.Namespace)
+	}
+
+	if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhaseFailed); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+	c.recorder.SetStatus(cd, flaggerv1.CanaryPhaseFailed)
+	c.publishEvent(sink.NewPhaseEvent(cd, flaggerv1.CanaryPhaseFailed))
+	c.runPostRolloutHooks(cd, flaggerv1.CanaryPhaseFailed)
+}
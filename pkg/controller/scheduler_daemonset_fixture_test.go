@@ -70,9 +70,11 @@ func newDaemonSetFixture(c *flaggerv1.Canary) daemonSetFixture {
 	flaggerInformerFactory := informers.NewSharedInformerFactory(flaggerClient, 0)
 
 	fi := Informers{
-		CanaryInformer: flaggerInformerFactory.Flagger().V1beta1().Canaries(),
-		MetricInformer: flaggerInformerFactory.Flagger().V1beta1().MetricTemplates(),
-		AlertInformer:  flaggerInformerFactory.Flagger().V1beta1().AlertProviders(),
+		CanaryInformer:                flaggerInformerFactory.Flagger().V1beta1().Canaries(),
+		MetricInformer:                flaggerInformerFactory.Flagger().V1beta1().MetricTemplates(),
+		AlertInformer:                 flaggerInformerFactory.Flagger().V1beta1().AlertProviders(),
+		CanaryTemplateInformer:        flaggerInformerFactory.Flagger().V1beta1().CanaryTemplates(),
+		ClusterCanaryTemplateInformer: flaggerInformerFactory.Flagger().V1beta1().ClusterCanaryTemplates(),
 	}
 
 	// init router
@@ -114,7 +116,7 @@ func newDaemonSetFixture(c *flaggerv1.Canary) daemonSetFixture {
 
 	return daemonSetFixture{
 		canary:        c,
-		deployer:      canaryFactory.Controller("DaemonSet"),
+		deployer:      canaryFactory.Controller(flaggerv1.CrossNamespaceObjectReference{Kind: "DaemonSet"}),
 		logger:        logger,
 		flaggerClient: flaggerClient,
 		meshClient:    flaggerClient,
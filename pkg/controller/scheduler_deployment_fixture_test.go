@@ -76,6 +76,7 @@ func newDeploymentFixture(c *flaggerv1.Canary) fixture {
 		c,
 		newDeploymentTestMetricTemplate(),
 		newDeploymentTestAlertProvider(),
+		newDeploymentTestCanaryTemplate(),
 	)
 
 	// init Kubernetes clientset and register objects
@@ -98,9 +99,11 @@ func newDeploymentFixture(c *flaggerv1.Canary) fixture {
 	flaggerInformerFactory := informers.NewSharedInformerFactory(flaggerClient, 0)
 
 	fi := Informers{
-		CanaryInformer: flaggerInformerFactory.Flagger().V1beta1().Canaries(),
-		MetricInformer: flaggerInformerFactory.Flagger().V1beta1().MetricTemplates(),
-		AlertInformer:  flaggerInformerFactory.Flagger().V1beta1().AlertProviders(),
+		CanaryInformer:                flaggerInformerFactory.Flagger().V1beta1().Canaries(),
+		MetricInformer:                flaggerInformerFactory.Flagger().V1beta1().MetricTemplates(),
+		AlertInformer:                 flaggerInformerFactory.Flagger().V1beta1().AlertProviders(),
+		CanaryTemplateInformer:        flaggerInformerFactory.Flagger().V1beta1().CanaryTemplates(),
+		ClusterCanaryTemplateInformer: flaggerInformerFactory.Flagger().V1beta1().ClusterCanaryTemplates(),
 	}
 
 	// init router
@@ -137,12 +140,13 @@ func newDeploymentFixture(c *flaggerv1.Canary) fixture {
 	ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Add(c)
 	ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(newDeploymentTestMetricTemplate())
 	ctrl.flaggerInformers.AlertInformer.Informer().GetIndexer().Add(newDeploymentTestAlertProvider())
+	ctrl.flaggerInformers.CanaryTemplateInformer.Informer().GetIndexer().Add(newDeploymentTestCanaryTemplate())
 
 	meshRouter := rf.MeshRouter("istio")
 
 	return fixture{
 		canary:        c,
-		deployer:      canaryFactory.Controller("Deployment"),
+		deployer:      canaryFactory.Controller(flaggerv1.CrossNamespaceObjectReference{Kind: "Deployment"}),
 		logger:        logger,
 		flaggerClient: flaggerClient,
 		meshClient:    flaggerClient,
@@ -321,6 +325,30 @@ func newDeploymentTestCanary() *flaggerv1.Canary {
 	return cd
 }
 
+func newDeploymentTestCanaryTemplateRef() *flaggerv1.Canary {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis = &flaggerv1.CanaryAnalysis{
+		StepWeight: 20,
+	}
+	cd.Spec.TemplateRef = &flaggerv1.CanaryTemplateRef{
+		Name: "podinfo-template",
+	}
+	return cd
+}
+
+func newDeploymentTestCanaryTemplate() *flaggerv1.CanaryTemplate {
+	return &flaggerv1.CanaryTemplate{
+		TypeMeta: metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo-template",
+		},
+		Spec: flaggerv1.CanaryTemplateSpec{
+			Analysis: newDeploymentTestCanary().Spec.Analysis,
+		},
+	}
+}
+
 func newDeploymentTestCanaryMirror() *flaggerv1.Canary {
 	cd := newDeploymentTestCanary()
 	cd.Spec.Analysis.Mirror = true
@@ -11,11 +11,19 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	k8sTesting "k8s.io/client-go/testing"
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
 	"github.com/weaveworks/flagger/pkg/notifier"
+	"github.com/weaveworks/flagger/pkg/router"
 )
 
 func TestScheduler_DeploymentInit(t *testing.T) {
@@ -97,6 +105,65 @@ func TestScheduler_DeploymentRollback(t *testing.T) {
 	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
 }
 
+// rollbackRecorder records the scaling calls made during a rollback
+type rollbackRecorder struct {
+	canary.Controller
+	calls        *[]string
+	primaryReady error
+}
+
+// rollbackRouterRecorder records the routing calls made during a rollback
+type rollbackRouterRecorder struct {
+	router.Interface
+	calls *[]string
+}
+
+func (r *rollbackRecorder) ScaleByWeight(_ *flaggerv1.Canary, primaryWeight int, canaryWeight int) error {
+	*r.calls = append(*r.calls, fmt.Sprintf("ScaleByWeight %d/%d", primaryWeight, canaryWeight))
+	return nil
+}
+
+func (r *rollbackRecorder) IsPrimaryReady(_ *flaggerv1.Canary) error {
+	*r.calls = append(*r.calls, "IsPrimaryReady")
+	return r.primaryReady
+}
+
+func (r *rollbackRouterRecorder) SetRoutes(_ *flaggerv1.Canary, primaryWeight int, canaryWeight int, _ bool) error {
+	*r.calls = append(*r.calls, fmt.Sprintf("SetRoutes %d/%d", primaryWeight, canaryWeight))
+	return nil
+}
+
+func (r *rollbackRecorder) ScaleToZero(_ *flaggerv1.Canary) error {
+	*r.calls = append(*r.calls, "ScaleToZero")
+	return nil
+}
+
+func TestScheduler_DeploymentRollbackOrder(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd.Status = flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing, CanaryWeight: 60, FailedChecks: 10}
+
+	// the traffic stays on the canary until the primary is scaled back up
+	var calls []string
+	recorder := &rollbackRecorder{
+		Controller:   mocks.ctrl.canaryFactory.Controller(cd.Spec.TargetRef),
+		calls:        &calls,
+		primaryReady: fmt.Errorf("waiting for rollout to finish"),
+	}
+	routerRecorder := &rollbackRouterRecorder{Interface: mocks.router, calls: &calls}
+	mocks.ctrl.rollback(cd, recorder, routerRecorder)
+	assert.Equal(t, []string{"ScaleByWeight 100/60", "IsPrimaryReady"}, calls)
+
+	calls, recorder.primaryReady = nil, nil
+	mocks.ctrl.rollback(cd, recorder, routerRecorder)
+	assert.Equal(t, []string{"ScaleByWeight 100/60", "IsPrimaryReady", "SetRoutes 100/0", "ScaleToZero"}, calls)
+}
+
 func TestScheduler_DeploymentSkipAnalysis(t *testing.T) {
 	mocks := newDeploymentFixture(nil)
 	// initializing
@@ -622,3 +689,732 @@ func TestScheduler_DeploymentAlerts(t *testing.T) {
 	// initialization done - now send alert
 	mocks.ctrl.advanceCanary("podinfo", "default")
 }
+
+func TestScheduler_DeploymentBakeTime(t *testing.T) {
+	canary := newDeploymentTestCanary()
+	canary.Spec.Analysis.BakeTime = "2m"
+	mocks := newDeploymentFixture(canary)
+	promoteDeploymentToBake(t, mocks)
+
+	// run bake iterations
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseBaking, c.Status.Phase)
+	assert.Equal(t, 2, c.Status.Iterations)
+
+	// finish bake
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, c.Status.Phase)
+	assert.Equal(t, c.Status.LastAppliedSpec, c.Status.LastPromotedSpec)
+
+	primaryDep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, newDeploymentTestDeploymentV2().Spec.Template.Spec.Containers[0].Image,
+		primaryDep.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestScheduler_DeploymentBakeTimeRevert(t *testing.T) {
+	canary := newDeploymentTestCanary()
+	canary.Spec.Analysis.BakeTime = "2m"
+	mocks := newDeploymentFixture(canary)
+	promoteDeploymentToBake(t, mocks)
+
+	// update failed checks to max
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	err = mocks.deployer.SetStatusFailedChecks(c, 10)
+	require.NoError(t, err)
+
+	// revert primary
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+	assert.NotEqual(t, c.Status.LastAppliedSpec, c.Status.LastPromotedSpec)
+
+	primaryDep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, newDeploymentTestDeployment().Spec.Template.Spec.Containers[0].Image,
+		primaryDep.Spec.Template.Spec.Containers[0].Image)
+
+	// the canary revision is not analysed again
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+}
+
+// promoteDeploymentToBake runs the canary analysis of a new revision until the primary is baking
+func promoteDeploymentToBake(t *testing.T, mocks fixture) {
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect pod spec changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	err = mocks.router.SetRoutes(mocks.canary, 60, 40, false)
+	require.NoError(t, err)
+
+	// advance, promote, finalise and start baking
+	for i := 0; i < 4; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default")
+	}
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, flaggerv1.CanaryPhaseBaking, c.Status.Phase)
+	require.Equal(t, 0, c.Status.Iterations)
+	require.Equal(t, 0, c.Status.FailedChecks)
+}
+
+func TestScheduler_DeploymentRollbackToRevision(t *testing.T) {
+	canary := newDeploymentTestCanary()
+	canary.Spec.Analysis.BakeTime = "2m"
+	mocks := newDeploymentFixture(canary)
+	promoteDeploymentToBake(t, mocks)
+
+	image1 := newDeploymentTestDeployment().Spec.Template.Spec.Containers[0].Image
+	image2 := newDeploymentTestDeploymentV2().Spec.Template.Spec.Containers[0].Image
+
+	setRollbackAnnotation := func(revision string) {
+		c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		cd := c.DeepCopy()
+		cd.Annotations = map[string]string{flaggerv1.RollbackRevisionAnnotation: revision}
+		_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+		require.NoError(t, err)
+	}
+
+	getPrimaryImage := func() string {
+		primaryDep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+		require.NoError(t, err)
+		return primaryDep.Spec.Template.Spec.Containers[0].Image
+	}
+
+	// roll back while baking
+	setRollbackAnnotation("1")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+	assert.NotContains(t, c.Annotations, flaggerv1.RollbackRevisionAnnotation)
+	assert.Equal(t, image1, getPrimaryImage())
+
+	// roll forward while idle
+	setRollbackAnnotation("2")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+	assert.NotContains(t, c.Annotations, flaggerv1.RollbackRevisionAnnotation)
+	assert.Equal(t, image2, getPrimaryImage())
+
+	// unknown revisions are ignored
+	setRollbackAnnotation("10")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, c.Annotations, flaggerv1.RollbackRevisionAnnotation)
+	assert.Equal(t, image2, getPrimaryImage())
+}
+
+func TestScheduler_DeploymentRollbackToRevisionNotReady(t *testing.T) {
+	for _, revision := range []string{"0", "1"} {
+		t.Run(revision, func(t *testing.T) {
+			canary := newDeploymentTestCanary()
+			canary.Spec.Analysis.BakeTime = "2m"
+			mocks := newDeploymentFixture(canary)
+			promoteDeploymentToBake(t, mocks)
+
+			image1 := newDeploymentTestDeployment().Spec.Template.Spec.Containers[0].Image
+
+			// the primary doesn't become ready right away after the revert
+			primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+			require.NoError(t, err)
+			primary.Status = appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 0}
+			_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), primary, metav1.UpdateOptions{})
+			require.NoError(t, err)
+
+			c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+			require.NoError(t, err)
+			cd := c.DeepCopy()
+			cd.Annotations = map[string]string{flaggerv1.RollbackRevisionAnnotation: revision}
+			_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+			require.NoError(t, err)
+
+			// the primary is reverted once while waiting for it to become ready
+			for i := 0; i < 2; i++ {
+				mocks.ctrl.advanceCanary("podinfo", "default")
+
+				primary, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, image1, primary.Spec.Template.Spec.Containers[0].Image)
+
+				c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, flaggerv1.CanaryPhaseBaking, c.Status.Phase)
+				assert.Equal(t, revision, c.Status.RollbackRevision)
+				assert.Contains(t, c.Annotations, flaggerv1.RollbackRevisionAnnotation)
+			}
+
+			mocks.makePrimaryReady(t)
+			mocks.ctrl.advanceCanary("podinfo", "default")
+
+			c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+			assert.Empty(t, c.Status.RollbackRevision)
+			assert.NotContains(t, c.Annotations, flaggerv1.RollbackRevisionAnnotation)
+
+			primary, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, image1, primary.Spec.Template.Spec.Containers[0].Image)
+		})
+	}
+}
+
+func TestScheduler_DeploymentRollbackToRevisionConfigs(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.SkipAnalysis = true
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update the pod spec and the config map
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	config2 := newDeploymentTestConfigMapV2()
+	_, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Update(context.TODO(), config2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// promote
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, flaggerv1.CanaryPhaseSucceeded, c.Status.Phase)
+
+	getPrimaryConfig := func() map[string]string {
+		config, err := mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "podinfo-config-env-primary", metav1.GetOptions{})
+		require.NoError(t, err)
+		return config.Data
+	}
+	require.Equal(t, config2.Data, getPrimaryConfig())
+
+	// roll back to the previous revision
+	cd = c.DeepCopy()
+	cd.Annotations = map[string]string{flaggerv1.RollbackRevisionAnnotation: "0"}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, c.Annotations, flaggerv1.RollbackRevisionAnnotation)
+	assert.Equal(t, newDeploymentTestConfigMap().Data, getPrimaryConfig())
+}
+
+func TestScheduler_DeploymentIgnoredChanges(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.IgnoredChanges = &flaggerv1.CanaryIgnoredChanges{
+		AnnotationPrefixes: []string{"logging.example.com/"},
+		Env:                []string{"LOG_LEVEL", "LOG_FORMAT"},
+	}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update the ignored paths
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	dep.Spec.Template.Annotations = map[string]string{"logging.example.com/level": "debug"}
+	logFormat := corev1.EnvVar{
+		Name: "LOG_FORMAT",
+		ValueFrom: &corev1.EnvVarSource{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "podinfo-config-env"},
+				Key:                  "color",
+			},
+		},
+	}
+	dep.Spec.Template.Spec.Containers[0].Env = append(dep.Spec.Template.Spec.Containers[0].Env,
+		corev1.EnvVar{Name: "LOG_LEVEL", Value: "debug"}, logFormat)
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// apply the changes to the primary without analysis
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseInitialized, c.Status.Phase)
+
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "debug", primary.Spec.Template.Annotations["logging.example.com/level"])
+	assert.Contains(t, primary.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "LOG_LEVEL", Value: "debug"})
+	assert.Equal(t, "quay.io/stefanprodan/podinfo:1.2.0", primary.Spec.Template.Spec.Containers[0].Image)
+
+	// the ignored env vars read the primary copy of the ConfigMap
+	logFormat.ValueFrom.ConfigMapKeyRef.Name = "podinfo-config-env-primary"
+	assert.Contains(t, primary.Spec.Template.Spec.Containers[0].Env, logFormat)
+
+	// significant changes still go through the canary analysis
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+}
+
+func TestScheduler_DeploymentShadowAnalysis(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Shadow = true
+	cd.Spec.Analysis.Iterations = 2
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseShadowing, c.Status.Phase)
+
+	// pre-rollout hooks and analysis iterations
+	for i := 0; i < 3; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default")
+
+		primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+		require.NoError(t, err)
+		assert.Equal(t, 100, primaryWeight)
+		assert.Equal(t, 0, canaryWeight)
+	}
+
+	// verdict without promotion
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseShadowSucceeded, c.Status.Phase)
+
+	var shadowCondition *flaggerv1.CanaryCondition
+	for i := range c.Status.Conditions {
+		if c.Status.Conditions[i].Type == flaggerv1.ShadowAnalysisType {
+			shadowCondition = &c.Status.Conditions[i]
+		}
+	}
+	require.NotNil(t, shadowCondition)
+	assert.Equal(t, corev1.ConditionTrue, shadowCondition.Status)
+
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "quay.io/stefanprodan/podinfo:1.2.0", primary.Spec.Template.Spec.Containers[0].Image)
+
+	canary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), *canary.Spec.Replicas)
+
+	// the shadowed revision is not analysed again
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseShadowSucceeded, c.Status.Phase)
+
+	// the shadowed revision is rolled out once the shadow mode is disabled
+	c.Spec.Analysis.Shadow = false
+	c.Spec.Analysis.Iterations = 0
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+}
+
+func TestScheduler_DeploymentShadowAbort(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Shadow = true
+	cd.Spec.Analysis.Iterations = 5
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// abort the shadow analysis
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, flaggerv1.CanaryPhaseShadowing, c.Status.Phase)
+	c.Annotations = map[string]string{flaggerv1.AbortAnnotation: "true"}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseShadowFailed, c.Status.Phase)
+	assert.NotContains(t, c.Annotations, flaggerv1.AbortAnnotation)
+
+	canary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), *canary.Spec.Replicas)
+}
+
+func TestScheduler_DeploymentCanaryTemplate(t *testing.T) {
+	mocks := newDeploymentFixture(newDeploymentTestCanaryTemplateRef())
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance with the step weight of the canary
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, primaryWeight)
+	assert.Equal(t, 40, canaryWeight)
+
+	// promote after reaching the max weight of the template
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhasePromoting, c.Status.Phase)
+}
+
+func TestScheduler_DeploymentInvalidSpec(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.StepWeight = 60
+	mocks := newDeploymentFixture(cd)
+
+	// halt before initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.True(t, errors.IsNotFound(err))
+}
+
+func TestScheduler_DeploymentInvalidSpecInitialized(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// the canary was admitted before the spec checks
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd.Spec.Analysis.StepWeight = 60
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes instead of halting
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+}
+
+func TestScheduler_DeploymentManualActions(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	setAnnotation := func(key, value string) {
+		c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		cd := c.DeepCopy()
+		cd.Annotations = map[string]string{}
+		if key != "" {
+			cd.Annotations[key] = value
+		}
+		_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+		require.NoError(t, err)
+	}
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance and run the analysis
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	weight := c.Status.CanaryWeight
+	require.Greater(t, weight, 0)
+	require.NotEmpty(t, c.Status.Metrics)
+	assert.Equal(t, "request-success-rate", c.Status.Metrics[0].Name)
+
+	// pause
+	setAnnotation(flaggerv1.PauseAnnotation, "true")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, weight, c.Status.CanaryWeight)
+
+	// promote without completing the analysis
+	setAnnotation(flaggerv1.PromoteAnnotation, "true")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhasePromoting, c.Status.Phase)
+	assert.NotContains(t, c.Annotations, flaggerv1.PromoteAnnotation)
+
+	primaryDep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, dep2.Spec.Template.Spec.Containers[0].Image, primaryDep.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestScheduler_DeploymentAbort(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// abort
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd := c.DeepCopy()
+	cd.Annotations = map[string]string{flaggerv1.AbortAnnotation: "true"}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+	assert.NotContains(t, c.Annotations, flaggerv1.AbortAnnotation)
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, primaryWeight)
+	assert.Equal(t, 0, canaryWeight)
+}
+
+func TestScheduler_DeploymentFleetHold(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// hold
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd := c.DeepCopy()
+	cd.Annotations = map[string]string{flaggerv1.FleetHoldAnnotation: "flagger-system/podinfo"}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// the analysis doesn't start while the fleet holds the canary
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseInitialized, c.Status.Phase)
+
+	// release
+	cd = c.DeepCopy()
+	delete(cd.Annotations, flaggerv1.FleetHoldAnnotation)
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+}
+
+func TestScheduler_DeploymentProportionalScalingDeadline(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.AutoscalerRef = nil
+	cd.Spec.Analysis.ProportionalScaling = &flaggerv1.CanaryProportionalScaling{}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// the canary exceeds its progress deadline when scaled up for the next weight
+	mocks.kubeClient.(*fake.Clientset).PrependReactor("update", "deployments", func(action k8sTesting.Action) (bool, runtime.Object, error) {
+		dep := action.(k8sTesting.UpdateAction).GetObject().(*appsv1.Deployment)
+		if dep.Name == "podinfo" {
+			dep.Status.Conditions = []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Reason: "ProgressDeadlineExceeded"},
+			}
+		}
+		return false, nil, nil
+	})
+
+	// rollback
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+	assert.Equal(t, 0, c.Status.CanaryWeight)
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -14,28 +15,7 @@ import (
 )
 
 func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
-	// override the global provider if one is specified in the canary spec
-	var metricsProvider string
-	// set the metrics provider to Crossover Prometheus when Crossover is the mesh provider
-	// For example, `crossover` metrics provider should be used for `smi:crossover` mesh provider
-	if strings.Contains(c.meshProvider, "crossover") {
-		metricsProvider = "crossover"
-	} else {
-		metricsProvider = c.meshProvider
-	}
-
-	if canary.Spec.Provider != "" {
-		metricsProvider = canary.Spec.Provider
-
-		// set the metrics provider to Linkerd Prometheus when Linkerd is the default mesh provider
-		if strings.Contains(c.meshProvider, "linkerd") {
-			metricsProvider = "linkerd"
-		}
-	}
-	// set the metrics provider to query Prometheus for the canary Kubernetes service if the canary target is Service
-	if canary.Spec.TargetRef.Kind == "Service" {
-		metricsProvider = metricsProvider + MetricsProviderServiceSuffix
-	}
+	metricsProvider := c.builtinMetricsProvider(canary)
 
 	// create observer based on the mesh provider
 	observerFactory := c.observerFactory
@@ -58,7 +38,7 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 		}
 
 		if metric.Name == "request-success-rate" {
-			val, err := observer.GetRequestSuccessRate(toMetricModel(canary, metric.Interval))
+			val, err := observer.GetRequestSuccessRate(c.metricModel(canary, metric.Interval))
 			if err != nil {
 				if errors.Is(err, providers.ErrNoValuesFound) {
 					c.recordEventWarningf(canary,
@@ -69,6 +49,7 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 				}
 				return false
 			}
+			setMetricStatus(canary, metric.Name, fmt.Sprintf("%.2f", val))
 
 			if metric.ThresholdRange != nil {
 				tr := *metric.ThresholdRange
@@ -90,7 +71,7 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 		}
 
 		if metric.Name == "request-duration" {
-			val, err := observer.GetRequestDuration(toMetricModel(canary, metric.Interval))
+			val, err := observer.GetRequestDuration(c.metricModel(canary, metric.Interval))
 			if err != nil {
 				if errors.Is(err, providers.ErrNoValuesFound) {
 					c.recordEventWarningf(canary, "Halt advancement no values found for %s metric %s probably %s.%s is not receiving traffic",
@@ -100,6 +81,7 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 				}
 				return false
 			}
+			setMetricStatus(canary, metric.Name, val.String())
 			if metric.ThresholdRange != nil {
 				tr := *metric.ThresholdRange
 				if tr.Min != nil && val < time.Duration(*tr.Min)*time.Millisecond {
@@ -131,6 +113,7 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 				}
 				return false
 			}
+			setMetricStatus(canary, metric.Name, fmt.Sprintf("%.2f", val))
 			if metric.ThresholdRange != nil {
 				tr := *metric.ThresholdRange
 				if tr.Min != nil && val < *tr.Min {
@@ -154,6 +137,45 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 	return true
 }
 
+// builtinMetricsProvider returns the observer used to query the request-success-rate and request-duration metrics
+func (c *Controller) builtinMetricsProvider(canary *flaggerv1.Canary) string {
+	// override the global provider if one is specified in the canary spec
+	var metricsProvider string
+	// set the metrics provider to Crossover Prometheus when Crossover is the mesh provider
+	// For example, `crossover` metrics provider should be used for `smi:crossover` mesh provider
+	if strings.Contains(c.meshProvider, "crossover") {
+		metricsProvider = "crossover"
+	} else {
+		metricsProvider = c.meshProvider
+	}
+
+	if canary.Spec.Provider != "" {
+		metricsProvider = canary.Spec.Provider
+
+		// set the metrics provider to Linkerd Prometheus when Linkerd is the default mesh provider
+		if strings.Contains(c.meshProvider, "linkerd") {
+			metricsProvider = "linkerd"
+		}
+	}
+	// set the metrics provider to query the Knative queue-proxy metrics if the canary target is a Knative Service
+	if canary.Spec.TargetRef.IsKnativeService() {
+		metricsProvider = flaggerv1.KnativeProvider
+	} else if canary.Spec.TargetRef.Kind == "Service" {
+		// set the metrics provider to query Prometheus for the canary Kubernetes service if the canary target is Service
+		metricsProvider = metricsProvider + MetricsProviderServiceSuffix
+	}
+	return metricsProvider
+}
+
+// metricsProvider returns the client used to run the metric template queries
+func (c *Controller) metricsProvider(metricInterval string, provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte) (providers.Interface, error) {
+	if c.providerFactory != nil {
+		return c.providerFactory(metricInterval, provider, credentials)
+	}
+	factory := providers.Factory{}
+	return factory.Provider(metricInterval, provider, credentials)
+}
+
 func (c *Controller) runMetricChecks(canary *flaggerv1.Canary) bool {
 	for _, metric := range canary.GetAnalysis().Metrics {
 		if metric.TemplateRef != nil {
@@ -179,15 +201,14 @@ func (c *Controller) runMetricChecks(canary *flaggerv1.Canary) bool {
 				credentials = secret.Data
 			}
 
-			factory := providers.Factory{}
-			provider, err := factory.Provider(metric.Interval, template.Spec.Provider, credentials)
+			provider, err := c.metricsProvider(metric.Interval, template.Spec.Provider, credentials)
 			if err != nil {
 				c.recordEventErrorf(canary, "Metric template %s.%s provider %s error: %v",
 					metric.TemplateRef.Name, namespace, template.Spec.Provider.Type, err)
 				return false
 			}
 
-			query, err := observers.RenderQuery(template.Spec.Query, toMetricModel(canary, metric.Interval))
+			query, err := observers.RenderQuery(template.Spec.Query, c.metricModel(canary, metric.Interval))
 			if err != nil {
 				c.recordEventErrorf(canary, "Metric template %s.%s query render error: %v",
 					metric.TemplateRef.Name, namespace, err)
@@ -204,6 +225,7 @@ func (c *Controller) runMetricChecks(canary *flaggerv1.Canary) bool {
 				}
 				return false
 			}
+			setMetricStatus(canary, metric.Name, fmt.Sprintf("%.2f", val))
 
 			if metric.ThresholdRange != nil {
 				tr := *metric.ThresholdRange
@@ -228,6 +250,54 @@ func (c *Controller) runMetricChecks(canary *flaggerv1.Canary) bool {
 	return true
 }
 
+// setMetricStatus records the measured value of a metric in the canary status
+func setMetricStatus(canary *flaggerv1.Canary, name string, value string) {
+	now := metav1.Now()
+	for i := range canary.Status.Metrics {
+		if canary.Status.Metrics[i].Name == name {
+			canary.Status.Metrics[i].Value = value
+			canary.Status.Metrics[i].LastUpdateTime = now
+			return
+		}
+	}
+	canary.Status.Metrics = append(canary.Status.Metrics, flaggerv1.CanaryMetricStatus{
+		Name:           name,
+		Value:          value,
+		LastUpdateTime: now,
+	})
+}
+
+// syncMetricStatus persists the metric values measured during the analysis
+func (c *Controller) syncMetricStatus(canary *flaggerv1.Canary) {
+	if len(canary.Status.Metrics) == 0 {
+		return
+	}
+	canaryController := c.canaryFactory.Controller(canary.Spec.TargetRef)
+	if err := canaryController.SetStatusMetrics(canary, canary.Status.Metrics); err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
+	}
+}
+
+// metricModel returns the query template model, for Knative targets
+// the model revision is set to the revision under analysis,
+// during the bake time the model targets the primary workload
+func (c *Controller) metricModel(canary *flaggerv1.Canary, interval string) flaggerv1.MetricTemplateModel {
+	model := toMetricModel(canary, interval)
+	if canary.Status.Phase == flaggerv1.CanaryPhaseBaking {
+		model.Target = fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name)
+	}
+	if canary.Spec.TargetRef.IsKnativeService() {
+		svc, err := c.flaggerClient.ServingV1().Services(canary.Namespace).Get(context.TODO(), canary.Spec.TargetRef.Name, metav1.GetOptions{})
+		if err != nil {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+				Errorf("Knative service %s.%s get query error: %v", canary.Spec.TargetRef.Name, canary.Namespace, err)
+		} else {
+			model.Revision = svc.Status.LatestCreatedRevisionName
+		}
+	}
+	return model
+}
+
 func toMetricModel(r *flaggerv1.Canary, interval string) flaggerv1.MetricTemplateModel {
 	service := r.Spec.TargetRef.Name
 	if r.Spec.Service.Name != "" {
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+	"github.com/weaveworks/flagger/pkg/router"
+	"github.com/weaveworks/flagger/pkg/sink"
+)
+
+// rollbackToRevision rolls the primary back to the revision requested with the rollback annotation
+// without running the analysis, a canary analysis underway is aborted once the primary is ready,
+// the annotation is kept until then so that the rollback is resumed at the next run.
+// The applied revision is recorded in the canary status to revert the primary only once.
+func (c *Controller) rollbackToRevision(cd *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface) {
+	value := cd.Annotations[flaggerv1.RollbackRevisionAnnotation]
+	revision, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || revision < 0 {
+		c.recordEventWarningf(cd, "Invalid %s annotation value %s", flaggerv1.RollbackRevisionAnnotation, value)
+		c.removeAnnotation(cd, flaggerv1.RollbackRevisionAnnotation)
+		return
+	}
+
+	if cd.Status.RollbackRevision != value {
+		if err := canaryController.RevertPrimary(cd, revision); err != nil {
+			c.recordEventWarningf(cd, "Rolling back %s-primary.%s failed: %v", cd.Spec.TargetRef.Name, cd.Namespace, err)
+			c.removeAnnotation(cd, flaggerv1.RollbackRevisionAnnotation)
+			return
+		}
+	}
+
+	switch cd.Status.Phase {
+	case flaggerv1.CanaryPhaseProgressing, flaggerv1.CanaryPhaseWaiting, flaggerv1.CanaryPhasePromoting,
+		flaggerv1.CanaryPhaseFinalising, flaggerv1.CanaryPhaseBaking:
+	default:
+		c.rolledBackToRevision(cd, value)
+		return
+	}
+
+	if cd.Status.RollbackRevision != value {
+		if err := c.setRollbackRevision(cd, value); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return
+		}
+	}
+
+	// route all traffic back to primary
+	if err := c.restorePrimary(cd, canaryController); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+	if err := meshRouter.SetRoutes(cd, 100, 0, false); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+	c.recorder.SetWeight(cd, 100, 0)
+	c.publishEvent(sink.NewWeightEvent(cd, 100, 0))
+
+	// shutdown canary
+	if err := canaryController.ScaleToZero(cd); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+
+	// mark canary as failed
+	if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhaseFailed); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+	c.recorder.SetStatus(cd, flaggerv1.CanaryPhaseFailed)
+	c.publishEvent(sink.NewPhaseEvent(cd, flaggerv1.CanaryPhaseFailed))
+	c.runPostRolloutHooks(cd, flaggerv1.CanaryPhaseFailed)
+	c.rolledBackToRevision(cd, value)
+}
+
+// rolledBackToRevision notifies the rollback and removes the rollback annotation
+func (c *Controller) rolledBackToRevision(cd *flaggerv1.Canary, revision string) {
+	c.recordEventInfof(cd, "Rolled back %s-primary.%s to revision %s", cd.Spec.TargetRef.Name, cd.Namespace, revision)
+	c.alert(cd, fmt.Sprintf("Primary rolled back to revision %s", revision), false, flaggerv1.SeverityWarn)
+	if cd.Status.RollbackRevision != "" {
+		if err := c.setRollbackRevision(cd, ""); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+		}
+	}
+	c.removeAnnotation(cd, flaggerv1.RollbackRevisionAnnotation)
+}
+
+// setRollbackRevision records the rollback applied to the primary in the canary status
+func (c *Controller) setRollbackRevision(cd *flaggerv1.Canary, revision string) error {
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		canary, err := c.flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).Get(context.TODO(), cd.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		cdCopy := canary.DeepCopy()
+		cdCopy.Status.RollbackRevision = revision
+		updated, err := c.flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).UpdateStatus(context.TODO(), cdCopy, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+		cd.ResourceVersion = updated.ResourceVersion
+		cd.Status.RollbackRevision = revision
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("canary %s.%s status update error: %w", cd.Name, cd.Namespace, err)
+	}
+	return nil
+}
+
+// removeAnnotation removes a one-shot action annotation once the action has been applied
+func (c *Controller) removeAnnotation(cd *flaggerv1.Canary, key string) {
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		canary, err := c.flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).Get(context.TODO(), cd.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if _, ok := canary.Annotations[key]; !ok {
+			return nil
+		}
+
+		cdCopy := canary.DeepCopy()
+		delete(cdCopy.Annotations, key)
+		_, err = c.flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).Update(context.TODO(), cdCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Errorf("Removing %s annotation failed: %v", key, err)
+	}
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"fmt"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+	"github.com/weaveworks/flagger/pkg/router"
+	"github.com/weaveworks/flagger/pkg/sink"
+)
+
+// runShadow runs the canary analysis for the configured iterations while all traffic is routed to the primary,
+// the verdict is reported in the canary status and the canary is never promoted
+func (c *Controller) runShadow(cd *flaggerv1.Canary, canaryController canary.Controller,
+	meshRouter router.Interface, mirrored bool) {
+	// restart the analysis if the canary revision changed
+	if c.hasCanaryRevisionChanged(cd, canaryController) {
+		c.recordEventInfof(cd, "New revision detected! Restarting shadow analysis for %s.%s",
+			cd.Spec.TargetRef.Name, cd.Namespace)
+		if err := canaryController.SyncStatus(cd, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseShadowing}); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+		}
+		c.publishEvent(sink.NewPhaseEvent(cd, flaggerv1.CanaryPhaseShadowing))
+		return
+	}
+
+	retriable, err := canaryController.IsCanaryReady(cd)
+	if err != nil && retriable {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+	if !retriable {
+		c.recordEventWarningf(cd, "Shadow analysis failed! %s.%s progress deadline exceeded %v",
+			cd.Name, cd.Namespace, err)
+		c.finishShadow(cd, canaryController, meshRouter, mirrored, flaggerv1.CanaryPhaseShadowFailed)
+		return
+	}
+
+	if ok := c.runRollbackHooks(cd, cd.Status.Phase); ok {
+		c.recordEventWarningf(cd, "Shadow analysis failed! %s.%s manual webhook invoked", cd.Name, cd.Namespace)
+		c.finishShadow(cd, canaryController, meshRouter, mirrored, flaggerv1.CanaryPhaseShadowFailed)
+		return
+	}
+
+	if cd.Status.FailedChecks >= cd.GetAnalysisThreshold() {
+		c.recordEventWarningf(cd, "Shadow analysis failed! %s.%s failed checks threshold reached %v",
+			cd.Name, cd.Namespace, cd.Status.FailedChecks)
+		c.finishShadow(cd, canaryController, meshRouter, mirrored, flaggerv1.CanaryPhaseShadowFailed)
+		return
+	}
+
+	iterations := cd.GetAnalysis().Iterations
+	if iterations < 1 {
+		iterations = flaggerv1.ShadowIterations
+	}
+	if cd.Status.Iterations >= iterations {
+		c.recordEventInfof(cd, "Shadow analysis completed! %s.%s passed all checks", cd.Name, cd.Namespace)
+		c.finishShadow(cd, canaryController, meshRouter, mirrored, flaggerv1.CanaryPhaseShadowSucceeded)
+		return
+	}
+
+	// mirror the primary traffic to canary, the responses are served by the primary only
+	if cd.GetAnalysis().Mirror && !mirrored {
+		if err := meshRouter.SetRoutes(cd, 100, 0, true); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return
+		}
+		c.recordEventInfof(cd, "Mirroring traffic to %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
+	}
+
+	if cd.Status.Iterations == 0 {
+		c.recordEventInfof(cd, "Starting shadow analysis for %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
+		if ok := c.runPreRolloutHooks(cd); !ok {
+			if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
+				c.recordEventWarningf(cd, "%v", err)
+			}
+			return
+		}
+	} else {
+		ok := c.runAnalysis(cd)
+		c.publishEvent(sink.NewAnalysisEvent(cd, ok))
+		if !ok {
+			if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
+				c.recordEventWarningf(cd, "%v", err)
+			}
+			return
+		}
+	}
+
+	if err := canaryController.SetStatusIterations(cd, cd.Status.Iterations+1); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+	c.recordEventInfof(cd, "Advance %s.%s shadow iteration %v/%v",
+		cd.Name, cd.Namespace, cd.Status.Iterations+1, iterations)
+}
+
+// finishShadow stops the traffic mirroring, scales the canary to zero and reports the shadow analysis verdict
+func (c *Controller) finishShadow(cd *flaggerv1.Canary, canaryController canary.Controller,
+	meshRouter router.Interface, mirrored bool, phase flaggerv1.CanaryPhase) {
+	if mirrored {
+		if err := meshRouter.SetRoutes(cd, 100, 0, false); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return
+		}
+	}
+
+	if err := canaryController.ScaleToZero(cd); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+
+	if err := canaryController.SetStatusPhase(cd, phase); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+	c.recorder.SetStatus(cd, phase)
+	c.publishEvent(sink.NewPhaseEvent(cd, phase))
+	c.runPostRolloutHooks(cd, phase)
+
+	if phase == flaggerv1.CanaryPhaseShadowSucceeded {
+		c.alert(cd, "Shadow analysis completed successfully, canary not promoted.",
+			false, flaggerv1.SeverityInfo)
+		return
+	}
+	c.alert(cd, fmt.Sprintf("Shadow analysis failed, %s scaled to zero.", cd.Spec.TargetRef.Kind),
+		false, flaggerv1.SeverityError)
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"fmt"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+)
+
+// applyCanaryTemplate returns a copy of the canary merged with the referenced template,
+// the template is read at every reconciliation so that template changes apply to all canaries
+func (c *Controller) applyCanaryTemplate(cd *flaggerv1.Canary) (*flaggerv1.Canary, error) {
+	ref := cd.Spec.TemplateRef
+	if ref == nil {
+		return cd, nil
+	}
+
+	switch ref.Kind {
+	case "", flaggerv1.CanaryTemplateKind:
+		template, err := c.flaggerInformers.CanaryTemplateInformer.Lister().CanaryTemplates(cd.Namespace).Get(ref.Name)
+		if err != nil {
+			return nil, fmt.Errorf("canary template %s.%s get query error: %w", ref.Name, cd.Namespace, err)
+		}
+		return canary.MergeTemplate(cd, template.Spec), nil
+	case flaggerv1.ClusterCanaryTemplateKind:
+		template, err := c.flaggerInformers.ClusterCanaryTemplateInformer.Lister().Get(ref.Name)
+		if err != nil {
+			return nil, fmt.Errorf("cluster canary template %s get query error: %w", ref.Name, err)
+		}
+		return canary.MergeTemplate(cd, template.Spec), nil
+	default:
+		return nil, fmt.Errorf("template kind %s not supported", ref.Kind)
+	}
+}
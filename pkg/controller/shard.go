@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// claimCanary records the controller shard as the owner of the canary,
+// returns false if the analysis is driven by another shard or if the canary
+// has been changed by another instance in the meantime
+func (c *Controller) claimCanary(cd *flaggerv1.Canary) (*flaggerv1.Canary, bool) {
+	owner := cd.Annotations[flaggerv1.ShardOwnerAnnotation]
+	if owner == c.shard {
+		return cd, true
+	}
+
+	// wait for the other shard to finish or release the analysis
+	if owner != "" && !isShardIdle(cd) {
+		c.recordEventInfof(cd, "Halt %s.%s advancement, analysis driven by shard %s, remove the %s annotation to take over",
+			cd.Name, cd.Namespace, owner, flaggerv1.ShardOwnerAnnotation)
+		return nil, false
+	}
+
+	// the instances without a shard don't record the ownership
+	var value interface{}
+	if c.shard != "" {
+		value = c.shard
+	}
+	claimed, err := patchCanaryAnnotations(c.flaggerClient, cd, map[string]interface{}{
+		flaggerv1.ShardOwnerAnnotation: value,
+	})
+	if errors.IsConflict(err) {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Debugf("Canary %s.%s has been modified, claiming skipped: %v", cd.Name, cd.Namespace, err)
+		return nil, false
+	} else if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Errorf("Claiming canary failed: %v", err)
+		return nil, false
+	}
+	if owner != "" {
+		c.recordEventInfof(claimed, "Canary %s.%s taken over from shard %s", cd.Name, cd.Namespace, owner)
+	}
+	return claimed, true
+}
+
+// releaseCanary removes the ownership of a canary that has been moved to another shard,
+// the new shard resumes the analysis from the current phase
+func (c *Controller) releaseCanary(name string, namespace string) {
+	if c.shard == "" {
+		return
+	}
+
+	cd, err := c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return
+	} else if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).Errorf("Canary %s.%s get query error: %v", name, namespace, err)
+		return
+	}
+	if cd.Annotations[flaggerv1.ShardOwnerAnnotation] != c.shard || cd.Labels[flaggerv1.ShardLabel] == c.shard {
+		return
+	}
+
+	_, err = patchCanaryAnnotations(c.flaggerClient, cd, map[string]interface{}{
+		flaggerv1.ShardOwnerAnnotation: nil,
+	})
+	if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).Errorf("Releasing canary failed: %v", err)
+		return
+	}
+	c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).
+		Infof("Canary %s.%s moved to shard %s, ownership released", name, namespace, cd.Labels[flaggerv1.ShardLabel])
+}
+
+// isShardIdle returns true if no analysis is underway and the canary can be taken over by another shard
+func isShardIdle(cd *flaggerv1.Canary) bool {
+	switch cd.Status.Phase {
+	case "", flaggerv1.CanaryPhaseInitialized, flaggerv1.CanaryPhaseSucceeded, flaggerv1.CanaryPhaseFailed:
+		return true
+	}
+	return false
+}
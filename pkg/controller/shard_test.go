@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	fakeFlagger "github.com/weaveworks/flagger/pkg/client/clientset/versioned/fake"
+)
+
+func TestScheduler_DeploymentShardClaim(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.shard = "a"
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "a", c.Annotations[flaggerv1.ShardOwnerAnnotation])
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	weight := c.Status.CanaryWeight
+
+	// another shard doesn't drive the analysis underway
+	mocks.ctrl.shard = "b"
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, weight, c.Status.CanaryWeight)
+	assert.Equal(t, "a", c.Annotations[flaggerv1.ShardOwnerAnnotation])
+
+	// the analysis is resumed once the owner releases the canary
+	c.Labels = map[string]string{flaggerv1.ShardLabel: "b"}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.shard = "a"
+	mocks.ctrl.releaseCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, c.Annotations, flaggerv1.ShardOwnerAnnotation)
+
+	mocks.ctrl.shard = "b"
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "b", c.Annotations[flaggerv1.ShardOwnerAnnotation])
+	assert.Greater(t, c.Status.CanaryWeight, weight)
+}
+
+func TestScheduler_DeploymentShardTakeOver(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// the instances without a shard don't record the ownership
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, c.Annotations, flaggerv1.ShardOwnerAnnotation)
+
+	// an idle canary is taken over from its previous owner
+	c.Annotations = map[string]string{flaggerv1.ShardOwnerAnnotation: "a"}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, c.Annotations, flaggerv1.ShardOwnerAnnotation)
+	assert.Equal(t, flaggerv1.CanaryPhaseInitialized, c.Status.Phase)
+}
+
+func TestScheduler_DeploymentShardClaimConflict(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.shard = "a"
+	flaggerClient := mocks.flaggerClient.(*fakeFlagger.Clientset)
+
+	// the API server rejects the patches with a stale resource version
+	gvr := flaggerv1.SchemeGroupVersion.WithResource("canaries")
+	flaggerClient.PrependReactor("patch", "canaries", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		var meta struct {
+			Metadata metav1.ObjectMeta `json:"metadata"`
+		}
+		require.NoError(t, json.Unmarshal(patch.GetPatch(), &meta))
+		obj, err := flaggerClient.Tracker().Get(gvr, patch.GetNamespace(), patch.GetName())
+		require.NoError(t, err)
+		if meta.Metadata.ResourceVersion != obj.(*flaggerv1.Canary).ResourceVersion {
+			return true, nil, errors.NewConflict(gvr.GroupResource(), patch.GetName(), fmt.Errorf("the object has been modified"))
+		}
+		return false, nil, nil
+	})
+
+	stale, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// another instance modifies the canary
+	cd := stale.DeepCopy()
+	cd.ResourceVersion = "2"
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	_, ok := mocks.ctrl.claimCanary(stale)
+	assert.False(t, ok)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, c.Annotations, flaggerv1.ShardOwnerAnnotation)
+
+	// the canary is claimed at the next run
+	claimed, ok := mocks.ctrl.claimCanary(c)
+	require.True(t, ok)
+	assert.Equal(t, "a", claimed.Annotations[flaggerv1.ShardOwnerAnnotation])
+}
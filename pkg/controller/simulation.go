@@ -0,0 +1,547 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+	fakeFlagger "github.com/weaveworks/flagger/pkg/client/clientset/versioned/fake"
+	informers "github.com/weaveworks/flagger/pkg/client/informers/externalversions"
+	"github.com/weaveworks/flagger/pkg/metrics"
+	"github.com/weaveworks/flagger/pkg/metrics/observers"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+	"github.com/weaveworks/flagger/pkg/notifier"
+	"github.com/weaveworks/flagger/pkg/router"
+)
+
+const simulationRevisionAnnotation = "flagger.app/simulation-revision"
+
+// SimulationTimeline holds the metric values and webhook outcomes replayed by the simulation,
+// the n-th entry of a list is used for the n-th analysis run and the last entry is repeated
+type SimulationTimeline struct {
+	// Metrics maps the analysis metric names to their values,
+	// the request-duration values are in milliseconds
+	Metrics map[string][]float64 `json:"metrics,omitempty"`
+	// Webhooks maps the webhook names to their outcomes,
+	// the webhooks missing from the timeline succeed
+	Webhooks map[string][]bool `json:"webhooks,omitempty"`
+}
+
+// SimulationOptions holds the settings the Flagger controller would run with
+type SimulationOptions struct {
+	// MeshProvider is the global mesh provider, defaults to istio
+	MeshProvider string
+	// SelectorLabels are the pod labels used to create the pod selectors
+	SelectorLabels []string
+	// MaxSteps stops the simulation if the canary analysis hasn't finished, defaults to 100
+	MaxSteps int
+}
+
+// SimulationValue is the outcome of a metric query or a webhook call
+type SimulationValue struct {
+	Name string
+	// Value is the metric value
+	Value float64
+	// Passed is the webhook outcome
+	Passed bool
+}
+
+// SimulationStep is the canary state after an analysis interval
+type SimulationStep struct {
+	// Step is the interval number, the step zero is the detection of the new revision
+	Step         int
+	Phase        flaggerv1.CanaryPhase
+	CanaryWeight int
+	Iterations   int
+	FailedChecks int
+	// Verdict is one of advance, halt, wait, promote, rollback or the lower case phase name
+	Verdict  string
+	Metrics  []SimulationValue
+	Webhooks []SimulationValue
+	Events   []string
+}
+
+// SimulationResult holds the simulated analysis steps
+type SimulationResult struct {
+	Steps []SimulationStep
+	// Phase is the canary phase at the end of the simulation
+	Phase flaggerv1.CanaryPhase
+	// RollbackStep is the step at which the canary was rolled back, zero if the canary wasn't rolled back
+	RollbackStep int
+}
+
+// Simulate runs the canary analysis state machine against the timeline metric values and webhook outcomes,
+// the scheduler is driven with fake clientsets so no cluster, metrics server or webhook is required.
+// The kube objects can hold the target workload, if missing a deployment is generated from the canary target ref.
+func Simulate(cd *flaggerv1.Canary, kubeObjects []runtime.Object, flaggerObjects []runtime.Object,
+	timeline SimulationTimeline, opts SimulationOptions) (*SimulationResult, error) {
+	if opts.MeshProvider == "" {
+		opts.MeshProvider = "istio"
+	}
+	if opts.MaxSteps <= 0 {
+		opts.MaxSteps = 100
+	}
+	cd = cd.DeepCopy()
+	cd.Status = flaggerv1.CanaryStatus{}
+	if cd.Namespace == "" {
+		cd.Namespace = metav1.NamespaceDefault
+	}
+
+	switch cd.Spec.TargetRef.Kind {
+	case "Deployment", "DaemonSet":
+	default:
+		return nil, fmt.Errorf("target kind %s is not supported by the simulation", cd.Spec.TargetRef.Kind)
+	}
+
+	flaggerClient := fakeFlagger.NewSimpleClientset(flaggerObjects...)
+	flaggerInformerFactory := informers.NewSharedInformerFactory(flaggerClient, 0)
+	fi := Informers{
+		CanaryInformer:                flaggerInformerFactory.Flagger().V1beta1().Canaries(),
+		MetricInformer:                flaggerInformerFactory.Flagger().V1beta1().MetricTemplates(),
+		AlertInformer:                 flaggerInformerFactory.Flagger().V1beta1().AlertProviders(),
+		CanaryTemplateInformer:        flaggerInformerFactory.Flagger().V1beta1().CanaryTemplates(),
+		ClusterCanaryTemplateInformer: flaggerInformerFactory.Flagger().V1beta1().ClusterCanaryTemplates(),
+	}
+	for _, obj := range flaggerObjects {
+		switch o := obj.(type) {
+		case *flaggerv1.MetricTemplate:
+			// the queries are answered by the timeline, the provider credentials are not needed
+			template := o.DeepCopy()
+			template.Spec.Provider.SecretRef = nil
+			fi.MetricInformer.Informer().GetIndexer().Add(template)
+		case *flaggerv1.CanaryTemplate:
+			fi.CanaryTemplateInformer.Informer().GetIndexer().Add(o)
+		case *flaggerv1.ClusterCanaryTemplate:
+			fi.ClusterCanaryTemplateInformer.Informer().GetIndexer().Add(o)
+		}
+	}
+
+	sim := &simulation{timeline: timeline}
+	logger := zap.NewNop().Sugar()
+	ctrl := &Controller{
+		flaggerClient:    flaggerClient,
+		flaggerInformers: fi,
+		flaggerSynced:    fi.CanaryInformer.Informer().HasSynced,
+		eventRecorder:    record.NewFakeRecorder(1000),
+		logger:           logger,
+		canaries:         new(sync.Map),
+		flaggerWindow:    time.Second,
+		observerFactory:  &observers.Factory{Client: sim},
+		recorder:         metrics.NewRecorder(controllerAgentName, false),
+		notifier:         &notifier.NopNotifier{},
+		meshProvider:     opts.MeshProvider,
+		webhookCaller:    sim.callWebhook,
+		providerFactory:  sim.provider,
+	}
+
+	// merge the canary template and drop the settings that reach outside the simulation
+	cd, err := ctrl.applyCanaryTemplate(cd)
+	if err != nil {
+		return nil, err
+	}
+	cd.Spec.TemplateRef = nil
+	cd.Spec.MetricsServer = ""
+	analysis := cd.GetAnalysis()
+	if analysis == nil {
+		return nil, fmt.Errorf("canary %s.%s has no analysis", cd.Name, cd.Namespace)
+	}
+	analysis.Alerts = nil
+	var webhooks []flaggerv1.CanaryWebhook
+	for _, webhook := range analysis.Webhooks {
+		if webhook.Type != flaggerv1.EventHook {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	analysis.Webhooks = webhooks
+	sim.queries = simulationQueries(ctrl, cd)
+
+	// generate the target and the metric templates missing from the manifests
+	if !hasObject(kubeObjects, cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name, cd.Namespace) {
+		kubeObjects = append(kubeObjects, newSimulationTarget(cd))
+	}
+	for _, metric := range analysis.Metrics {
+		if metric.TemplateRef == nil {
+			continue
+		}
+		namespace := cd.Namespace
+		if metric.TemplateRef.Namespace != "" {
+			namespace = metric.TemplateRef.Namespace
+		}
+		if _, err := fi.MetricInformer.Lister().MetricTemplates(namespace).Get(metric.TemplateRef.Name); err == nil {
+			continue
+		}
+		fi.MetricInformer.Informer().GetIndexer().Add(&flaggerv1.MetricTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: metric.TemplateRef.Name, Namespace: namespace},
+			Spec: flaggerv1.MetricTemplateSpec{
+				Provider: flaggerv1.MetricTemplateProvider{Type: "prometheus"},
+				Query:    metric.Name,
+			},
+		})
+	}
+
+	if _, err := flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).Create(context.TODO(), cd, metav1.CreateOptions{}); err != nil {
+		return nil, fmt.Errorf("canary %s.%s create error: %w", cd.Name, cd.Namespace, err)
+	}
+	fi.CanaryInformer.Informer().GetIndexer().Add(cd)
+
+	kubeClient := fake.NewSimpleClientset(kubeObjects...)
+	configTracker := &canary.ConfigTracker{
+		Logger:        logger,
+		KubeClient:    kubeClient,
+		FlaggerClient: flaggerClient,
+	}
+	selectorLabels := opts.SelectorLabels
+	if len(selectorLabels) == 0 {
+		selectorLabels = []string{"app", "name", "app.kubernetes.io/name"}
+	}
+	ctrl.kubeClient = kubeClient
+	ctrl.canaryFactory = canary.NewFactory(kubeClient, flaggerClient, configTracker, selectorLabels, logger)
+	ctrl.routerFactory = router.NewFactory(nil, kubeClient, flaggerClient, "nginx.ingress.kubernetes.io", logger, flaggerClient)
+	ctrl.flaggerSynced = func() bool { return true }
+
+	// initialize the primary workload, the rollout of the fake workloads completes instantly
+	initialized := false
+	for i := 0; i < 3 && !initialized; i++ {
+		if err := sim.markWorkloadsReady(kubeClient, cd.Namespace); err != nil {
+			return nil, err
+		}
+		ctrl.advanceCanary(cd.Name, cd.Namespace)
+		events := sim.drainEvents(ctrl)
+		c, err := flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).Get(context.TODO(), cd.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if c.Status.Phase == flaggerv1.CanaryPhaseInitialized {
+			initialized = true
+		} else if i == 2 {
+			return nil, fmt.Errorf("canary %s.%s initialization failed: %s", cd.Name, cd.Namespace, strings.Join(events, ", "))
+		}
+	}
+
+	// roll out a new revision of the target
+	if err := bumpSimulationRevision(kubeClient, cd); err != nil {
+		return nil, err
+	}
+
+	result := &SimulationResult{}
+	prev := flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseInitialized}
+	for step := 0; step <= opts.MaxSteps; step++ {
+		if err := sim.markWorkloadsReady(kubeClient, cd.Namespace); err != nil {
+			return nil, err
+		}
+		sim.startInterval()
+		ctrl.advanceCanary(cd.Name, cd.Namespace)
+
+		c, err := flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).Get(context.TODO(), cd.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		st := SimulationStep{
+			Step:         step,
+			Phase:        c.Status.Phase,
+			CanaryWeight: c.Status.CanaryWeight,
+			Iterations:   c.Status.Iterations,
+			FailedChecks: c.Status.FailedChecks,
+			Verdict:      simulationVerdict(prev, c.Status),
+			Metrics:      sim.metrics,
+			Webhooks:     sim.webhooks,
+			Events:       sim.drainEvents(ctrl),
+		}
+		result.Steps = append(result.Steps, st)
+		result.Phase = c.Status.Phase
+		if st.Verdict == "rollback" && result.RollbackStep == 0 {
+			result.RollbackStep = step
+		}
+		prev = c.Status
+
+		switch c.Status.Phase {
+		case flaggerv1.CanaryPhaseSucceeded, flaggerv1.CanaryPhaseFailed,
+			flaggerv1.CanaryPhaseShadowSucceeded, flaggerv1.CanaryPhaseShadowFailed:
+			return result, nil
+		}
+	}
+	return result, nil
+}
+
+// simulationVerdict describes the scheduler decision from the status change of an interval
+func simulationVerdict(prev flaggerv1.CanaryStatus, next flaggerv1.CanaryStatus) string {
+	switch {
+	case next.Phase == flaggerv1.CanaryPhaseFailed && prev.Phase != flaggerv1.CanaryPhaseFailed,
+		next.Phase == flaggerv1.CanaryPhaseShadowFailed && prev.Phase != flaggerv1.CanaryPhaseShadowFailed:
+		return "rollback"
+	case next.FailedChecks > prev.FailedChecks:
+		return "halt"
+	case next.Phase == flaggerv1.CanaryPhasePromoting && prev.Phase != flaggerv1.CanaryPhasePromoting:
+		return "promote"
+	case next.Phase != prev.Phase:
+		return strings.ToLower(string(next.Phase))
+	case next.CanaryWeight != prev.CanaryWeight || next.Iterations != prev.Iterations:
+		return "advance"
+	default:
+		return "wait"
+	}
+}
+
+// simulationQuery is a metric query run by the analysis in the order of the metric checks
+type simulationQuery struct {
+	name string
+	// seconds is set for the request-duration observers that query Prometheus in seconds
+	seconds bool
+}
+
+// simulationQueries returns the queries run by an analysis where all the metric checks pass,
+// the built-in and in-line metrics are checked before the metric templates
+func simulationQueries(ctrl *Controller, cd *flaggerv1.Canary) []simulationQuery {
+	analysis := cd.GetAnalysis()
+	if analysis == nil {
+		return nil
+	}
+
+	seconds := false
+	switch ctrl.observerFactory.Observer(ctrl.builtinMetricsProvider(cd)).(type) {
+	case *observers.IstioObserver, *observers.HttpObserver:
+		seconds = true
+	}
+
+	var queries []simulationQuery
+	for _, metric := range analysis.Metrics {
+		if metric.Name == "request-success-rate" {
+			queries = append(queries, simulationQuery{name: metric.Name})
+		}
+		if metric.Name == "request-duration" {
+			queries = append(queries, simulationQuery{name: metric.Name, seconds: seconds})
+		}
+		if metric.Query != "" {
+			queries = append(queries, simulationQuery{name: metric.Name})
+		}
+	}
+	for _, metric := range analysis.Metrics {
+		if metric.TemplateRef != nil {
+			queries = append(queries, simulationQuery{name: metric.Name})
+		}
+	}
+	return queries
+}
+
+// simulation answers the metric queries and the webhook calls from the timeline
+type simulation struct {
+	timeline SimulationTimeline
+	queries  []simulationQuery
+	analysis int
+	analysed bool
+	cursor   int
+	metrics  []SimulationValue
+	webhooks []SimulationValue
+}
+
+// startInterval resets the outcomes recorded during the previous interval,
+// the timeline entries advance after each interval that ran the analysis
+func (s *simulation) startInterval() {
+	if s.analysed {
+		s.analysis++
+	}
+	s.analysed = false
+	s.cursor = 0
+	s.metrics = nil
+	s.webhooks = nil
+}
+
+// RunQuery returns the timeline value of the next metric checked by the analysis
+func (s *simulation) RunQuery(_ string) (float64, error) {
+	if s.cursor >= len(s.queries) {
+		return 0, fmt.Errorf("unexpected metric query")
+	}
+	query := s.queries[s.cursor]
+	s.cursor++
+	s.analysed = true
+
+	values := s.timeline.Metrics[query.name]
+	if len(values) == 0 {
+		return 0, fmt.Errorf("%w: metric %s is missing from the timeline", providers.ErrNoValuesFound, query.name)
+	}
+	val := metricEntry(values, s.analysis)
+	s.metrics = append(s.metrics, SimulationValue{Name: query.name, Value: val})
+	if query.seconds {
+		return val / 1000, nil
+	}
+	return val, nil
+}
+
+// IsOnline always returns true
+func (s *simulation) IsOnline() (bool, error) {
+	return true, nil
+}
+
+func (s *simulation) provider(_ string, _ flaggerv1.MetricTemplateProvider, _ map[string][]byte) (providers.Interface, error) {
+	return s, nil
+}
+
+func (s *simulation) callWebhook(_ string, _ string, _ flaggerv1.CanaryPhase, w flaggerv1.CanaryWebhook) error {
+	if w.Type == "" || w.Type == flaggerv1.RolloutHook {
+		s.analysed = true
+	}
+	passed := true
+	if outcomes := s.timeline.Webhooks[w.Name]; len(outcomes) > 0 {
+		passed = webhookEntry(outcomes, s.analysis)
+	}
+	s.webhooks = append(s.webhooks, SimulationValue{Name: w.Name, Passed: passed})
+	if !passed {
+		return fmt.Errorf("simulated %s failure", w.Name)
+	}
+	return nil
+}
+
+// drainEvents returns the events recorded since the last call
+func (s *simulation) drainEvents(ctrl *Controller) []string {
+	recorder := ctrl.eventRecorder.(*record.FakeRecorder)
+	var events []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+// markWorkloadsReady sets the status of the deployments and daemonsets as if their rollout finished
+func (s *simulation) markWorkloadsReady(kubeClient *fake.Clientset, namespace string) error {
+	deployments, err := kubeClient.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, dep := range deployments.Items {
+		replicas := int32(1)
+		if dep.Spec.Replicas != nil {
+			replicas = *dep.Spec.Replicas
+		}
+		dep.Status = appsv1.DeploymentStatus{
+			Replicas:          replicas,
+			UpdatedReplicas:   replicas,
+			ReadyReplicas:     replicas,
+			AvailableReplicas: replicas,
+		}
+		if _, err := kubeClient.AppsV1().Deployments(namespace).UpdateStatus(context.TODO(), &dep, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+
+	daemonSets, err := kubeClient.AppsV1().DaemonSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, ds := range daemonSets.Items {
+		ds.Status = appsv1.DaemonSetStatus{
+			CurrentNumberScheduled: 1,
+			DesiredNumberScheduled: 1,
+			NumberReady:            1,
+			UpdatedNumberScheduled: 1,
+			NumberAvailable:        1,
+		}
+		if _, err := kubeClient.AppsV1().DaemonSets(namespace).UpdateStatus(context.TODO(), &ds, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// metricEntry returns the value of the analysis run, the last value is repeated
+func metricEntry(values []float64, analysis int) float64 {
+	if analysis >= len(values) {
+		return values[len(values)-1]
+	}
+	return values[analysis]
+}
+
+// webhookEntry returns the outcome of the analysis run, the last outcome is repeated
+func webhookEntry(outcomes []bool, analysis int) bool {
+	if analysis >= len(outcomes) {
+		return outcomes[len(outcomes)-1]
+	}
+	return outcomes[analysis]
+}
+
+func hasObject(objects []runtime.Object, kind string, name string, namespace string) bool {
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			if kind == "Deployment" && o.Name == name && o.Namespace == namespace {
+				return true
+			}
+		case *appsv1.DaemonSet:
+			if kind == "DaemonSet" && o.Name == name && o.Namespace == namespace {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// newSimulationTarget returns a workload labeled with the target name
+func newSimulationTarget(cd *flaggerv1.Canary) runtime.Object {
+	labels := map[string]string{"app": cd.Spec.TargetRef.Name}
+	meta := metav1.ObjectMeta{Name: cd.Spec.TargetRef.Name, Namespace: cd.Namespace}
+	selector := &metav1.LabelSelector{MatchLabels: labels}
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: labels},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  cd.Spec.TargetRef.Name,
+				Image: cd.Spec.TargetRef.Name,
+			}},
+		},
+	}
+
+	if cd.Spec.TargetRef.Kind == "DaemonSet" {
+		return &appsv1.DaemonSet{
+			ObjectMeta: meta,
+			Spec:       appsv1.DaemonSetSpec{Selector: selector, Template: template},
+		}
+	}
+	return &appsv1.Deployment{
+		ObjectMeta: meta,
+		Spec:       appsv1.DeploymentSpec{Selector: selector, Template: template},
+	}
+}
+
+// bumpSimulationRevision changes the target pod template so that the scheduler detects a new revision
+func bumpSimulationRevision(kubeClient *fake.Clientset, cd *flaggerv1.Canary) error {
+	name := cd.Spec.TargetRef.Name
+	if cd.Spec.TargetRef.Kind == "DaemonSet" {
+		ds, err := kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("daemonset %s.%s get query error: %w", name, cd.Namespace, err)
+		}
+		setSimulationRevision(&ds.Spec.Template)
+		_, err = kubeClient.AppsV1().DaemonSets(cd.Namespace).Update(context.TODO(), ds, metav1.UpdateOptions{})
+		return err
+	}
+
+	dep, err := kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s get query error: %w", name, cd.Namespace, err)
+	}
+	setSimulationRevision(&dep.Spec.Template)
+	_, err = kubeClient.AppsV1().Deployments(cd.Namespace).Update(context.TODO(), dep, metav1.UpdateOptions{})
+	return err
+}
+
+func setSimulationRevision(template *corev1.PodTemplateSpec) {
+	if template.Annotations == nil {
+		template.Annotations = make(map[string]string)
+	}
+	template.Annotations[simulationRevisionAnnotation] = time.Now().Format(time.RFC3339Nano)
+}
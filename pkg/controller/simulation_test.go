@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newSimulationTestCanary() *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "podinfo"},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{
+				Name:       "podinfo",
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+			},
+			Service: flaggerv1.CanaryService{Port: 9898},
+			Analysis: &flaggerv1.CanaryAnalysis{
+				Threshold:  2,
+				StepWeight: 10,
+				MaxWeight:  30,
+				Metrics: []flaggerv1.CanaryMetric{
+					{
+						Name:      "request-success-rate",
+						Threshold: 99,
+						Interval:  "1m",
+					},
+					{
+						Name:      "request-duration",
+						Threshold: 500,
+						Interval:  "1m",
+					},
+					{
+						Name:      "error-budget",
+						Threshold: 5,
+						Interval:  "1m",
+						TemplateRef: &flaggerv1.CrossNamespaceObjectReference{
+							Name: "error-budget",
+						},
+					},
+				},
+				Webhooks: []flaggerv1.CanaryWebhook{
+					{
+						Name: "load-test",
+						URL:  "http://flagger-loadtester.test/",
+					},
+				},
+			},
+		},
+	}
+}
+
+func verdicts(result *SimulationResult) []string {
+	var v []string
+	for _, step := range result.Steps {
+		v = append(v, step.Verdict)
+	}
+	return v
+}
+
+func TestSimulate_Promotion(t *testing.T) {
+	timeline := SimulationTimeline{
+		Metrics: map[string][]float64{
+			"request-success-rate": {100},
+			"request-duration":     {120, 250},
+			"error-budget":         {1},
+		},
+	}
+
+	result, err := Simulate(newSimulationTestCanary(), nil, nil, timeline, SimulationOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, result.Phase)
+	assert.Equal(t, 0, result.RollbackStep)
+	assert.Equal(t, []string{"progressing", "advance", "advance", "advance", "promote", "finalising", "succeeded"}, verdicts(result))
+	assert.Equal(t, 30, result.Steps[3].CanaryWeight)
+
+	// the metrics are queried in the analysis order and the last value is repeated
+	assert.Empty(t, result.Steps[1].Metrics)
+	require.Len(t, result.Steps[3].Metrics, 3)
+	assert.Equal(t, SimulationValue{Name: "request-duration", Value: 250}, result.Steps[3].Metrics[1])
+	assert.Equal(t, "error-budget", result.Steps[3].Metrics[2].Name)
+	require.Len(t, result.Steps[3].Webhooks, 1)
+	assert.True(t, result.Steps[3].Webhooks[0].Passed)
+}
+
+func TestSimulate_Rollback(t *testing.T) {
+	timeline := SimulationTimeline{
+		Metrics: map[string][]float64{
+			"request-success-rate": {100, 95},
+			"request-duration":     {120},
+			"error-budget":         {1},
+		},
+	}
+
+	result, err := Simulate(newSimulationTestCanary(), nil, nil, timeline, SimulationOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, result.Phase)
+	assert.Equal(t, []string{"progressing", "advance", "advance", "halt", "halt", "rollback"}, verdicts(result))
+	assert.Equal(t, 5, result.RollbackStep)
+	assert.Equal(t, 2, result.Steps[4].FailedChecks)
+	assert.Equal(t, 0, result.Steps[5].CanaryWeight)
+}
+
+func TestSimulate_Webhooks(t *testing.T) {
+	cd := newSimulationTestCanary()
+	cd.Spec.Analysis.Metrics = nil
+	timeline := SimulationTimeline{
+		Webhooks: map[string][]bool{
+			"load-test": {true, false, true},
+		},
+	}
+
+	result, err := Simulate(cd, nil, nil, timeline, SimulationOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, result.Phase)
+	assert.Equal(t, "halt", result.Steps[3].Verdict)
+	assert.False(t, result.Steps[3].Webhooks[0].Passed)
+	assert.Equal(t, 1, result.Steps[3].FailedChecks)
+}
+
+func TestSimulate_MissingMetric(t *testing.T) {
+	cd := newSimulationTestCanary()
+	cd.Spec.Analysis.Threshold = 1
+
+	result, err := Simulate(cd, nil, nil, SimulationTimeline{}, SimulationOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, result.Phase)
+	assert.Equal(t, 3, result.RollbackStep)
+	assert.Contains(t, strings.Join(result.Steps[2].Events, "\n"), "no values found")
+}
+
+func TestSimulate_MissingAnalysis(t *testing.T) {
+	cd := newSimulationTestCanary()
+	cd.Spec.Analysis = nil
+
+	_, err := Simulate(cd, nil, nil, SimulationTimeline{}, SimulationOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has no analysis")
+
+	// the analysis can be set by the canary template
+	cd.Spec.TemplateRef = &flaggerv1.CanaryTemplateRef{Name: "progressive"}
+	template := &flaggerv1.CanaryTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "progressive"},
+		Spec: flaggerv1.CanaryTemplateSpec{
+			Analysis: newSimulationTestCanary().Spec.Analysis,
+		},
+	}
+	result, err := Simulate(cd, nil, []runtime.Object{template}, SimulationTimeline{}, SimulationOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Steps)
+}
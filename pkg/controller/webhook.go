@@ -63,6 +63,14 @@ func callWebhook(webhook string, payload interface{}, timeout string) error {
 	return nil
 }
 
+// callWebhook runs the canary webhook with the controller webhook caller
+func (c *Controller) callWebhook(name string, namespace string, phase flaggerv1.CanaryPhase, w flaggerv1.CanaryWebhook) error {
+	if c.webhookCaller != nil {
+		return c.webhookCaller(name, namespace, phase, w)
+	}
+	return CallWebhook(name, namespace, phase, w)
+}
+
 // CallWebhook does a HTTP POST to an external service and
 // returns an error if the response status code is non-2xx
 func CallWebhook(name string, namespace string, phase flaggerv1.CanaryPhase, w flaggerv1.CanaryWebhook) error {
@@ -0,0 +1,70 @@
+package flaggerctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// Promote requests the promotion of the canary without completing the analysis,
+// Flagger applies it on the next analysis run once the canary is ready
+func (c *Client) Promote(ctx context.Context, namespace string, name string) error {
+	if err := c.setAnnotation(ctx, namespace, name, flaggerv1.PromoteAnnotation, "true"); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "canary %s.%s promotion requested\n", name, namespace)
+	return nil
+}
+
+// Abort requests the rollback of the analysis underway
+func (c *Client) Abort(ctx context.Context, namespace string, name string) error {
+	if err := c.setAnnotation(ctx, namespace, name, flaggerv1.AbortAnnotation, "true"); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "canary %s.%s abort requested\n", name, namespace)
+	return nil
+}
+
+// Pause halts the canary advancement until Resume is called
+func (c *Client) Pause(ctx context.Context, namespace string, name string) error {
+	if err := c.setAnnotation(ctx, namespace, name, flaggerv1.PauseAnnotation, "true"); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "canary %s.%s paused\n", name, namespace)
+	return nil
+}
+
+// Resume removes the pause annotation
+func (c *Client) Resume(ctx context.Context, namespace string, name string) error {
+	if err := c.setAnnotation(ctx, namespace, name, flaggerv1.PauseAnnotation, nil); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "canary %s.%s resumed\n", name, namespace)
+	return nil
+}
+
+// setAnnotation merge patches the canary annotation, a nil value removes the annotation
+func (c *Client) setAnnotation(ctx context.Context, namespace string, name string, key string, value interface{}) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				key: value,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("annotation patch marshal error: %w", err)
+	}
+
+	_, err = c.flaggerClient.FlaggerV1beta1().Canaries(namespace).
+		Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("canary %s.%s patch error: %w", name, namespace, err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package flaggerctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+// Client operates the canaries of a cluster through the Kubernetes API
+type Client struct {
+	kubeClient    kubernetes.Interface
+	flaggerClient clientset.Interface
+	gateClient    GateClient
+	out           io.Writer
+}
+
+// NewClient returns a client that writes its output to out,
+// the loadtester gates are reached through the Kubernetes API server service proxy
+func NewClient(kubeClient kubernetes.Interface, flaggerClient clientset.Interface, gateToken string, out io.Writer) *Client {
+	return &Client{
+		kubeClient:    kubeClient,
+		flaggerClient: flaggerClient,
+		gateClient:    NewServiceProxyGateClient(kubeClient, gateToken),
+		out:           out,
+	}
+}
+
+// WithGateClient overwrites the client used to call the loadtester gate API
+func (c *Client) WithGateClient(gateClient GateClient) *Client {
+	c.gateClient = gateClient
+	return c
+}
+
+func (c *Client) getCanary(ctx context.Context, namespace string, name string) (*flaggerv1.Canary, error) {
+	cd, err := c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("canary %s.%s get query error: %w", name, namespace, err)
+	}
+	return cd, nil
+}
+
+// withTemplate returns a copy of the canary merged with the referenced template,
+// the canary is returned unchanged when the template can't be read
+func (c *Client) withTemplate(ctx context.Context, cd *flaggerv1.Canary) *flaggerv1.Canary {
+	ref := cd.Spec.TemplateRef
+	if ref == nil {
+		return cd
+	}
+
+	switch ref.Kind {
+	case "", flaggerv1.CanaryTemplateKind:
+		template, err := c.flaggerClient.FlaggerV1beta1().CanaryTemplates(cd.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err == nil {
+			return canary.MergeTemplate(cd, template.Spec)
+		}
+	case flaggerv1.ClusterCanaryTemplateKind:
+		template, err := c.flaggerClient.FlaggerV1beta1().ClusterCanaryTemplates().Get(ctx, ref.Name, metav1.GetOptions{})
+		if err == nil {
+			return canary.MergeTemplate(cd, template.Spec)
+		}
+	}
+	return cd
+}
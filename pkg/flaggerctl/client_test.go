@@ -0,0 +1,141 @@
+package flaggerctl
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	fakeFlagger "github.com/weaveworks/flagger/pkg/client/clientset/versioned/fake"
+)
+
+type clientFixture struct {
+	client *Client
+	out    *bytes.Buffer
+	gates  *fakeGateClient
+}
+
+func newClientFixture(kubeObjects []runtime.Object, flaggerObjects ...runtime.Object) clientFixture {
+	if len(flaggerObjects) == 0 {
+		flaggerObjects = []runtime.Object{newTestCanary()}
+	}
+	out := &bytes.Buffer{}
+	gates := &fakeGateClient{gates: map[string]bool{}}
+	client := NewClient(fake.NewSimpleClientset(kubeObjects...), fakeFlagger.NewSimpleClientset(flaggerObjects...), "", out).
+		WithGateClient(gates)
+	return clientFixture{client: client, out: out, gates: gates}
+}
+
+func newTestCanary() *flaggerv1.Canary {
+	minRate := float64(99)
+	return &flaggerv1.Canary{
+		TypeMeta: metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo",
+		},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{
+				Name:       "podinfo",
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+			},
+			Service: flaggerv1.CanaryService{
+				Port: 9898,
+			},
+			Analysis: &flaggerv1.CanaryAnalysis{
+				Threshold:  5,
+				StepWeight: 10,
+				Metrics: []flaggerv1.CanaryMetric{
+					{
+						Name:           "request-success-rate",
+						ThresholdRange: &flaggerv1.CanaryThresholdRange{Min: &minRate},
+					},
+				},
+				Webhooks: []flaggerv1.CanaryWebhook{
+					{
+						Name: "gate",
+						Type: flaggerv1.ConfirmRolloutHook,
+						URL:  "http://flagger-loadtester.test/gate/check",
+					},
+					{
+						Name: "load-test",
+						Type: flaggerv1.RolloutHook,
+						URL:  "http://flagger-loadtester.test/",
+					},
+				},
+			},
+		},
+		Status: flaggerv1.CanaryStatus{
+			Phase:        flaggerv1.CanaryPhaseProgressing,
+			CanaryWeight: 30,
+			FailedChecks: 1,
+			Metrics: []flaggerv1.CanaryMetricStatus{
+				{Name: "request-success-rate", Value: "99.50", LastUpdateTime: metav1.Now()},
+			},
+		},
+	}
+}
+
+func TestClient_Actions(t *testing.T) {
+	mocks := newClientFixture(nil)
+	ctx := context.TODO()
+
+	require.NoError(t, mocks.client.Pause(ctx, "default", "podinfo"))
+	require.NoError(t, mocks.client.Promote(ctx, "default", "podinfo"))
+	require.NoError(t, mocks.client.Abort(ctx, "default", "podinfo"))
+
+	cd, err := mocks.client.getCanary(ctx, "default", "podinfo")
+	require.NoError(t, err)
+	assert.Equal(t, "true", cd.Annotations[flaggerv1.PauseAnnotation])
+	assert.Equal(t, "true", cd.Annotations[flaggerv1.PromoteAnnotation])
+	assert.Equal(t, "true", cd.Annotations[flaggerv1.AbortAnnotation])
+
+	require.NoError(t, mocks.client.Resume(ctx, "default", "podinfo"))
+	cd, err = mocks.client.getCanary(ctx, "default", "podinfo")
+	require.NoError(t, err)
+	assert.NotContains(t, cd.Annotations, flaggerv1.PauseAnnotation)
+	assert.Contains(t, cd.Annotations, flaggerv1.PromoteAnnotation)
+
+	assert.Error(t, mocks.client.Pause(ctx, "default", "missing"))
+}
+
+func TestClient_Events(t *testing.T) {
+	events := []runtime.Object{
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "default", Name: "podinfo.2"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Canary", Name: "podinfo"},
+			Type:           corev1.EventTypeNormal,
+			Message:        "Advance podinfo.default canary weight 20",
+			LastTimestamp:  metav1.Unix(200, 0),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "default", Name: "podinfo.1"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Canary", Name: "podinfo"},
+			Type:           corev1.EventTypeNormal,
+			Message:        "Advance podinfo.default canary weight 10",
+			LastTimestamp:  metav1.Unix(100, 0),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "default", Name: "podinfo.pod"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "podinfo"},
+			Message:        "Started container",
+		},
+	}
+	mocks := newClientFixture(events)
+
+	require.NoError(t, mocks.client.Events(context.TODO(), "default", "podinfo", false))
+	out := mocks.out.String()
+	assert.NotContains(t, out, "Started container")
+	first := bytes.Index(mocks.out.Bytes(), []byte("canary weight 10"))
+	second := bytes.Index(mocks.out.Bytes(), []byte("canary weight 20"))
+	require.True(t, first >= 0 && second >= 0, out)
+	assert.True(t, first < second)
+}
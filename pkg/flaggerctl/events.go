@@ -0,0 +1,94 @@
+package flaggerctl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// Events prints the analysis events recorded for a canary,
+// if follow is true the new events are printed until the context is cancelled
+func (c *Client) Events(ctx context.Context, namespace string, name string, follow bool) error {
+	if _, err := c.getCanary(ctx, namespace, name); err != nil {
+		return err
+	}
+
+	selector := fields.Set{
+		"involvedObject.kind": flaggerv1.CanaryKind,
+		"involvedObject.name": name,
+	}.AsSelector().String()
+
+	list, err := c.kubeClient.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return fmt.Errorf("events list query error: %w", err)
+	}
+
+	items := make([]corev1.Event, 0, len(list.Items))
+	for _, event := range list.Items {
+		if isCanaryEvent(&event, name) {
+			items = append(items, event)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return eventTime(&items[i]).Before(eventTime(&items[j]))
+	})
+	for i := range items {
+		c.printEvent(&items[i])
+	}
+
+	if !follow {
+		return nil
+	}
+
+	watcher, err := c.kubeClient.CoreV1().Events(namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector:   selector,
+		ResourceVersion: list.ResourceVersion,
+	})
+	if err != nil {
+		return fmt.Errorf("events watch error: %w", err)
+	}
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil
+			}
+			if e.Type != watch.Added && e.Type != watch.Modified {
+				continue
+			}
+			if event, ok := e.Object.(*corev1.Event); ok && isCanaryEvent(event, name) {
+				c.printEvent(event)
+			}
+		}
+	}
+}
+
+func (c *Client) printEvent(event *corev1.Event) {
+	fmt.Fprintf(c.out, "%s  %-7s  %s\n", eventTime(event).Format(time.RFC3339), event.Type, event.Message)
+}
+
+func isCanaryEvent(event *corev1.Event, name string) bool {
+	return event.InvolvedObject.Kind == flaggerv1.CanaryKind && event.InvolvedObject.Name == name
+}
+
+func eventTime(event *corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
@@ -0,0 +1,250 @@
+package flaggerctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/loadtester"
+)
+
+// GateClient calls the gate API of a loadtester service
+type GateClient interface {
+	// Do sends the request to the path of the service and returns the HTTP status code and the response body
+	Do(ctx context.Context, svc LoadtesterService, method string, path string, body []byte) (int, []byte, error)
+}
+
+// LoadtesterService references the Kubernetes service of a loadtester
+type LoadtesterService struct {
+	Name      string
+	Namespace string
+	Port      string
+}
+
+func (s LoadtesterService) String() string {
+	return fmt.Sprintf("%s.%s:%s", s.Name, s.Namespace, s.Port)
+}
+
+// ParseLoadtesterService parses a webhook URL or a service address
+// in the <name>[.<namespace>[.svc...]][:<port>] format,
+// the namespace defaults to the canary namespace and the port to 80
+func ParseLoadtesterService(address string, defaultNamespace string) (LoadtesterService, error) {
+	host := address
+	if strings.Contains(address, "://") {
+		u, err := url.Parse(address)
+		if err != nil {
+			return LoadtesterService{}, fmt.Errorf("invalid loadtester URL %s: %w", address, err)
+		}
+		host = u.Host
+	}
+	if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+
+	port := "80"
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		host, port = h, p
+	}
+
+	parts := strings.Split(host, ".")
+	if host == "" || parts[0] == "" {
+		return LoadtesterService{}, fmt.Errorf("invalid loadtester address %s", address)
+	}
+	svc := LoadtesterService{Name: parts[0], Namespace: defaultNamespace, Port: port}
+	if len(parts) > 1 && parts[1] != "" {
+		svc.Namespace = parts[1]
+	}
+	return svc, nil
+}
+
+// GateOptions holds the metadata sent with the gate open and close requests
+type GateOptions struct {
+	// Rollback selects the rollback gate instead of the confirm gate
+	Rollback bool
+	// TTL closes the gate automatically after the duration
+	TTL time.Duration
+	// Reason is recorded in the gate audit trail
+	Reason string
+}
+
+// ListGates prints the gates stored by the loadtesters referenced in the canary webhooks
+func (c *Client) ListGates(ctx context.Context, namespace string, name string, address string) error {
+	cd, err := c.getCanary(ctx, namespace, name)
+	if err != nil {
+		return err
+	}
+	services, err := loadtesterServices(cd, address)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "LOADTESTER\tGATE\tOPEN\tUPDATED BY\tEXPIRES")
+	for _, svc := range services {
+		code, body, err := c.gateClient.Do(ctx, svc, http.MethodGet, "/gate/list", nil)
+		if err != nil {
+			return fmt.Errorf("loadtester %s gate list error: %w", svc, err)
+		}
+		if code != http.StatusOK {
+			return fmt.Errorf("loadtester %s gate list failed with status %d: %s", svc, code, string(body))
+		}
+
+		var gates []loadtester.Gate
+		if err := json.Unmarshal(body, &gates); err != nil {
+			return fmt.Errorf("loadtester %s gate list decoding error: %w", svc, err)
+		}
+		for _, gate := range gates {
+			if !isCanaryGate(gate.Name, cd) {
+				continue
+			}
+			expires := "-"
+			if gate.ExpiresAt != nil {
+				expires = gate.ExpiresAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%v\t%s\t%s\n", svc, gate.Name, gate.IsOpen(time.Now()), gate.UpdatedBy, expires)
+		}
+	}
+	return w.Flush()
+}
+
+// OpenGate opens the confirm or rollback gate of the canary on its loadtesters
+func (c *Client) OpenGate(ctx context.Context, namespace string, name string, address string, opts GateOptions) error {
+	return c.toggleGate(ctx, namespace, name, address, "open", opts)
+}
+
+// CloseGate closes the confirm or rollback gate of the canary on its loadtesters
+func (c *Client) CloseGate(ctx context.Context, namespace string, name string, address string, opts GateOptions) error {
+	return c.toggleGate(ctx, namespace, name, address, "close", opts)
+}
+
+func (c *Client) toggleGate(ctx context.Context, namespace string, name string, address string, action string, opts GateOptions) error {
+	cd, err := c.getCanary(ctx, namespace, name)
+	if err != nil {
+		return err
+	}
+	services, err := loadtesterServices(cd, address)
+	if err != nil {
+		return err
+	}
+
+	payload := flaggerv1.CanaryWebhookPayload{
+		Name:      cd.Name,
+		Namespace: cd.Namespace,
+		Phase:     cd.Status.Phase,
+		Metadata:  map[string]string{},
+	}
+	if opts.TTL > 0 && action == "open" {
+		payload.Metadata["ttl"] = opts.TTL.String()
+	}
+	if opts.Reason != "" {
+		payload.Metadata["reason"] = opts.Reason
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("gate payload marshal error: %w", err)
+	}
+
+	kind := "gate"
+	if opts.Rollback {
+		kind = "rollback"
+	}
+	path := fmt.Sprintf("/%s/%s", kind, action)
+	for _, svc := range services {
+		code, res, err := c.gateClient.Do(ctx, svc, http.MethodPost, path, body)
+		if err != nil {
+			return fmt.Errorf("loadtester %s %s error: %w", svc, path, err)
+		}
+		if code >= 400 {
+			return fmt.Errorf("loadtester %s %s failed with status %d: %s", svc, path, code, string(res))
+		}
+		fmt.Fprintf(c.out, "%s %s %sed on %s\n", cd.Name, kind, strings.TrimSuffix(action, "e"), svc)
+	}
+	return nil
+}
+
+// loadtesterServices returns the loadtesters referenced by the gate webhooks of the canary
+// or the service set with the address argument
+func loadtesterServices(cd *flaggerv1.Canary, address string) ([]LoadtesterService, error) {
+	if address != "" {
+		svc, err := ParseLoadtesterService(address, cd.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		return []LoadtesterService{svc}, nil
+	}
+
+	var services []LoadtesterService
+	seen := make(map[string]bool)
+	if analysis := cd.GetAnalysis(); analysis != nil {
+		for _, webhook := range analysis.Webhooks {
+			switch webhook.Type {
+			case flaggerv1.ConfirmRolloutHook, flaggerv1.ConfirmPromotionHook, flaggerv1.RollbackHook:
+			default:
+				continue
+			}
+			svc, err := ParseLoadtesterService(webhook.URL, cd.Namespace)
+			if err != nil {
+				return nil, fmt.Errorf("webhook %s: %w", webhook.Name, err)
+			}
+			if !seen[svc.String()] {
+				seen[svc.String()] = true
+				services = append(services, svc)
+			}
+		}
+	}
+	if len(services) == 0 {
+		return nil, fmt.Errorf("canary %s.%s has no gate webhooks, use the loadtester flag to select a service", cd.Name, cd.Namespace)
+	}
+	return services, nil
+}
+
+func isCanaryGate(key string, cd *flaggerv1.Canary) bool {
+	gate := fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)
+	return key == gate || key == "rollback."+gate
+}
+
+// ServiceProxyGateClient calls the loadtester through the Kubernetes API server service proxy
+type ServiceProxyGateClient struct {
+	kubeClient kubernetes.Interface
+	token      string
+}
+
+// NewServiceProxyGateClient returns a gate client that authenticates to the loadtester with the bearer token
+func NewServiceProxyGateClient(kubeClient kubernetes.Interface, token string) *ServiceProxyGateClient {
+	return &ServiceProxyGateClient{kubeClient: kubeClient, token: token}
+}
+
+// Do sends the request to the loadtester service proxy
+func (g *ServiceProxyGateClient) Do(ctx context.Context, svc LoadtesterService, method string, path string, body []byte) (int, []byte, error) {
+	req := g.kubeClient.CoreV1().RESTClient().Verb(method).
+		Namespace(svc.Namespace).
+		Resource("services").
+		Name(fmt.Sprintf("%s:%s", svc.Name, svc.Port)).
+		SubResource("proxy").
+		Suffix(path)
+	if body != nil {
+		req = req.SetHeader("Content-Type", "application/json").Body(body)
+	}
+	if g.token != "" {
+		// the Authorization header is consumed by the API server and is not forwarded to the service
+		req = req.SetHeader(loadtester.GateTokenHeader, g.token)
+	}
+
+	result := req.Do(ctx)
+	var code int
+	result.StatusCode(&code)
+	res, err := result.Raw()
+	if code == 0 {
+		return 0, nil, err
+	}
+	return code, res, nil
+}
@@ -0,0 +1,104 @@
+package flaggerctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/loadtester"
+)
+
+// fakeGateClient emulates the loadtester gate API
+type fakeGateClient struct {
+	gates    map[string]bool
+	services []string
+}
+
+func (f *fakeGateClient) Do(_ context.Context, svc LoadtesterService, method string, path string, body []byte) (int, []byte, error) {
+	f.services = append(f.services, svc.String())
+	if method == http.MethodGet && path == "/gate/list" {
+		var gates []loadtester.Gate
+		for name, open := range f.gates {
+			gates = append(gates, loadtester.Gate{Name: name, Open: open, UpdatedBy: "alice"})
+		}
+		data, err := json.Marshal(gates)
+		return http.StatusOK, data, err
+	}
+
+	payload := &flaggerv1.CanaryWebhookPayload{}
+	if err := json.Unmarshal(body, payload); err != nil {
+		return http.StatusBadRequest, nil, nil
+	}
+	key := fmt.Sprintf("%s.%s", payload.Name, payload.Namespace)
+	if strings.HasPrefix(path, "/rollback/") {
+		key = "rollback." + key
+	}
+	switch {
+	case strings.HasSuffix(path, "/open"):
+		f.gates[key] = true
+	case strings.HasSuffix(path, "/close"):
+		f.gates[key] = false
+	default:
+		return http.StatusNotFound, nil, nil
+	}
+	return http.StatusAccepted, nil, nil
+}
+
+func TestParseLoadtesterService(t *testing.T) {
+	tests := []struct {
+		address  string
+		expected string
+	}{
+		{"http://flagger-loadtester.test/gate/check", "flagger-loadtester.test:80"},
+		{"http://flagger-loadtester.test.svc.cluster.local:8080/", "flagger-loadtester.test:8080"},
+		{"http://flagger-loadtester/", "flagger-loadtester.default:80"},
+		{"flagger-loadtester.test:9090", "flagger-loadtester.test:9090"},
+	}
+	for _, tt := range tests {
+		svc, err := ParseLoadtesterService(tt.address, "default")
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, svc.String())
+	}
+
+	_, err := ParseLoadtesterService("http:///gate/check", "default")
+	assert.Error(t, err)
+}
+
+func TestClient_Gates(t *testing.T) {
+	mocks := newClientFixture(nil)
+	ctx := context.TODO()
+
+	require.NoError(t, mocks.client.OpenGate(ctx, "default", "podinfo", "", GateOptions{TTL: time.Hour}))
+	require.NoError(t, mocks.client.CloseGate(ctx, "default", "podinfo", "", GateOptions{Rollback: true}))
+	assert.True(t, mocks.gates.gates["podinfo.default"])
+	assert.False(t, mocks.gates.gates["rollback.podinfo.default"])
+
+	// only the loadtester referenced by the gate webhook is called
+	for _, svc := range mocks.gates.services {
+		assert.Equal(t, "flagger-loadtester.test:80", svc)
+	}
+
+	mocks.gates.gates["other.default"] = true
+	mocks.out.Reset()
+	require.NoError(t, mocks.client.ListGates(ctx, "default", "podinfo", ""))
+	out := mocks.out.String()
+	assert.Regexp(t, `podinfo.default\s+true\s+alice`, out)
+	assert.Regexp(t, `rollback.podinfo.default\s+false`, out)
+	assert.NotContains(t, out, "other.default")
+
+	require.NoError(t, mocks.client.OpenGate(ctx, "default", "podinfo", "loadtester.ops:8080", GateOptions{}))
+	assert.Equal(t, "loadtester.ops:8080", mocks.gates.services[len(mocks.gates.services)-1])
+
+	cd := newTestCanary()
+	cd.Spec.Analysis.Webhooks = nil
+	mocks = newClientFixture(nil, cd)
+	assert.Error(t, mocks.client.OpenGate(ctx, "default", "podinfo", "", GateOptions{}))
+}
@@ -0,0 +1,333 @@
+package flaggerctl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/yaml"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	knative "github.com/weaveworks/flagger/pkg/apis/knative/v1"
+	"github.com/weaveworks/flagger/pkg/canary"
+	fakeFlagger "github.com/weaveworks/flagger/pkg/client/clientset/versioned/fake"
+	flaggerscheme "github.com/weaveworks/flagger/pkg/client/clientset/versioned/scheme"
+	"github.com/weaveworks/flagger/pkg/router"
+	"github.com/weaveworks/flagger/pkg/validation"
+)
+
+// RenderOptions holds the settings the Flagger controller would run with
+type RenderOptions struct {
+	// Provider overwrites the canary provider, defaults to the canary spec provider or istio
+	Provider string
+	// Weight is the traffic percentage routed to the canary
+	Weight int
+	// SelectorLabels are the pod labels used to create the pod selectors
+	SelectorLabels []string
+	// IngressAnnotationsPrefix is the prefix of the NGINX canary annotations
+	IngressAnnotationsPrefix string
+}
+
+var documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// Render prints as YAML the primary workload, services and routing objects Flagger would generate
+// for the canary and target manifests, the canary and router controllers are run against fake clientsets
+// so no cluster is required
+func Render(canaryManifest []byte, targetManifests []byte, opts RenderOptions, out io.Writer) error {
+	cd, err := decodeCanary(canaryManifest)
+	if err != nil {
+		return err
+	}
+	if errs := validation.ValidateCanary(cd); len(errs) > 0 {
+		return fmt.Errorf("canary %s.%s spec is invalid: %v", cd.Name, cd.Namespace, errs.ToAggregate())
+	}
+	if opts.Weight < 0 || opts.Weight > 100 {
+		return fmt.Errorf("weight %d is not in the 0-100 range", opts.Weight)
+	}
+
+	kubeObjects, flaggerObjects, err := decodeTargets(targetManifests, cd.Namespace)
+	if err != nil {
+		return err
+	}
+	cd, err = mergeCanaryTemplate(cd, flaggerObjects)
+	if err != nil {
+		return err
+	}
+	if cd.GetAnalysis() == nil {
+		return fmt.Errorf("canary %s.%s has no analysis", cd.Name, cd.Namespace)
+	}
+	kubeClient := fake.NewSimpleClientset(kubeObjects...)
+	flaggerClient := fakeFlagger.NewSimpleClientset(append(flaggerObjects, cd)...)
+
+	provider := opts.Provider
+	if provider == "" {
+		provider = cd.Spec.Provider
+	}
+	if provider == "" {
+		provider = "istio"
+	}
+	if cd.Spec.TargetRef.IsKnativeService() {
+		provider = flaggerv1.KnativeProvider
+	}
+
+	logger := zap.NewNop().Sugar()
+	configTracker := &canary.ConfigTracker{
+		Logger:        logger,
+		KubeClient:    kubeClient,
+		FlaggerClient: flaggerClient,
+	}
+	canaryFactory := canary.NewFactory(kubeClient, flaggerClient, configTracker, opts.SelectorLabels, logger)
+	routerFactory := router.NewFactory(nil, kubeClient, flaggerClient, opts.IngressAnnotationsPrefix, logger, flaggerClient)
+
+	// the target readiness can't be observed without a cluster,
+	// the canary is rendered as if it was already initialized
+	cd.Status.Phase = flaggerv1.CanaryPhaseInitialized
+
+	canaryController := canaryFactory.Controller(cd.Spec.TargetRef)
+	labelSelector, ports, err := canaryController.GetMetadata(cd)
+	if err != nil {
+		return err
+	}
+	kubeRouter := routerFactory.KubernetesRouter(cd.Spec.TargetRef.Kind, labelSelector, ports)
+	if err := kubeRouter.Initialize(cd); err != nil {
+		return err
+	}
+	if err := canaryController.Initialize(cd); err != nil {
+		return err
+	}
+	if err := kubeRouter.Reconcile(cd); err != nil {
+		return err
+	}
+	meshRouter := routerFactory.MeshRouter(provider)
+	if err := meshRouter.Reconcile(cd); err != nil {
+		return err
+	}
+	if opts.Weight > 0 {
+		if err := meshRouter.SetRoutes(cd, 100-opts.Weight, opts.Weight, false); err != nil {
+			return err
+		}
+	}
+
+	objects, err := changedObjects(&kubeClient.Fake, kubeClient.Tracker(), kubescheme.Scheme)
+	if err != nil {
+		return err
+	}
+	flaggerChanged, err := changedObjects(&flaggerClient.Fake, flaggerClient.Tracker(), flaggerscheme.Scheme)
+	if err != nil {
+		return err
+	}
+	objects = append(objects, flaggerChanged...)
+
+	for _, obj := range objects {
+		if accessor, err := meta.Accessor(obj); err == nil {
+			accessor.SetResourceVersion("")
+			accessor.SetManagedFields(nil)
+		}
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("%s encoding error: %w", obj.GetObjectKind().GroupVersionKind().Kind, err)
+		}
+		fmt.Fprintf(out, "---\n%s", data)
+	}
+	return nil
+}
+
+func decodeCanary(manifest []byte) (*flaggerv1.Canary, error) {
+	var cd *flaggerv1.Canary
+	for _, doc := range splitDocuments(manifest) {
+		typeMeta := metav1.TypeMeta{}
+		if err := yaml.Unmarshal(doc, &typeMeta); err != nil {
+			return nil, fmt.Errorf("canary manifest decoding error: %w", err)
+		}
+		if typeMeta.Kind != flaggerv1.CanaryKind {
+			continue
+		}
+		if cd != nil {
+			return nil, fmt.Errorf("canary manifest contains more than one canary")
+		}
+		cd = &flaggerv1.Canary{}
+		if err := yaml.Unmarshal(doc, cd); err != nil {
+			return nil, fmt.Errorf("canary manifest decoding error: %w", err)
+		}
+	}
+	if cd == nil {
+		return nil, fmt.Errorf("canary manifest contains no canary")
+	}
+	if cd.Namespace == "" {
+		cd.Namespace = metav1.NamespaceDefault
+	}
+	return cd, nil
+}
+
+// mergeCanaryTemplate merges the template referenced by the canary, the template must be part of the target manifests
+func mergeCanaryTemplate(cd *flaggerv1.Canary, flaggerObjects []runtime.Object) (*flaggerv1.Canary, error) {
+	ref := cd.Spec.TemplateRef
+	if ref == nil {
+		return cd, nil
+	}
+
+	for _, obj := range flaggerObjects {
+		switch o := obj.(type) {
+		case *flaggerv1.CanaryTemplate:
+			if (ref.Kind == "" || ref.Kind == flaggerv1.CanaryTemplateKind) && o.Name == ref.Name && o.Namespace == cd.Namespace {
+				return canary.MergeTemplate(cd, o.Spec), nil
+			}
+		case *flaggerv1.ClusterCanaryTemplate:
+			if ref.Kind == flaggerv1.ClusterCanaryTemplateKind && o.Name == ref.Name {
+				return canary.MergeTemplate(cd, o.Spec), nil
+			}
+		}
+	}
+
+	kind := ref.Kind
+	if kind == "" {
+		kind = flaggerv1.CanaryTemplateKind
+	}
+	return nil, fmt.Errorf("%s %s referenced by canary %s.%s not found in the target manifests", kind, ref.Name, cd.Name, cd.Namespace)
+}
+
+// decodeTargets returns the objects of the target manifests split by the clientset serving them
+func decodeTargets(manifests []byte, namespace string) (kubeObjects []runtime.Object, flaggerObjects []runtime.Object, err error) {
+	for _, doc := range splitDocuments(manifests) {
+		typeMeta := metav1.TypeMeta{}
+		if err := yaml.Unmarshal(doc, &typeMeta); err != nil {
+			return nil, nil, fmt.Errorf("target manifest decoding error: %w", err)
+		}
+
+		var obj runtime.Object
+		fromFlagger := false
+		switch {
+		case typeMeta.Kind == "Deployment":
+			obj = &appsv1.Deployment{}
+		case typeMeta.Kind == "DaemonSet":
+			obj = &appsv1.DaemonSet{}
+		case typeMeta.Kind == "Service" && strings.HasPrefix(typeMeta.APIVersion, knative.SchemeGroupVersion.Group+"/"):
+			obj, fromFlagger = &knative.Service{}, true
+		case typeMeta.Kind == "Service":
+			obj = &corev1.Service{}
+		case typeMeta.Kind == "ConfigMap":
+			obj = &corev1.ConfigMap{}
+		case typeMeta.Kind == "Secret":
+			obj = &corev1.Secret{}
+		case typeMeta.Kind == "Ingress":
+			obj = &networkingv1beta1.Ingress{}
+		case typeMeta.Kind == "HorizontalPodAutoscaler" && typeMeta.APIVersion == autoscalingv2beta1.SchemeGroupVersion.String():
+			obj = &autoscalingv2beta1.HorizontalPodAutoscaler{}
+		case typeMeta.Kind == "HorizontalPodAutoscaler":
+			// Flagger reads the autoscaling/v2 HPAs through the autoscaling/v2beta2 API
+			obj = &autoscalingv2beta2.HorizontalPodAutoscaler{}
+		case typeMeta.Kind == "MetricTemplate":
+			obj, fromFlagger = &flaggerv1.MetricTemplate{}, true
+		case typeMeta.Kind == flaggerv1.CanaryTemplateKind:
+			obj, fromFlagger = &flaggerv1.CanaryTemplate{}, true
+		case typeMeta.Kind == flaggerv1.ClusterCanaryTemplateKind:
+			obj, fromFlagger = &flaggerv1.ClusterCanaryTemplate{}, true
+		default:
+			return nil, nil, fmt.Errorf("target manifest kind %s %s is not supported", typeMeta.APIVersion, typeMeta.Kind)
+		}
+
+		if err := yaml.Unmarshal(doc, obj); err != nil {
+			return nil, nil, fmt.Errorf("%s decoding error: %w", typeMeta.Kind, err)
+		}
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return nil, nil, err
+		}
+		if accessor.GetNamespace() == "" && typeMeta.Kind != flaggerv1.ClusterCanaryTemplateKind {
+			accessor.SetNamespace(namespace)
+		}
+
+		if fromFlagger {
+			flaggerObjects = append(flaggerObjects, obj)
+		} else {
+			kubeObjects = append(kubeObjects, obj)
+		}
+	}
+	return kubeObjects, flaggerObjects, nil
+}
+
+func splitDocuments(manifest []byte) [][]byte {
+	var docs [][]byte
+	for _, doc := range documentSeparator.Split(string(manifest), -1) {
+		if len(bytes.TrimSpace([]byte(doc))) == 0 {
+			continue
+		}
+		docs = append(docs, []byte(doc))
+	}
+	return docs
+}
+
+// changedObjects returns the current state of the objects created or modified through the fake clientset
+// in the order they were first changed, the canaries are skipped
+func changedObjects(fakeClient *k8stesting.Fake, tracker k8stesting.ObjectTracker, scheme *runtime.Scheme) ([]runtime.Object, error) {
+	type objectRef struct {
+		resource  schema.GroupVersionResource
+		namespace string
+		name      string
+	}
+
+	var refs []objectRef
+	seen := make(map[objectRef]bool)
+	for _, action := range fakeClient.Actions() {
+		if action.GetResource().Resource == "canaries" {
+			continue
+		}
+
+		var name string
+		switch a := action.(type) {
+		case k8stesting.CreateAction:
+			accessor, err := meta.Accessor(a.GetObject())
+			if err != nil {
+				return nil, err
+			}
+			name = accessor.GetName()
+		case k8stesting.UpdateAction:
+			accessor, err := meta.Accessor(a.GetObject())
+			if err != nil {
+				return nil, err
+			}
+			name = accessor.GetName()
+		case k8stesting.PatchAction:
+			name = a.GetName()
+		default:
+			continue
+		}
+
+		ref := objectRef{resource: action.GetResource(), namespace: action.GetNamespace(), name: name}
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	objects := make([]runtime.Object, 0, len(refs))
+	for _, ref := range refs {
+		obj, err := tracker.Get(ref.resource, ref.namespace, ref.name)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s.%s get error: %w", ref.resource.Resource, ref.name, ref.namespace, err)
+		}
+		obj = obj.DeepCopyObject()
+		gvks, _, err := scheme.ObjectKinds(obj)
+		if err != nil {
+			return nil, err
+		}
+		obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
@@ -0,0 +1,216 @@
+package flaggerctl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const renderCanary = `
+apiVersion: flagger.app/v1beta1
+kind: Canary
+metadata:
+  name: podinfo
+  namespace: test
+spec:
+  targetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: podinfo
+  ingressRef:
+    apiVersion: networking.k8s.io/v1beta1
+    kind: Ingress
+    name: podinfo
+  service:
+    port: 9898
+    meshName: global
+  analysis:
+    interval: 1m
+    threshold: 5
+    maxWeight: 50
+    stepWeight: 10
+`
+
+const renderTarget = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: podinfo
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: podinfo
+  template:
+    metadata:
+      labels:
+        app: podinfo
+    spec:
+      containers:
+      - name: podinfod
+        image: stefanprodan/podinfo:3.1.0
+        ports:
+        - name: http
+          containerPort: 9898
+        envFrom:
+        - configMapRef:
+            name: podinfo-env
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: podinfo-env
+data:
+  color: blue
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: podinfo
+spec:
+  rules:
+  - host: app.example.com
+    http:
+      paths:
+      - backend:
+          serviceName: podinfo
+          servicePort: 9898
+`
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		provider string
+		expected []string
+	}{
+		{"istio", []string{"kind: VirtualService", "kind: DestinationRule", "weight: 20"}},
+		{"linkerd", []string{"kind: TrafficSplit", "weight: \"20\""}},
+		{"appmesh", []string{"kind: VirtualNode", "kind: VirtualService"}},
+		{"contour", []string{"kind: HTTPProxy", "weight: 20"}},
+		{"gloo", []string{"kind: UpstreamGroup", "weight: 20"}},
+		{"nginx", []string{"kind: Ingress", "name: podinfo-canary", "nginx.ingress.kubernetes.io/canary-weight: \"20\""}},
+		{"kubernetes", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			err := Render([]byte(renderCanary), []byte(renderTarget), RenderOptions{
+				Provider:                 tt.provider,
+				Weight:                   20,
+				SelectorLabels:           []string{"app", "name"},
+				IngressAnnotationsPrefix: "nginx.ingress.kubernetes.io",
+			}, out)
+			require.NoError(t, err)
+
+			rendered := out.String()
+			assert.Contains(t, rendered, "kind: Deployment")
+			assert.Contains(t, rendered, "name: podinfo-primary")
+			assert.Contains(t, rendered, "name: podinfo-env-primary")
+			assert.Contains(t, rendered, "kind: Service")
+			assert.Contains(t, rendered, "name: podinfo-canary")
+			assert.NotContains(t, rendered, "apiVersion: flagger.app/v1beta1\nkind: Canary")
+			for _, e := range tt.expected {
+				assert.Contains(t, rendered, e)
+			}
+		})
+	}
+}
+
+func TestRender_Template(t *testing.T) {
+	canary := `
+apiVersion: flagger.app/v1beta1
+kind: Canary
+metadata:
+  name: podinfo
+  namespace: test
+spec:
+  targetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: podinfo
+  service:
+    port: 9898
+  templateRef:
+    name: podinfo-template
+`
+	template := `
+---
+apiVersion: flagger.app/v1beta1
+kind: CanaryTemplate
+metadata:
+  name: podinfo-template
+spec:
+  provider: linkerd
+  analysis:
+    interval: 1m
+    threshold: 5
+    maxWeight: 50
+    stepWeight: 10
+`
+
+	out := &bytes.Buffer{}
+	opts := RenderOptions{Weight: 20, SelectorLabels: []string{"app"}}
+	err := Render([]byte(canary), []byte(renderTarget+template), opts, out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "kind: TrafficSplit")
+	assert.NotContains(t, out.String(), "kind: VirtualService")
+
+	// the template must be part of the target manifests
+	err = Render([]byte(canary), []byte(renderTarget), opts, &bytes.Buffer{})
+	assert.Error(t, err)
+
+	// the template must define the analysis missing from the canary
+	noAnalysis := strings.Replace(template, `  analysis:
+    interval: 1m
+    threshold: 5
+    maxWeight: 50
+    stepWeight: 10
+`, "", 1)
+	err = Render([]byte(canary), []byte(renderTarget+noAnalysis), opts, &bytes.Buffer{})
+	assert.Error(t, err)
+}
+
+func TestRender_Autoscaler(t *testing.T) {
+	canary := strings.Replace(renderCanary, "  service:\n", `  autoscalerRef:
+    apiVersion: autoscaling/v2
+    kind: HorizontalPodAutoscaler
+    name: podinfo
+  service:
+`, 1)
+	hpa := `
+---
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: podinfo
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: podinfo
+  maxReplicas: 4
+  behavior:
+    scaleDown:
+      stabilizationWindowSeconds: 300
+`
+
+	out := &bytes.Buffer{}
+	err := Render([]byte(canary), []byte(renderTarget+hpa), RenderOptions{Provider: "kubernetes", SelectorLabels: []string{"app"}}, out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "name: podinfo-primary")
+	assert.Contains(t, out.String(), "stabilizationWindowSeconds: 300")
+}
+
+func TestRender_Invalid(t *testing.T) {
+	err := Render([]byte(renderTarget), []byte(renderTarget), RenderOptions{}, &bytes.Buffer{})
+	assert.Error(t, err)
+
+	err = Render([]byte(renderCanary), []byte("apiVersion: batch/v1\nkind: Job\n"), RenderOptions{}, &bytes.Buffer{})
+	assert.Error(t, err)
+
+	err = Render([]byte(renderCanary), []byte(renderTarget), RenderOptions{Weight: 120}, &bytes.Buffer{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,163 @@
+package flaggerctl
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	appmeshv1 "github.com/weaveworks/flagger/pkg/apis/appmesh/v1beta1"
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	gloov1 "github.com/weaveworks/flagger/pkg/apis/gloo/v1"
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
+	knative "github.com/weaveworks/flagger/pkg/apis/knative/v1"
+	contourv1 "github.com/weaveworks/flagger/pkg/apis/projectcontour/v1"
+	smiv1alpha1 "github.com/weaveworks/flagger/pkg/apis/smi/v1alpha1"
+)
+
+// routeLookup fetches a routing object Flagger may have generated for a canary
+type routeLookup struct {
+	gvk schema.GroupVersionKind
+	get func(ctx context.Context) (runtime.Object, error)
+}
+
+// Routes prints as YAML the Kubernetes services, service mesh and ingress objects Flagger manages for the canary,
+// the objects of the providers that are not in use or not installed are skipped
+func (c *Client) Routes(ctx context.Context, namespace string, name string) error {
+	cd, err := c.getCanary(ctx, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	found := 0
+	for _, lookup := range c.routeLookups(cd) {
+		obj, err := lookup.get(ctx)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("%s get query error: %w", lookup.gvk.Kind, err)
+		}
+
+		obj = obj.DeepCopyObject()
+		obj.GetObjectKind().SetGroupVersionKind(lookup.gvk)
+		if accessor, err := meta.Accessor(obj); err == nil {
+			accessor.SetManagedFields(nil)
+		}
+
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("%s encoding error: %w", lookup.gvk.Kind, err)
+		}
+		fmt.Fprintf(c.out, "---\n%s", data)
+		found++
+	}
+
+	if found == 0 {
+		return fmt.Errorf("no routing objects found for canary %s.%s", name, namespace)
+	}
+	return nil
+}
+
+func (c *Client) routeLookups(cd *flaggerv1.Canary) []routeLookup {
+	ns := cd.Namespace
+	apexName, primaryName, canaryName := cd.GetServiceNames()
+	opts := metav1.GetOptions{}
+
+	if cd.Spec.TargetRef.IsKnativeService() {
+		return []routeLookup{{
+			gvk: knative.SchemeGroupVersion.WithKind("Service"),
+			get: func(ctx context.Context) (runtime.Object, error) {
+				return c.flaggerClient.ServingV1().Services(ns).Get(ctx, cd.Spec.TargetRef.Name, opts)
+			},
+		}}
+	}
+
+	var lookups []routeLookup
+	for _, svcName := range []string{apexName, primaryName, canaryName} {
+		svcName := svcName
+		lookups = append(lookups, routeLookup{
+			gvk: schema.GroupVersionKind{Version: "v1", Kind: "Service"},
+			get: func(ctx context.Context) (runtime.Object, error) {
+				return c.kubeClient.CoreV1().Services(ns).Get(ctx, svcName, opts)
+			},
+		})
+	}
+
+	lookups = append(lookups,
+		routeLookup{
+			gvk: istiov1alpha3.SchemeGroupVersion.WithKind("VirtualService"),
+			get: func(ctx context.Context) (runtime.Object, error) {
+				return c.flaggerClient.NetworkingV1alpha3().VirtualServices(ns).Get(ctx, apexName, opts)
+			},
+		},
+	)
+	for _, drName := range []string{primaryName, canaryName} {
+		drName := drName
+		lookups = append(lookups, routeLookup{
+			gvk: istiov1alpha3.SchemeGroupVersion.WithKind("DestinationRule"),
+			get: func(ctx context.Context) (runtime.Object, error) {
+				return c.flaggerClient.NetworkingV1alpha3().DestinationRules(ns).Get(ctx, drName, opts)
+			},
+		})
+	}
+
+	for _, vnName := range []string{apexName, primaryName, canaryName} {
+		vnName := vnName
+		lookups = append(lookups, routeLookup{
+			gvk: appmeshv1.SchemeGroupVersion.WithKind("VirtualNode"),
+			get: func(ctx context.Context) (runtime.Object, error) {
+				return c.flaggerClient.AppmeshV1beta1().VirtualNodes(ns).Get(ctx, vnName, opts)
+			},
+		})
+	}
+	for _, vsName := range []string{fmt.Sprintf("%s.%s", apexName, ns), fmt.Sprintf("%s.%s", canaryName, ns)} {
+		vsName := vsName
+		lookups = append(lookups, routeLookup{
+			gvk: appmeshv1.SchemeGroupVersion.WithKind("VirtualService"),
+			get: func(ctx context.Context) (runtime.Object, error) {
+				return c.flaggerClient.AppmeshV1beta1().VirtualServices(ns).Get(ctx, vsName, opts)
+			},
+		})
+	}
+
+	lookups = append(lookups,
+		routeLookup{
+			gvk: smiv1alpha1.SchemeGroupVersion.WithKind("TrafficSplit"),
+			get: func(ctx context.Context) (runtime.Object, error) {
+				return c.flaggerClient.SplitV1alpha1().TrafficSplits(ns).Get(ctx, apexName, opts)
+			},
+		},
+		routeLookup{
+			gvk: contourv1.SchemeGroupVersion.WithKind("HTTPProxy"),
+			get: func(ctx context.Context) (runtime.Object, error) {
+				return c.flaggerClient.ProjectcontourV1().HTTPProxies(ns).Get(ctx, apexName, opts)
+			},
+		},
+		routeLookup{
+			gvk: gloov1.SchemeGroupVersion.WithKind("UpstreamGroup"),
+			get: func(ctx context.Context) (runtime.Object, error) {
+				return c.flaggerClient.GlooV1().UpstreamGroups(ns).Get(ctx, apexName, opts)
+			},
+		},
+	)
+
+	if cd.Spec.IngressRef != nil {
+		for _, ingName := range []string{cd.Spec.IngressRef.Name, fmt.Sprintf("%s-canary", cd.Spec.IngressRef.Name)} {
+			ingName := ingName
+			lookups = append(lookups, routeLookup{
+				gvk: schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1beta1", Kind: "Ingress"},
+				get: func(ctx context.Context) (runtime.Object, error) {
+					return c.kubeClient.NetworkingV1beta1().Ingresses(ns).Get(ctx, ingName, opts)
+				},
+			})
+		}
+	}
+
+	return lookups
+}
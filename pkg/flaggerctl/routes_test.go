@@ -0,0 +1,37 @@
+package flaggerctl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
+)
+
+func TestClient_Routes(t *testing.T) {
+	services := []runtime.Object{
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "podinfo"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "podinfo-primary"}},
+	}
+	vs := &istiov1alpha3.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "podinfo"},
+		Spec:       istiov1alpha3.VirtualServiceSpec{Hosts: []string{"podinfo"}},
+	}
+	mocks := newClientFixture(services, newTestCanary(), vs)
+
+	require.NoError(t, mocks.client.Routes(context.TODO(), "default", "podinfo"))
+	out := mocks.out.String()
+	assert.Contains(t, out, "kind: Service")
+	assert.Contains(t, out, "name: podinfo-primary")
+	assert.NotContains(t, out, "name: podinfo-canary")
+	assert.Contains(t, out, "apiVersion: networking.istio.io/v1alpha3")
+	assert.Contains(t, out, "kind: VirtualService")
+
+	mocks = newClientFixture(nil)
+	assert.Error(t, mocks.client.Routes(context.TODO(), "default", "podinfo"))
+}
@@ -0,0 +1,102 @@
+package flaggerctl
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/weaveworks/flagger/pkg/controller"
+)
+
+// SimulateOptions holds the settings the Flagger controller would run with
+type SimulateOptions struct {
+	// Provider is the global mesh provider, defaults to istio
+	Provider string
+	// SelectorLabels are the pod labels used to create the pod selectors
+	SelectorLabels []string
+	// MaxSteps stops the simulation if the canary analysis hasn't finished
+	MaxSteps int
+}
+
+// Simulate replays the timeline metric values and webhook outcomes against the canary analysis
+// and prints the weight, the failed checks and the scheduler verdict of each step,
+// the target manifests are optional and can contain metric and canary templates
+func Simulate(canaryManifest []byte, targetManifests []byte, timelineManifest []byte, opts SimulateOptions, out io.Writer) error {
+	cd, err := decodeCanary(canaryManifest)
+	if err != nil {
+		return err
+	}
+	kubeObjects, flaggerObjects, err := decodeTargets(targetManifests, cd.Namespace)
+	if err != nil {
+		return err
+	}
+	timeline := controller.SimulationTimeline{}
+	if err := yaml.Unmarshal(timelineManifest, &timeline); err != nil {
+		return fmt.Errorf("timeline decoding error: %w", err)
+	}
+
+	result, err := controller.Simulate(cd, kubeObjects, flaggerObjects, timeline, controller.SimulationOptions{
+		MeshProvider:   opts.Provider,
+		SelectorLabels: opts.SelectorLabels,
+		MaxSteps:       opts.MaxSteps,
+	})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "STEP\tPHASE\tWEIGHT\tITERATIONS\tFAILED\tMETRICS\tWEBHOOKS\tVERDICT\tMESSAGE")
+	for _, step := range result.Steps {
+		metrics := make([]string, 0, len(step.Metrics))
+		for _, m := range step.Metrics {
+			metrics = append(metrics, fmt.Sprintf("%s=%.2f", m.Name, m.Value))
+		}
+		webhooks := make([]string, 0, len(step.Webhooks))
+		for _, h := range step.Webhooks {
+			outcome := "ok"
+			if !h.Passed {
+				outcome = "fail"
+			}
+			webhooks = append(webhooks, fmt.Sprintf("%s=%s", h.Name, outcome))
+		}
+		message := ""
+		if len(step.Events) > 0 {
+			message = eventMessage(step.Events[len(step.Events)-1])
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%d\t%s\t%s\t%s\t%s\n", step.Step, step.Phase, step.CanaryWeight,
+			step.Iterations, step.FailedChecks, orDash(metrics), orDash(webhooks), step.Verdict, message)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	steps := len(result.Steps) - 1
+	switch {
+	case result.RollbackStep > 0:
+		fmt.Fprintf(out, "\ncanary %s.%s rolled back at step %d\n", cd.Name, cd.Namespace, result.RollbackStep)
+	case strings.HasSuffix(string(result.Phase), "Succeeded"):
+		fmt.Fprintf(out, "\ncanary %s.%s promoted after %d steps\n", cd.Name, cd.Namespace, steps)
+	default:
+		fmt.Fprintf(out, "\ncanary %s.%s analysis unfinished after %d steps, phase %s\n", cd.Name, cd.Namespace, steps, result.Phase)
+	}
+	return nil
+}
+
+// eventMessage strips the type and reason the fake recorder prepends to the event message
+func eventMessage(event string) string {
+	parts := strings.SplitN(event, " ", 3)
+	if len(parts) < 3 {
+		return event
+	}
+	return parts[2]
+}
+
+func orDash(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	return strings.Join(values, ",")
+}
@@ -0,0 +1,89 @@
+package flaggerctl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const simulateCanary = `
+apiVersion: flagger.app/v1beta1
+kind: Canary
+metadata:
+  name: podinfo
+  namespace: test
+spec:
+  targetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: podinfo
+  service:
+    port: 9898
+  analysis:
+    interval: 1m
+    threshold: 2
+    maxWeight: 20
+    stepWeight: 10
+    metrics:
+    - name: request-success-rate
+      thresholdRange:
+        min: 99
+      interval: 1m
+    - name: latency
+      templateRef:
+        name: latency
+      thresholdRange:
+        max: 0.5
+      interval: 1m
+    webhooks:
+    - name: acceptance-test
+      type: pre-rollout
+      url: http://flagger-loadtester.test/
+`
+
+const simulateTemplates = `
+apiVersion: flagger.app/v1beta1
+kind: MetricTemplate
+metadata:
+  name: latency
+spec:
+  provider:
+    type: prometheus
+    address: http://prometheus.istio-system:9090
+    secretRef:
+      name: prom-auth
+  query: histogram_quantile(0.99, sum(rate(http_request_duration_seconds_bucket[{{ interval }}])) by (le))
+`
+
+func TestSimulate(t *testing.T) {
+	timeline := `
+metrics:
+  request-success-rate: [100, 100, 97]
+  latency: [0.2]
+webhooks:
+  acceptance-test: [true]
+`
+	var out bytes.Buffer
+	err := Simulate([]byte(simulateCanary), []byte(simulateTemplates), []byte(timeline), SimulateOptions{}, &out)
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), "request-success-rate=100.00,latency=0.20")
+	assert.Contains(t, out.String(), "acceptance-test=ok")
+	assert.Contains(t, out.String(), "canary podinfo.test promoted after 5 steps")
+}
+
+func TestSimulate_Rollback(t *testing.T) {
+	timeline := `
+metrics:
+  request-success-rate: [100, 98]
+  latency: [0.2, 0.9]
+`
+	var out bytes.Buffer
+	err := Simulate([]byte(simulateCanary), nil, []byte(timeline), SimulateOptions{}, &out)
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), "Halt podinfo.test advancement success rate 98.00% < 99%")
+	assert.Contains(t, out.String(), "canary podinfo.test rolled back at step 5")
+}
@@ -0,0 +1,148 @@
+package flaggerctl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// ListCanaries prints the phase, weight and failed checks of the canaries in the namespace
+func (c *Client) ListCanaries(ctx context.Context, namespace string) error {
+	list, err := c.flaggerClient.FlaggerV1beta1().Canaries(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("canaries list query error: %w", err)
+	}
+
+	items := list.Items
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Namespace != items[j].Namespace {
+			return items[i].Namespace < items[j].Namespace
+		}
+		return items[i].Name < items[j].Name
+	})
+
+	w := tabwriter.NewWriter(c.out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tPHASE\tWEIGHT\tFAILED\tLAST TRANSITION")
+	for i := range items {
+		cd := c.withTemplate(ctx, &items[i])
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d/%s\t%s\n",
+			cd.Namespace, cd.Name, phaseOf(cd), cd.Status.CanaryWeight,
+			cd.Status.FailedChecks, analysisThreshold(cd), since(cd.Status.LastTransitionTime))
+	}
+	return w.Flush()
+}
+
+// Status prints the rollout status of a canary including the routing weights,
+// the failed checks, the last measured metric values and the status conditions
+func (c *Client) Status(ctx context.Context, namespace string, name string) error {
+	cd, err := c.getCanary(ctx, namespace, name)
+	if err != nil {
+		return err
+	}
+	cd = c.withTemplate(ctx, cd)
+
+	w := tabwriter.NewWriter(c.out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Canary:\t%s.%s\n", cd.Name, cd.Namespace)
+	fmt.Fprintf(w, "Target:\t%s/%s\n", cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name)
+	fmt.Fprintf(w, "Phase:\t%s\n", phaseOf(cd))
+	fmt.Fprintf(w, "Weight:\tprimary %d, canary %d\n", 100-cd.Status.CanaryWeight, cd.Status.CanaryWeight)
+	fmt.Fprintf(w, "Failed checks:\t%d/%s\n", cd.Status.FailedChecks, analysisThreshold(cd))
+	if analysis := cd.GetAnalysis(); analysis != nil && analysis.Iterations > 0 {
+		fmt.Fprintf(w, "Iterations:\t%d/%d\n", cd.Status.Iterations, analysis.Iterations)
+	}
+	for _, key := range []string{flaggerv1.PauseAnnotation, flaggerv1.PromoteAnnotation, flaggerv1.AbortAnnotation} {
+		if v, ok := cd.Annotations[key]; ok {
+			fmt.Fprintf(w, "Requested:\t%s=%s\n", key, v)
+		}
+	}
+	fmt.Fprintf(w, "Last transition:\t%s\n", since(cd.Status.LastTransitionTime))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(cd.Status.Metrics) > 0 {
+		fmt.Fprintln(c.out, "\nMetrics:")
+		w = tabwriter.NewWriter(c.out, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "  NAME\tTHRESHOLD\tVALUE\tUPDATED")
+		for _, m := range cd.Status.Metrics {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", m.Name, metricThreshold(cd, m.Name), m.Value, since(m.LastUpdateTime))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(cd.Status.Conditions) > 0 {
+		fmt.Fprintln(c.out, "\nConditions:")
+		w = tabwriter.NewWriter(c.out, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "  TYPE\tSTATUS\tREASON\tMESSAGE")
+		for _, cond := range cd.Status.Conditions {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", cond.Type, cond.Status, cond.Reason, cond.Message)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func phaseOf(cd *flaggerv1.Canary) string {
+	if cd.Status.Phase == "" {
+		return string(flaggerv1.CanaryPhaseInitializing)
+	}
+	if cd.Annotations[flaggerv1.PauseAnnotation] == "true" {
+		return fmt.Sprintf("%s (paused)", cd.Status.Phase)
+	}
+	return string(cd.Status.Phase)
+}
+
+// analysisThreshold returns the number of failed checks before rollback,
+// or a dash when the canary has no analysis
+func analysisThreshold(cd *flaggerv1.Canary) string {
+	if cd.GetAnalysis() == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d", cd.GetAnalysisThreshold())
+}
+
+// metricThreshold returns the threshold range of the analysis metric in a human readable form
+func metricThreshold(cd *flaggerv1.Canary, name string) string {
+	analysis := cd.GetAnalysis()
+	if analysis == nil {
+		return "-"
+	}
+	for _, metric := range analysis.Metrics {
+		if metric.Name != name {
+			continue
+		}
+		if metric.ThresholdRange != nil {
+			r := metric.ThresholdRange
+			switch {
+			case r.Min != nil && r.Max != nil:
+				return fmt.Sprintf("%v..%v", *r.Min, *r.Max)
+			case r.Min != nil:
+				return fmt.Sprintf(">=%v", *r.Min)
+			case r.Max != nil:
+				return fmt.Sprintf("<=%v", *r.Max)
+			}
+		}
+		if metric.Threshold != 0 {
+			return fmt.Sprintf("%v", metric.Threshold)
+		}
+	}
+	return "-"
+}
+
+func since(t metav1.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return fmt.Sprintf("%s ago", time.Since(t.Time).Round(time.Second))
+}
@@ -0,0 +1,72 @@
+package flaggerctl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestClient_Status(t *testing.T) {
+	cd := newTestCanary()
+	cd.Annotations = map[string]string{flaggerv1.PauseAnnotation: "true"}
+	mocks := newClientFixture(nil, cd)
+
+	require.NoError(t, mocks.client.Status(context.TODO(), "default", "podinfo"))
+	out := mocks.out.String()
+	assert.Contains(t, out, "Progressing (paused)")
+	assert.Contains(t, out, "primary 70, canary 30")
+	assert.Contains(t, out, "1/5")
+	assert.Contains(t, out, "request-success-rate")
+	assert.Contains(t, out, ">=99")
+	assert.Contains(t, out, "99.50")
+
+	assert.Error(t, mocks.client.Status(context.TODO(), "default", "missing"))
+}
+
+func TestClient_ListCanaries(t *testing.T) {
+	cd := newTestCanary()
+	other := newTestCanary()
+	other.Name = "backend"
+	other.Status = flaggerv1.CanaryStatus{}
+	mocks := newClientFixture(nil, cd, other)
+
+	require.NoError(t, mocks.client.ListCanaries(context.TODO(), "default"))
+	out := mocks.out.String()
+	assert.Regexp(t, `backend\s+Initializing`, out)
+	assert.Regexp(t, `podinfo\s+Progressing\s+30\s+1/5`, out)
+}
+
+func TestClient_StatusTemplate(t *testing.T) {
+	cd := newTestCanary()
+	cd.Spec.Analysis = nil
+	cd.Spec.TemplateRef = &flaggerv1.CanaryTemplateRef{Name: "standard"}
+	other := newTestCanary()
+	other.Name = "backend"
+	other.Spec.Analysis = nil
+	other.Spec.TemplateRef = &flaggerv1.CanaryTemplateRef{Name: "missing"}
+	template := &flaggerv1.CanaryTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "standard"},
+		Spec: flaggerv1.CanaryTemplateSpec{
+			Analysis: &flaggerv1.CanaryAnalysis{Threshold: 3, StepWeight: 10},
+		},
+	}
+	mocks := newClientFixture(nil, cd, other, template)
+
+	require.NoError(t, mocks.client.Status(context.TODO(), "default", "podinfo"))
+	assert.Contains(t, mocks.out.String(), "1/3")
+
+	mocks.out.Reset()
+	require.NoError(t, mocks.client.Status(context.TODO(), "default", "backend"))
+	assert.Contains(t, mocks.out.String(), "1/-")
+
+	mocks.out.Reset()
+	require.NoError(t, mocks.client.ListCanaries(context.TODO(), "default"))
+	out := mocks.out.String()
+	assert.Regexp(t, `backend\s+Progressing\s+30\s+1/-`, out)
+	assert.Regexp(t, `podinfo\s+Progressing\s+30\s+1/3`, out)
+}
@@ -1,31 +1,168 @@
 package loadtester
 
-import "sync"
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxGateAuditEntries is the number of audit entries kept per gate
+const maxGateAuditEntries = 20
+
+// Gate holds the state and the audit trail of a manual gate
+type Gate struct {
+	Name      string           `json:"name"`
+	Open      bool             `json:"open"`
+	UpdatedBy string           `json:"updatedBy,omitempty"`
+	UpdatedAt time.Time        `json:"updatedAt,omitempty"`
+	ExpiresAt *time.Time       `json:"expiresAt,omitempty"`
+	Audit     []GateAuditEntry `json:"audit,omitempty"`
+}
+
+// GateAuditEntry records who changed a gate and when
+type GateAuditEntry struct {
+	Action string    `json:"action"`
+	User   string    `json:"user"`
+	Time   time.Time `json:"time"`
+	TTL    string    `json:"ttl,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// IsOpen returns true if the gate is open and has not expired
+func (g *Gate) IsOpen(now time.Time) bool {
+	if g == nil || !g.Open {
+		return false
+	}
+	return g.ExpiresAt == nil || now.Before(*g.ExpiresAt)
+}
+
+func (g *Gate) record(entry GateAuditEntry) {
+	g.Audit = append(g.Audit, entry)
+	if len(g.Audit) > maxGateAuditEntries {
+		g.Audit = g.Audit[len(g.Audit)-maxGateAuditEntries:]
+	}
+}
+
+// GateStore persists the state of the manual gates
+type GateStore interface {
+	// Get returns the gate or nil if the gate has never been set
+	Get(key string) (*Gate, error)
+	// Update applies the changes to the gate in a single read-modify-write operation
+	Update(key string, fn func(gate *Gate)) error
+	// List returns all gates
+	List() ([]Gate, error)
+}
 
 type GateStorage struct {
 	backend string
-	data    *sync.Map
+	store   GateStore
+	now     func() time.Time
 }
 
+// NewGateStorage returns a gate storage that keeps the state in memory,
+// all gates are closed when the loadtester restarts
 func NewGateStorage(backend string) *GateStorage {
+	return NewGateStorageWithStore(backend, &memoryGateStore{data: make(map[string]Gate)})
+}
+
+// NewGateStorageWithStore returns a gate storage backed by the given store
+func NewGateStorageWithStore(backend string, store GateStore) *GateStorage {
 	return &GateStorage{
 		backend: backend,
-		data:    new(sync.Map),
+		store:   store,
+		now:     time.Now,
 	}
 }
 
-func (gs *GateStorage) open(key string) {
-	gs.data.Store(key, true)
+// Backend returns the storage backend name
+func (gs *GateStorage) Backend() string {
+	return gs.backend
+}
+
+// open opens the gate, if the ttl is greater than zero the gate closes automatically after the ttl
+func (gs *GateStorage) open(key string, user string, ttl time.Duration, reason string) error {
+	now := gs.now().UTC()
+	return gs.store.Update(key, func(gate *Gate) {
+		gate.Open = true
+		gate.UpdatedBy = user
+		gate.UpdatedAt = now
+		gate.ExpiresAt = nil
+		entry := GateAuditEntry{Action: "open", User: user, Time: now, Reason: reason}
+		if ttl > 0 {
+			expiresAt := now.Add(ttl)
+			gate.ExpiresAt = &expiresAt
+			entry.TTL = ttl.String()
+		}
+		gate.record(entry)
+	})
+}
+
+func (gs *GateStorage) close(key string, user string, reason string) error {
+	now := gs.now().UTC()
+	return gs.store.Update(key, func(gate *Gate) {
+		gate.Open = false
+		gate.UpdatedBy = user
+		gate.UpdatedAt = now
+		gate.ExpiresAt = nil
+		gate.record(GateAuditEntry{Action: "close", User: user, Time: now, Reason: reason})
+	})
+}
+
+func (gs *GateStorage) isOpen(key string) (bool, error) {
+	gate, err := gs.store.Get(key)
+	if err != nil {
+		return false, err
+	}
+	return gate.IsOpen(gs.now()), nil
+}
+
+// list returns all gates sorted by name, expired gates are reported as closed
+func (gs *GateStorage) list() ([]Gate, error) {
+	gates, err := gs.store.List()
+	if err != nil {
+		return nil, err
+	}
+	now := gs.now()
+	for i := range gates {
+		gates[i].Open = gates[i].IsOpen(now)
+	}
+	sort.Slice(gates, func(i, j int) bool {
+		return gates[i].Name < gates[j].Name
+	})
+	return gates, nil
+}
+
+type memoryGateStore struct {
+	mu   sync.RWMutex
+	data map[string]Gate
+}
+
+func (s *memoryGateStore) Get(key string) (*Gate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	gate, ok := s.data[key]
+	if !ok {
+		return nil, nil
+	}
+	return &gate, nil
 }
 
-func (gs *GateStorage) close(key string) {
-	gs.data.Store(key, false)
+func (s *memoryGateStore) Update(key string, fn func(gate *Gate)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	gate := s.data[key]
+	gate.Name = key
+	fn(&gate)
+	s.data[key] = gate
+	return nil
 }
 
-func (gs *GateStorage) isOpen(key string) (locked bool) {
-	val, ok := gs.data.LoadOrStore(key, false)
-	if ok {
-		return val.(bool)
+func (s *memoryGateStore) List() ([]Gate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	gates := make([]Gate, 0, len(s.data))
+	for _, gate := range s.data {
+		gates = append(gates, gate)
 	}
-	return
+	return gates, nil
 }
@@ -0,0 +1,94 @@
+package loadtester
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// anonymousUser is recorded when the gate API authentication is disabled
+const anonymousUser = "anonymous"
+
+// GateTokenHeader carries the bearer token of the callers that can't set the Authorization header,
+// such as requests made through the Kubernetes API server service proxy
+const GateTokenHeader = "X-Gate-Token"
+
+var errUnauthorized = errors.New("unauthorized")
+
+// GateAuthenticator identifies the callers of the gate API
+// based on bearer tokens or TLS client certificates
+type GateAuthenticator struct {
+	tokens     map[string]string
+	clientCert bool
+}
+
+// NewGateAuthenticator returns an authenticator for the given token to user mapping,
+// when clientCert is true the verified client certificate common name is accepted as identity
+func NewGateAuthenticator(tokens map[string]string, clientCert bool) *GateAuthenticator {
+	return &GateAuthenticator{
+		tokens:     tokens,
+		clientCert: clientCert,
+	}
+}
+
+// LoadGateTokens reads a JSON document mapping the tokens to the user names,
+// the tokens file is encoded the same way as the file gate storage
+func LoadGateTokens(path string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading tokens file %s failed: %w", path, err)
+	}
+
+	tokens := make(map[string]string)
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("decoding tokens file %s failed: %w", path, err)
+	}
+	for token, user := range tokens {
+		if strings.TrimSpace(token) == "" || strings.TrimSpace(user) == "" {
+			return nil, fmt.Errorf("tokens file %s contains an empty token or user", path)
+		}
+	}
+	return tokens, nil
+}
+
+// Enabled returns true if the gate API requires authentication
+func (a *GateAuthenticator) Enabled() bool {
+	return a != nil && (len(a.tokens) > 0 || a.clientCert)
+}
+
+// Identify returns the caller identity or an error if the caller can't be authenticated
+func (a *GateAuthenticator) Identify(r *http.Request) (string, error) {
+	if !a.Enabled() {
+		return anonymousUser, nil
+	}
+
+	if token := r.Header.Get(GateTokenHeader); token != "" {
+		return a.identifyToken(token)
+	}
+
+	// other authorization schemes are ignored so that the client certificate can still be checked
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return a.identifyToken(strings.TrimPrefix(header, "Bearer "))
+	}
+
+	if a.clientCert && r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.PeerCertificates) > 0 {
+		if cn := r.TLS.PeerCertificates[0].Subject.CommonName; cn != "" {
+			return cn, nil
+		}
+	}
+
+	return "", errUnauthorized
+}
+
+func (a *GateAuthenticator) identifyToken(token string) (string, error) {
+	for t, user := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return user, nil
+		}
+	}
+	return "", errUnauthorized
+}
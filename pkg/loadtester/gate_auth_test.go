@@ -0,0 +1,65 @@
+package loadtester
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGateAuthenticator_Identify(t *testing.T) {
+	auth := NewGateAuthenticator(map[string]string{"secret": "alice"}, true)
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "bob"}}
+
+	req := httptest.NewRequest("POST", "/gate/open", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	user, err := auth.Identify(req)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", user)
+
+	req.Header.Set("Authorization", "Bearer wrong")
+	_, err = auth.Identify(req)
+	assert.Error(t, err)
+
+	// a non bearer authorization header falls through to the client certificate
+	req = httptest.NewRequest("POST", "/gate/open", nil)
+	req.Header.Set("Authorization", "Basic Ym9iOnB3ZA==")
+	req.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	}
+	user, err = auth.Identify(req)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", user)
+
+	req.TLS = nil
+	_, err = auth.Identify(req)
+	assert.Error(t, err)
+}
+
+func TestLoadGateTokens(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tokens")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "tokens.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"secret": "alice", "other": "bob"}`), 0644))
+	tokens, err := LoadGateTokens(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"secret": "alice", "other": "bob"}, tokens)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"secret": ""}`), 0644))
+	_, err = LoadGateTokens(path)
+	assert.Error(t, err)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("secret,alice"), 0644))
+	_, err = LoadGateTokens(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,150 @@
+package loadtester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// ConfigMapGateStore persists the gates state in a Kubernetes ConfigMap,
+// every read goes to the API server so that all loadtester replicas agree on the gate state
+type ConfigMapGateStore struct {
+	kubeClient kubernetes.Interface
+	namespace  string
+	name       string
+}
+
+// NewConfigMapGateStore returns a ConfigMap backed gate store
+func NewConfigMapGateStore(kubeClient kubernetes.Interface, namespace string, name string) (*ConfigMapGateStore, error) {
+	if namespace == "" {
+		return nil, fmt.Errorf("gate storage namespace is empty")
+	}
+	if name == "" {
+		return nil, fmt.Errorf("gate storage ConfigMap name is empty")
+	}
+
+	return &ConfigMapGateStore{
+		kubeClient: kubeClient,
+		namespace:  namespace,
+		name:       name,
+	}, nil
+}
+
+func (s *ConfigMapGateStore) Get(key string) (*Gate, error) {
+	cm, err := s.kubeClient.CoreV1().ConfigMaps(s.namespace).Get(context.TODO(), s.name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ConfigMap %s.%s get query error: %w", s.name, s.namespace, err)
+	}
+
+	val, ok := cm.Data[key]
+	if !ok {
+		return nil, nil
+	}
+	gate, err := decodeGate(key, val)
+	if err != nil {
+		return nil, fmt.Errorf("ConfigMap %s.%s invalid value for gate %s: %w", s.name, s.namespace, key, err)
+	}
+	return gate, nil
+}
+
+func (s *ConfigMapGateStore) Update(key string, fn func(gate *Gate)) error {
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cm, err := s.kubeClient.CoreV1().ConfigMaps(s.namespace).Get(context.TODO(), s.name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			gate := &Gate{Name: key}
+			fn(gate)
+			data, err := json.Marshal(gate)
+			if err != nil {
+				return err
+			}
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      s.name,
+					Namespace: s.namespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/managed-by": "flagger-loadtester",
+					},
+				},
+				Data: map[string]string{
+					key: string(data),
+				},
+			}
+			_, err = s.kubeClient.CoreV1().ConfigMaps(s.namespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+			if errors.IsAlreadyExists(err) {
+				// another replica created the ConfigMap, retry as an update
+				return errors.NewConflict(corev1.Resource("configmaps"), s.name, err)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		gate := &Gate{Name: key}
+		if val, ok := cm.Data[key]; ok {
+			if gate, err = decodeGate(key, val); err != nil {
+				return err
+			}
+		}
+		fn(gate)
+		data, err := json.Marshal(gate)
+		if err != nil {
+			return err
+		}
+
+		cmCopy := cm.DeepCopy()
+		if cmCopy.Data == nil {
+			cmCopy.Data = make(map[string]string)
+		}
+		cmCopy.Data[key] = string(data)
+		_, err = s.kubeClient.CoreV1().ConfigMaps(s.namespace).Update(context.TODO(), cmCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("ConfigMap %s.%s update gate %s failed: %w", s.name, s.namespace, key, err)
+	}
+	return nil
+}
+
+func (s *ConfigMapGateStore) List() ([]Gate, error) {
+	cm, err := s.kubeClient.CoreV1().ConfigMaps(s.namespace).Get(context.TODO(), s.name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return []Gate{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ConfigMap %s.%s get query error: %w", s.name, s.namespace, err)
+	}
+
+	gates := make([]Gate, 0, len(cm.Data))
+	for key, val := range cm.Data {
+		gate, err := decodeGate(key, val)
+		if err != nil {
+			return nil, fmt.Errorf("ConfigMap %s.%s invalid value for gate %s: %w", s.name, s.namespace, key, err)
+		}
+		gates = append(gates, *gate)
+	}
+	return gates, nil
+}
+
+// decodeGate parses the JSON gate, plain boolean values are accepted for compatibility
+func decodeGate(key string, val string) (*Gate, error) {
+	if open, err := strconv.ParseBool(val); err == nil {
+		return &Gate{Name: key, Open: open}, nil
+	}
+	gate := &Gate{}
+	if err := json.Unmarshal([]byte(val), gate); err != nil {
+		return nil, err
+	}
+	gate.Name = key
+	return gate, nil
+}
@@ -0,0 +1,122 @@
+package loadtester
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileGateStore persists the gates state as a JSON document on disk,
+// the file is read on every check so that external changes are picked up
+type FileGateStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileGateStore validates the path and returns a file backed gate store
+func NewFileGateStore(path string) (*FileGateStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("gate storage file path is empty")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating gate storage dir failed: %w", err)
+	}
+
+	return &FileGateStore{path: path}, nil
+}
+
+func (s *FileGateStore) Get(key string) (*Gate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	gates, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	gate, ok := gates[key]
+	if !ok {
+		return nil, nil
+	}
+	return &gate, nil
+}
+
+func (s *FileGateStore) Update(key string, fn func(gate *Gate)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	gates, err := s.read()
+	if err != nil {
+		return err
+	}
+	gate := gates[key]
+	gate.Name = key
+	fn(&gate)
+	gates[key] = gate
+	return s.write(gates)
+}
+
+func (s *FileGateStore) List() ([]Gate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	gates, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Gate, 0, len(gates))
+	for _, gate := range gates {
+		res = append(res, gate)
+	}
+	return res, nil
+}
+
+func (s *FileGateStore) read() (map[string]Gate, error) {
+	gates := make(map[string]Gate)
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return gates, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading gate storage file %s failed: %w", s.path, err)
+	}
+	if len(data) == 0 {
+		return gates, nil
+	}
+	if err := json.Unmarshal(data, &gates); err != nil {
+		return nil, fmt.Errorf("decoding gate storage file %s failed: %w", s.path, err)
+	}
+	return gates, nil
+}
+
+// write replaces the file atomically to avoid leaving a truncated document on crash
+func (s *FileGateStore) write(gates map[string]Gate) error {
+	data, err := json.Marshal(gates)
+	if err != nil {
+		return fmt.Errorf("encoding gates failed: %w", err)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("creating gate storage temp file failed: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing gate storage temp file failed: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("syncing gate storage temp file failed: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing gate storage temp file failed: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("replacing gate storage file %s failed: %w", s.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package loadtester
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+type gateKind string
+
+const (
+	confirmGate  gateKind = "gate"
+	rollbackGate gateKind = "rollback"
+)
+
+func (k gateKind) key(payload *flaggerv1.CanaryWebhookPayload) string {
+	if k == rollbackGate {
+		return fmt.Sprintf("rollback.%s.%s", payload.Name, payload.Namespace)
+	}
+	return fmt.Sprintf("%s.%s", payload.Name, payload.Namespace)
+}
+
+func decodeGatePayload(r *http.Request) (*flaggerv1.CanaryWebhookPayload, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading the request body failed: %w", err)
+	}
+	defer r.Body.Close()
+
+	payload := &flaggerv1.CanaryWebhookPayload{}
+	if err := json.Unmarshal(body, payload); err != nil {
+		return nil, fmt.Errorf("decoding the request body failed: %w", err)
+	}
+	return payload, nil
+}
+
+// HandleGateCheck returns HTTP 200 if the gate is open and HTTP 403 otherwise
+func HandleGateCheck(logger *zap.SugaredLogger, gate *GateStorage, kind gateKind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		payload, err := decodeGatePayload(r)
+		if err != nil {
+			logger.Error(err.Error())
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		key := kind.key(payload)
+		approved, err := gate.isOpen(key)
+		if err != nil {
+			logger.Errorf("%s gate storage error: %v", key, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		if approved {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("Approved"))
+		} else {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("Forbidden"))
+		}
+
+		logger.Infof("%s %s check: approved %v", key, kind, approved)
+	}
+}
+
+// HandleGateOpen opens the gate, the payload metadata can contain
+// a ttl after which the gate closes automatically and a reason recorded in the audit log
+func HandleGateOpen(logger *zap.SugaredLogger, gate *GateStorage, auth *GateAuthenticator, kind gateKind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := auth.Identify(r)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("Unauthorized"))
+			return
+		}
+
+		payload, err := decodeGatePayload(r)
+		if err != nil {
+			logger.Error(err.Error())
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var ttl time.Duration
+		if v, ok := payload.Metadata["ttl"]; ok {
+			ttl, err = time.ParseDuration(v)
+			if err != nil || ttl < 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(fmt.Sprintf("invalid ttl %s", v)))
+				return
+			}
+		}
+
+		key := kind.key(payload)
+		if err := gate.open(key, user, ttl, payload.Metadata["reason"]); err != nil {
+			logger.Errorf("%s gate storage error: %v", key, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+
+		if ttl > 0 {
+			logger.Infof("%s %s opened by %s for %v", key, kind, user, ttl)
+		} else {
+			logger.Infof("%s %s opened by %s", key, kind, user)
+		}
+	}
+}
+
+// HandleGateClose closes the gate
+func HandleGateClose(logger *zap.SugaredLogger, gate *GateStorage, auth *GateAuthenticator, kind gateKind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := auth.Identify(r)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("Unauthorized"))
+			return
+		}
+
+		payload, err := decodeGatePayload(r)
+		if err != nil {
+			logger.Error(err.Error())
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		key := kind.key(payload)
+		if err := gate.close(key, user, payload.Metadata["reason"]); err != nil {
+			logger.Errorf("%s gate storage error: %v", key, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+
+		logger.Infof("%s %s closed by %s", key, kind, user)
+	}
+}
+
+// HandleGateList returns all gates with their state and audit trail
+func HandleGateList(logger *zap.SugaredLogger, gate *GateStorage, auth *GateAuthenticator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if _, err := auth.Identify(r); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("Unauthorized"))
+			return
+		}
+
+		gates, err := gate.list()
+		if err != nil {
+			logger.Errorf("gate storage error: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		data, err := json.Marshal(gates)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
+	}
+}
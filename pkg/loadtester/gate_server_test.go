@@ -0,0 +1,106 @@
+package loadtester
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestServer_HandleGateOpenUnauthorized(t *testing.T) {
+	mocks := newServerFixture()
+	gate := NewGateStorage("in-memory")
+	auth := NewGateAuthenticator(map[string]string{"secret": "alice"}, false)
+
+	req := newJsonRequest("POST", "/gate/open", &flaggerv1.CanaryWebhookPayload{Name: "podinfo", Namespace: "test"})
+	HandleGateOpen(mocks.logger, gate, auth, confirmGate)(mocks.resp, req)
+	assert.Equal(t, http.StatusUnauthorized, mocks.resp.Code)
+
+	resp := httptest.NewRecorder()
+	req = newJsonRequest("POST", "/gate/open", &flaggerv1.CanaryWebhookPayload{Name: "podinfo", Namespace: "test"})
+	req.Header.Set("Authorization", "Bearer wrong")
+	HandleGateOpen(mocks.logger, gate, auth, confirmGate)(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	open, err := gate.isOpen("podinfo.test")
+	require.NoError(t, err)
+	assert.False(t, open)
+}
+
+func TestServer_HandleGateOpenCheckList(t *testing.T) {
+	mocks := newServerFixture()
+	gate := NewGateStorage("in-memory")
+	auth := NewGateAuthenticator(map[string]string{"secret": "alice"}, false)
+
+	req := newJsonRequest("POST", "/gate/open", &flaggerv1.CanaryWebhookPayload{
+		Name:      "podinfo",
+		Namespace: "test",
+		Metadata:  map[string]string{"ttl": "1h", "reason": "approved in review"},
+	})
+	req.Header.Set("Authorization", "Bearer secret")
+	HandleGateOpen(mocks.logger, gate, auth, confirmGate)(mocks.resp, req)
+	require.Equal(t, http.StatusAccepted, mocks.resp.Code)
+
+	// the check endpoint called by Flagger does not require authentication
+	resp := httptest.NewRecorder()
+	req = newJsonRequest("POST", "/gate/check", &flaggerv1.CanaryWebhookPayload{Name: "podinfo", Namespace: "test"})
+	HandleGateCheck(mocks.logger, gate, confirmGate)(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = newJsonRequest("POST", "/rollback/check", &flaggerv1.CanaryWebhookPayload{Name: "podinfo", Namespace: "test"})
+	HandleGateCheck(mocks.logger, gate, rollbackGate)(resp, req)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/gate/list", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	HandleGateList(mocks.logger, gate, auth)(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var gates []Gate
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &gates))
+	require.Len(t, gates, 1)
+	assert.Equal(t, "podinfo.test", gates[0].Name)
+	assert.True(t, gates[0].Open)
+	assert.NotNil(t, gates[0].ExpiresAt)
+	require.Len(t, gates[0].Audit, 1)
+	assert.Equal(t, "alice", gates[0].Audit[0].User)
+	assert.Equal(t, "approved in review", gates[0].Audit[0].Reason)
+}
+
+func TestServer_HandleGateOpenInvalidTTL(t *testing.T) {
+	mocks := newServerFixture()
+	gate := NewGateStorage("in-memory")
+
+	req := newJsonRequest("POST", "/gate/open", &flaggerv1.CanaryWebhookPayload{
+		Name:      "podinfo",
+		Namespace: "test",
+		Metadata:  map[string]string{"ttl": "forever"},
+	})
+	HandleGateOpen(mocks.logger, gate, NewGateAuthenticator(nil, false), confirmGate)(mocks.resp, req)
+	assert.Equal(t, http.StatusBadRequest, mocks.resp.Code)
+}
+
+func TestServer_HandleGateListTokenHeader(t *testing.T) {
+	mocks := newServerFixture()
+	gate := NewGateStorage("in-memory")
+	auth := NewGateAuthenticator(map[string]string{"secret": "alice"}, false)
+
+	// the token header is used by clients that proxy requests through the Kubernetes API server
+	req, _ := http.NewRequest("GET", "/gate/list", nil)
+	req.Header.Set(GateTokenHeader, "secret")
+	HandleGateList(mocks.logger, gate, auth)(mocks.resp, req)
+	assert.Equal(t, http.StatusOK, mocks.resp.Code)
+
+	resp := httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/gate/list", nil)
+	req.Header.Set(GateTokenHeader, "wrong")
+	HandleGateList(mocks.logger, gate, auth)(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
@@ -0,0 +1,152 @@
+package loadtester
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGateStorage_InMemory(t *testing.T) {
+	gate := NewGateStorage("in-memory")
+
+	open, err := gate.isOpen("podinfo.test")
+	require.NoError(t, err)
+	assert.False(t, open)
+
+	require.NoError(t, gate.open("podinfo.test", "alice", 0, ""))
+	open, err = gate.isOpen("podinfo.test")
+	require.NoError(t, err)
+	assert.True(t, open)
+
+	require.NoError(t, gate.close("podinfo.test", "bob", "bad release"))
+	open, err = gate.isOpen("podinfo.test")
+	require.NoError(t, err)
+	assert.False(t, open)
+
+	gates, err := gate.list()
+	require.NoError(t, err)
+	require.Len(t, gates, 1)
+	assert.Equal(t, "bob", gates[0].UpdatedBy)
+	require.Len(t, gates[0].Audit, 2)
+	assert.Equal(t, "open", gates[0].Audit[0].Action)
+	assert.Equal(t, "alice", gates[0].Audit[0].User)
+	assert.Equal(t, "close", gates[0].Audit[1].Action)
+	assert.Equal(t, "bad release", gates[0].Audit[1].Reason)
+}
+
+func TestGateStorage_Expiry(t *testing.T) {
+	gate := NewGateStorage("in-memory")
+	now := time.Now()
+	gate.now = func() time.Time { return now }
+
+	require.NoError(t, gate.open("podinfo.test", "alice", time.Hour, ""))
+	open, err := gate.isOpen("podinfo.test")
+	require.NoError(t, err)
+	assert.True(t, open)
+
+	gate.now = func() time.Time { return now.Add(2 * time.Hour) }
+	open, err = gate.isOpen("podinfo.test")
+	require.NoError(t, err)
+	assert.False(t, open)
+
+	gates, err := gate.list()
+	require.NoError(t, err)
+	require.Len(t, gates, 1)
+	assert.False(t, gates[0].Open)
+	assert.Equal(t, "1h0m0s", gates[0].Audit[0].TTL)
+}
+
+func TestGateStorage_AuditLimit(t *testing.T) {
+	gate := NewGateStorage("in-memory")
+	for i := 0; i < maxGateAuditEntries+5; i++ {
+		require.NoError(t, gate.open("podinfo.test", "alice", 0, ""))
+	}
+
+	gates, err := gate.list()
+	require.NoError(t, err)
+	assert.Len(t, gates[0].Audit, maxGateAuditEntries)
+}
+
+func TestGateStorage_File(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gates")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "gates.json")
+	store, err := NewFileGateStore(path)
+	require.NoError(t, err)
+	gate := NewGateStorageWithStore("file", store)
+
+	open, err := gate.isOpen("podinfo.test")
+	require.NoError(t, err)
+	assert.False(t, open)
+
+	require.NoError(t, gate.open("podinfo.test", "alice", 0, ""))
+	require.NoError(t, gate.open("rollback.podinfo.test", "alice", 0, ""))
+	require.NoError(t, gate.close("rollback.podinfo.test", "alice", ""))
+
+	// state survives a restart
+	restarted, err := NewFileGateStore(path)
+	require.NoError(t, err)
+	g, err := restarted.Get("podinfo.test")
+	require.NoError(t, err)
+	require.NotNil(t, g)
+	assert.True(t, g.Open)
+	assert.Equal(t, "alice", g.UpdatedBy)
+
+	g, err = restarted.Get("rollback.podinfo.test")
+	require.NoError(t, err)
+	require.NotNil(t, g)
+	assert.False(t, g.Open)
+	assert.Len(t, g.Audit, 2)
+}
+
+func TestGateStorage_ConfigMap(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	store, err := NewConfigMapGateStore(kubeClient, "test", "gates")
+	require.NoError(t, err)
+	gate := NewGateStorageWithStore("configmap", store)
+
+	open, err := gate.isOpen("podinfo.test")
+	require.NoError(t, err)
+	assert.False(t, open)
+
+	require.NoError(t, gate.open("podinfo.test", "alice", 0, ""))
+
+	cm, err := kubeClient.CoreV1().ConfigMaps("test").Get(context.TODO(), "gates", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, cm.Data["podinfo.test"], `"open":true`)
+
+	// a second replica sees the same state
+	replica := NewGateStorageWithStore("configmap", store)
+	open, err = replica.isOpen("podinfo.test")
+	require.NoError(t, err)
+	assert.True(t, open)
+
+	require.NoError(t, replica.close("podinfo.test", "bob", ""))
+	open, err = gate.isOpen("podinfo.test")
+	require.NoError(t, err)
+	assert.False(t, open)
+
+	// plain boolean values are still accepted
+	cm, err = kubeClient.CoreV1().ConfigMaps("test").Get(context.TODO(), "gates", metav1.GetOptions{})
+	require.NoError(t, err)
+	cm.Data["rollback.podinfo.test"] = "true"
+	_, err = kubeClient.CoreV1().ConfigMaps("test").Update(context.TODO(), cm, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	gates, err := gate.list()
+	require.NoError(t, err)
+	require.Len(t, gates, 2)
+	assert.Equal(t, "podinfo.test", gates[0].Name)
+	assert.Equal(t, "rollback.podinfo.test", gates[1].Name)
+	assert.True(t, gates[1].Open)
+}
@@ -2,6 +2,7 @@ package loadtester
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -9,11 +10,48 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	// maxFinishedTasks is the number of finished tasks kept in memory
+	maxFinishedTasks = 100
+	// maxTaskOutput is the number of bytes kept from the output of a finished task
+	maxTaskOutput = 4096
+)
+
 type TaskRunnerInterface interface {
 	Add(task Task)
 	GetTotalExecs() uint64
 	Start(interval time.Duration, stopCh <-chan struct{})
 	Timeout() time.Duration
+	List() []TaskRecord
+	Get(hash string) (TaskRecord, bool)
+	Cancel(hash string) bool
+}
+
+type TaskStatus string
+
+const (
+	TaskStatusQueued    TaskStatus = "queued"
+	TaskStatusRunning   TaskStatus = "running"
+	TaskStatusSucceeded TaskStatus = "succeeded"
+	TaskStatusFailed    TaskStatus = "failed"
+	TaskStatusCanceled  TaskStatus = "canceled"
+)
+
+// TaskRecord holds the state of a queued, running or finished task
+type TaskRecord struct {
+	Hash            string     `json:"hash"`
+	Canary          string     `json:"canary"`
+	Type            string     `json:"type"`
+	Task            string     `json:"task"`
+	Status          TaskStatus `json:"status"`
+	QueuedAt        time.Time  `json:"queuedAt"`
+	StartedAt       *time.Time `json:"startedAt,omitempty"`
+	FinishedAt      *time.Time `json:"finishedAt,omitempty"`
+	Output          string     `json:"output,omitempty"`
+	OutputTruncated bool       `json:"outputTruncated,omitempty"`
+
+	cancel   context.CancelFunc
+	canceled bool
 }
 
 type TaskRunner struct {
@@ -22,6 +60,10 @@ type TaskRunner struct {
 	todoTasks    *sync.Map
 	runningTasks *sync.Map
 	totalExecs   uint64
+
+	mu       sync.Mutex
+	active   map[string]*TaskRecord
+	finished []*TaskRecord
 }
 
 func NewTaskRunner(logger *zap.SugaredLogger, timeout time.Duration) *TaskRunner {
@@ -30,11 +72,25 @@ func NewTaskRunner(logger *zap.SugaredLogger, timeout time.Duration) *TaskRunner
 		todoTasks:    new(sync.Map),
 		runningTasks: new(sync.Map),
 		timeout:      timeout,
+		active:       make(map[string]*TaskRecord),
 	}
 }
 
 func (tr *TaskRunner) Add(task Task) {
 	tr.todoTasks.Store(task.Hash(), task)
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if _, exists := tr.active[task.Hash()]; !exists {
+		tr.active[task.Hash()] = &TaskRecord{
+			Hash:     task.Hash(),
+			Canary:   task.Canary(),
+			Type:     task.Type(),
+			Task:     task.String(),
+			Status:   TaskStatusQueued,
+			QueuedAt: time.Now(),
+		}
+	}
 }
 
 func (tr *TaskRunner) GetTotalExecs() uint64 {
@@ -57,13 +113,16 @@ func (tr *TaskRunner) runAll() {
 				ctx, cancel := context.WithTimeout(context.Background(), tr.timeout)
 				defer cancel()
 
+				tr.started(t, cancel)
+
 				// increment the total exec counter
 				atomic.AddUint64(&tr.totalExecs, 1)
 
 				tr.logger.With("canary", t.Canary()).Infof("task starting %s", t)
 
 				// run task with the timeout context
-				t.Run(ctx)
+				result := t.Run(ctx)
+				tr.finish(t.Hash(), result)
 
 				// remove task from the running list
 				tr.runningTasks.Delete(t.Hash())
@@ -75,6 +134,131 @@ func (tr *TaskRunner) runAll() {
 	})
 }
 
+// started marks the task as running
+func (tr *TaskRunner) started(t Task, cancel context.CancelFunc) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	now := time.Now()
+	record, exists := tr.active[t.Hash()]
+	if !exists {
+		// the task was added again while the previous run was finishing
+		record = &TaskRecord{
+			Hash:     t.Hash(),
+			Canary:   t.Canary(),
+			Type:     t.Type(),
+			Task:     t.String(),
+			QueuedAt: now,
+		}
+		tr.active[t.Hash()] = record
+	}
+	record.Status = TaskStatusRunning
+	record.StartedAt = &now
+	record.cancel = cancel
+}
+
+// finish records the task result and moves the task to the finished list
+func (tr *TaskRunner) finish(hash string, result *TaskRunResult) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	record, exists := tr.active[hash]
+	if !exists {
+		return
+	}
+	delete(tr.active, hash)
+
+	now := time.Now()
+	record.FinishedAt = &now
+	record.cancel = nil
+	switch {
+	case record.canceled:
+		record.Status = TaskStatusCanceled
+	case result != nil && result.ok:
+		record.Status = TaskStatusSucceeded
+	default:
+		record.Status = TaskStatusFailed
+	}
+	if result != nil {
+		out := result.out
+		if len(out) > maxTaskOutput {
+			out = out[len(out)-maxTaskOutput:]
+			record.OutputTruncated = true
+		}
+		record.Output = string(out)
+	}
+
+	tr.addFinished(record)
+}
+
+func (tr *TaskRunner) addFinished(record *TaskRecord) {
+	tr.finished = append(tr.finished, record)
+	if len(tr.finished) > maxFinishedTasks {
+		tr.finished = tr.finished[len(tr.finished)-maxFinishedTasks:]
+	}
+}
+
+// List returns the queued and running tasks followed by the finished ones, most recent first
+func (tr *TaskRunner) List() []TaskRecord {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	records := make([]TaskRecord, 0, len(tr.active)+len(tr.finished))
+	for _, record := range tr.active {
+		records = append(records, *record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].QueuedAt.After(records[j].QueuedAt)
+	})
+	for i := len(tr.finished) - 1; i >= 0; i-- {
+		records = append(records, *tr.finished[i])
+	}
+	return records
+}
+
+// Get returns the active task or the last finished task with the given hash
+func (tr *TaskRunner) Get(hash string) (TaskRecord, bool) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	if record, exists := tr.active[hash]; exists {
+		return *record, true
+	}
+	for i := len(tr.finished) - 1; i >= 0; i-- {
+		if tr.finished[i].Hash == hash {
+			return *tr.finished[i], true
+		}
+	}
+	return TaskRecord{}, false
+}
+
+// Cancel removes a queued task or cancels the context of a running task,
+// returns false if there is no queued or running task with the given hash
+func (tr *TaskRunner) Cancel(hash string) bool {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	record, exists := tr.active[hash]
+	if !exists {
+		return false
+	}
+	record.canceled = true
+
+	if record.Status == TaskStatusQueued {
+		tr.todoTasks.Delete(hash)
+		delete(tr.active, hash)
+		now := time.Now()
+		record.Status = TaskStatusCanceled
+		record.FinishedAt = &now
+		tr.addFinished(record)
+	} else if record.cancel != nil {
+		record.cancel()
+	}
+
+	tr.logger.With("canary", record.Canary).Infof("task canceled %s", record.Task)
+	return true
+}
+
 func (tr *TaskRunner) Start(interval time.Duration, stopCh <-chan struct{}) {
 	tickChan := time.NewTicker(interval).C
 	for {
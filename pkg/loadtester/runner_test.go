@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/weaveworks/flagger/pkg/logger"
 )
@@ -43,3 +44,68 @@ func TestTaskRunner_Start(t *testing.T) {
 	time.Sleep(time.Second)
 	assert.Equal(t, uint64(4), tr.GetTotalExecs())
 }
+
+func TestTaskRunner_Records(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	logger, _ := logger.NewLogger("debug")
+	tr := NewTaskRunner(logger, time.Hour)
+
+	taskFactory, _ := GetTaskFactory(TaskTypeShell)
+	ok, _ := taskFactory(map[string]string{"cmd": "echo ok"}, "podinfo.default", logger)
+	fail, _ := taskFactory(map[string]string{"cmd": "echo fail && exit 1"}, "podinfo.default", logger)
+	long, _ := taskFactory(map[string]string{"cmd": "exec sleep 10"}, "podinfo.default", logger)
+
+	tr.Add(ok)
+	tr.Add(fail)
+	tr.Add(long)
+
+	record, found := tr.Get(long.Hash())
+	require.True(t, found)
+	assert.Equal(t, TaskStatusQueued, record.Status)
+	assert.Equal(t, TaskTypeShell, record.Type)
+
+	go tr.Start(10*time.Millisecond, stop)
+
+	assert.Eventually(t, func() bool {
+		record, _ := tr.Get(long.Hash())
+		return record.Status == TaskStatusRunning
+	}, time.Second, 10*time.Millisecond)
+
+	assert.True(t, tr.Cancel(long.Hash()))
+	assert.Eventually(t, func() bool {
+		record, _ := tr.Get(long.Hash())
+		return record.Status == TaskStatusCanceled
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.False(t, tr.Cancel(long.Hash()))
+
+	record, _ = tr.Get(ok.Hash())
+	assert.Equal(t, TaskStatusSucceeded, record.Status)
+	assert.Equal(t, "ok\n", record.Output)
+	assert.NotNil(t, record.StartedAt)
+	assert.NotNil(t, record.FinishedAt)
+
+	record, _ = tr.Get(fail.Hash())
+	assert.Equal(t, TaskStatusFailed, record.Status)
+	assert.Equal(t, "fail\n", record.Output)
+
+	assert.Len(t, tr.List(), 3)
+}
+
+func TestTaskRunner_CancelQueued(t *testing.T) {
+	logger, _ := logger.NewLogger("debug")
+	tr := NewTaskRunner(logger, time.Hour)
+
+	taskFactory, _ := GetTaskFactory(TaskTypeShell)
+	task, _ := taskFactory(map[string]string{"cmd": "sleep 10"}, "podinfo.default", logger)
+	tr.Add(task)
+
+	assert.True(t, tr.Cancel(task.Hash()))
+	tr.runAll()
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Equal(t, uint64(0), tr.GetTotalExecs())
+	record, _ := tr.Get(task.Hash())
+	assert.Equal(t, TaskStatusCanceled, record.Status)
+	assert.Nil(t, record.StartedAt)
+}
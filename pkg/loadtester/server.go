@@ -2,6 +2,8 @@ package loadtester
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -14,8 +16,17 @@ import (
 	"go.uber.org/zap"
 )
 
+// TLSOptions enables an HTTPS listener with optional client certificate verification
+type TLSOptions struct {
+	Port         string
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+}
+
 // ListenAndServe starts a web server and waits for SIGTERM
-func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogger, taskRunner *TaskRunner, gate *GateStorage, stopCh <-chan struct{}) {
+func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogger, taskRunner *TaskRunner,
+	gate *GateStorage, auth *GateAuthenticator, tlsOpts *TLSOptions, stopCh <-chan struct{}) {
 	mux := http.DefaultServeMux
 	mux.Handle("/metrics", promhttp.Handler())
 	mux.HandleFunc("/healthz", HandleHealthz)
@@ -27,163 +38,15 @@ func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogge
 		w.WriteHeader(http.StatusForbidden)
 		w.Write([]byte("Forbidden"))
 	})
-	mux.HandleFunc("/gate/check", func(w http.ResponseWriter, r *http.Request) {
-		body, err := ioutil.ReadAll(r.Body)
-		if err != nil {
-			logger.Error("reading the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-		defer r.Body.Close()
-
-		canary := &flaggerv1.CanaryWebhookPayload{}
-		err = json.Unmarshal(body, canary)
-		if err != nil {
-			logger.Error("decoding the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		canaryName := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
-		approved := gate.isOpen(canaryName)
-		if approved {
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte("Approved"))
-		} else {
-			w.WriteHeader(http.StatusForbidden)
-			w.Write([]byte("Forbidden"))
-		}
-
-		logger.Infof("%s gate check: approved %v", canaryName, approved)
-	})
-
-	mux.HandleFunc("/gate/open", func(w http.ResponseWriter, r *http.Request) {
-		body, err := ioutil.ReadAll(r.Body)
-		if err != nil {
-			logger.Error("reading the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-		defer r.Body.Close()
-
-		canary := &flaggerv1.CanaryWebhookPayload{}
-		err = json.Unmarshal(body, canary)
-		if err != nil {
-			logger.Error("decoding the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		canaryName := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
-		gate.open(canaryName)
-
-		w.WriteHeader(http.StatusAccepted)
-
-		logger.Infof("%s gate opened", canaryName)
-	})
-
-	mux.HandleFunc("/gate/close", func(w http.ResponseWriter, r *http.Request) {
-		body, err := ioutil.ReadAll(r.Body)
-		if err != nil {
-			logger.Error("reading the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-		defer r.Body.Close()
-
-		canary := &flaggerv1.CanaryWebhookPayload{}
-		err = json.Unmarshal(body, canary)
-		if err != nil {
-			logger.Error("decoding the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		canaryName := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
-		gate.close(canaryName)
-
-		w.WriteHeader(http.StatusAccepted)
-
-		logger.Infof("%s gate closed", canaryName)
-	})
-
-	mux.HandleFunc("/rollback/check", func(w http.ResponseWriter, r *http.Request) {
-		body, err := ioutil.ReadAll(r.Body)
-		if err != nil {
-			logger.Error("reading the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-		defer r.Body.Close()
-
-		canary := &flaggerv1.CanaryWebhookPayload{}
-		err = json.Unmarshal(body, canary)
-		if err != nil {
-			logger.Error("decoding the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		canaryName := fmt.Sprintf("rollback.%s.%s", canary.Name, canary.Namespace)
-		approved := gate.isOpen(canaryName)
-		if approved {
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte("Approved"))
-		} else {
-			w.WriteHeader(http.StatusForbidden)
-			w.Write([]byte("Forbidden"))
-		}
-
-		logger.Infof("%s rollback check: approved %v", canaryName, approved)
-	})
-	mux.HandleFunc("/rollback/open", func(w http.ResponseWriter, r *http.Request) {
-		body, err := ioutil.ReadAll(r.Body)
-		if err != nil {
-			logger.Error("reading the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-		defer r.Body.Close()
-
-		canary := &flaggerv1.CanaryWebhookPayload{}
-		err = json.Unmarshal(body, canary)
-		if err != nil {
-			logger.Error("decoding the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		canaryName := fmt.Sprintf("rollback.%s.%s", canary.Name, canary.Namespace)
-		gate.open(canaryName)
-
-		w.WriteHeader(http.StatusAccepted)
-
-		logger.Infof("%s rollback opened", canaryName)
-	})
-	mux.HandleFunc("/rollback/close", func(w http.ResponseWriter, r *http.Request) {
-		body, err := ioutil.ReadAll(r.Body)
-		if err != nil {
-			logger.Error("reading the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-		defer r.Body.Close()
-
-		canary := &flaggerv1.CanaryWebhookPayload{}
-		err = json.Unmarshal(body, canary)
-		if err != nil {
-			logger.Error("decoding the request body failed", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		canaryName := fmt.Sprintf("rollback.%s.%s", canary.Name, canary.Namespace)
-		gate.close(canaryName)
-
-		w.WriteHeader(http.StatusAccepted)
-
-		logger.Infof("%s rollback closed", canaryName)
-	})
+	mux.HandleFunc("/gate/check", HandleGateCheck(logger, gate, confirmGate))
+	mux.HandleFunc("/gate/open", HandleGateOpen(logger, gate, auth, confirmGate))
+	mux.HandleFunc("/gate/close", HandleGateClose(logger, gate, auth, confirmGate))
+	mux.HandleFunc("/gate/list", HandleGateList(logger, gate, auth))
+	mux.HandleFunc("/rollback/check", HandleGateCheck(logger, gate, rollbackGate))
+	mux.HandleFunc("/rollback/open", HandleGateOpen(logger, gate, auth, rollbackGate))
+	mux.HandleFunc("/rollback/close", HandleGateClose(logger, gate, auth, rollbackGate))
+	mux.HandleFunc("/tasks", HandleTaskList(logger, taskRunner, auth))
+	mux.HandleFunc("/tasks/", HandleTask(logger, taskRunner, auth))
 
 	mux.HandleFunc("/", HandleNewTask(logger, taskRunner))
 	srv := &http.Server{
@@ -198,11 +61,36 @@ func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogge
 		}
 	}()
 
+	var srvTLS *http.Server
+	if tlsOpts != nil {
+		tlsConfig, err := newTLSConfig(tlsOpts)
+		if err != nil {
+			logger.Fatalf("HTTPS server config error %v", err)
+		}
+		srvTLS = &http.Server{
+			Addr:      ":" + tlsOpts.Port,
+			Handler:   mux,
+			TLSConfig: tlsConfig,
+		}
+
+		go func() {
+			if err := srvTLS.ListenAndServeTLS(tlsOpts.CertFile, tlsOpts.KeyFile); err != http.ErrServerClosed {
+				logger.Fatalf("HTTPS server crashed %v", err)
+			}
+		}()
+	}
+
 	// wait for SIGTERM or SIGINT
 	<-stopCh
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
+	if srvTLS != nil {
+		if err := srvTLS.Shutdown(ctx); err != nil {
+			logger.Errorf("HTTPS server graceful shutdown failed %v", err)
+		}
+	}
+
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Errorf("HTTP server graceful shutdown failed %v", err)
 	} else {
@@ -210,6 +98,26 @@ func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogge
 	}
 }
 
+func newTLSConfig(opts *TLSOptions) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if opts.ClientCAFile != "" {
+		ca, err := ioutil.ReadFile(opts.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading client CA %s failed: %w", opts.ClientCAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in client CA %s", opts.ClientCAFile)
+		}
+		// Flagger calls the check endpoints without a client certificate
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, nil
+}
+
 // HandleHealthz handles heath check requests
 func HandleHealthz(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
@@ -44,3 +44,15 @@ func (m *MockTaskRunner) Start(interval time.Duration, stopCh <-chan struct{}) {
 func (m *MockTaskRunner) Timeout() time.Duration {
 	return time.Hour
 }
+
+func (m *MockTaskRunner) List() []TaskRecord {
+	return nil
+}
+
+func (m *MockTaskRunner) Get(hash string) (TaskRecord, bool) {
+	return TaskRecord{}, false
+}
+
+func (m *MockTaskRunner) Cancel(hash string) bool {
+	return false
+}
@@ -15,6 +15,7 @@ type Task interface {
 	Run(ctx context.Context) *TaskRunResult
 	String() string
 	Canary() string
+	Type() string
 }
 
 type TaskBase struct {
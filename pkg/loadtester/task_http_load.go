@@ -0,0 +1,388 @@
+package loadtester
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const TaskTypeHTTPLoad = "http-load"
+
+var (
+	httpLoadRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "flagger_loadtester",
+		Name:      "http_requests_total",
+		Help:      "Total number of HTTP requests sent by the http-load tasks.",
+	}, []string{"canary", "code"})
+
+	httpLoadDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: "flagger_loadtester",
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of the HTTP requests sent by the http-load tasks.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"canary"})
+)
+
+func init() {
+	prometheus.MustRegister(httpLoadRequests)
+	prometheus.MustRegister(httpLoadDuration)
+
+	taskFactories.Store(TaskTypeHTTPLoad, func(metadata map[string]string, canary string, logger *zap.SugaredLogger) (Task, error) {
+		return NewHTTPLoadTask(metadata, canary, logger)
+	})
+}
+
+// HTTPLoadTask generates constant rate or ramping HTTP load
+type HTTPLoadTask struct {
+	TaskBase
+	url          string
+	method       string
+	headers      http.Header
+	body         string
+	concurrency  int
+	duration     time.Duration
+	rate         float64
+	startRate    float64
+	rampDuration time.Duration
+	timeout      time.Duration
+	maxErrorRate float64
+	client       *http.Client
+}
+
+// HTTPLoadResult holds the load test statistics
+type HTTPLoadResult struct {
+	Requests    int             `json:"requests"`
+	Errors      int             `json:"errors"`
+	ErrorRate   float64         `json:"errorRate"`
+	StatusCodes map[string]int  `json:"statusCodes"`
+	Duration    string          `json:"duration"`
+	RPS         float64         `json:"rps"`
+	Latency     HTTPLoadLatency `json:"latency"`
+}
+
+// HTTPLoadLatency holds the latency percentiles in milliseconds
+type HTTPLoadLatency struct {
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// NewHTTPLoadTask validates the metadata and returns a http-load task
+func NewHTTPLoadTask(metadata map[string]string, canary string, logger *zap.SugaredLogger) (*HTTPLoadTask, error) {
+	address := metadata["url"]
+	if address == "" {
+		return nil, errors.New("url not found in metadata")
+	}
+	if _, err := url.ParseRequestURI(address); err != nil {
+		return nil, fmt.Errorf("invalid url %s: %w", address, err)
+	}
+
+	task := &HTTPLoadTask{
+		TaskBase:    TaskBase{canary, logger},
+		url:         address,
+		method:      http.MethodGet,
+		headers:     make(http.Header),
+		body:        metadata["body"],
+		concurrency: 10,
+		duration:    time.Minute,
+		timeout:     10 * time.Second,
+	}
+
+	if v := metadata["method"]; v != "" {
+		task.method = strings.ToUpper(v)
+	}
+
+	// headers are specified one per line in the format Name: value
+	for _, line := range strings.Split(metadata["headers"], "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid header %s, must be in the format Name: value", line)
+		}
+		task.headers.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+
+	var err error
+	if v := metadata["concurrency"]; v != "" {
+		if task.concurrency, err = strconv.Atoi(v); err != nil || task.concurrency < 1 {
+			return nil, fmt.Errorf("metadata concurrency must be a positive integer")
+		}
+	}
+	if task.duration, err = parseDurationDefault(metadata["duration"], task.duration); err != nil {
+		return nil, fmt.Errorf("metadata duration is invalid: %w", err)
+	}
+	if task.timeout, err = parseDurationDefault(metadata["timeout"], task.timeout); err != nil {
+		return nil, fmt.Errorf("metadata timeout is invalid: %w", err)
+	}
+	if task.rampDuration, err = parseDurationDefault(metadata["rampDuration"], 0); err != nil {
+		return nil, fmt.Errorf("metadata rampDuration is invalid: %w", err)
+	}
+	if task.rate, err = parseFloatDefault(metadata["rate"], 0); err != nil {
+		return nil, fmt.Errorf("metadata rate is invalid: %w", err)
+	}
+	if task.startRate, err = parseFloatDefault(metadata["startRate"], 1); err != nil {
+		return nil, fmt.Errorf("metadata startRate is invalid: %w", err)
+	}
+	if task.maxErrorRate, err = parseFloatDefault(metadata["maxErrorRate"], 100); err != nil {
+		return nil, fmt.Errorf("metadata maxErrorRate is invalid: %w", err)
+	}
+	if task.rampDuration > 0 && task.rate <= 0 {
+		return nil, errors.New("metadata rate is required when rampDuration is specified")
+	}
+
+	task.client = &http.Client{
+		Timeout: task.timeout,
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			MaxIdleConnsPerHost: task.concurrency,
+		},
+	}
+
+	return task, nil
+}
+
+func parseDurationDefault(v string, def time.Duration) (time.Duration, error) {
+	if v == "" {
+		return def, nil
+	}
+	return time.ParseDuration(v)
+}
+
+func parseFloatDefault(v string, def float64) (float64, error) {
+	if v == "" {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err == nil && f < 0 {
+		return 0, fmt.Errorf("%s must be a positive number", v)
+	}
+	return f, err
+}
+
+func (task *HTTPLoadTask) Hash() string {
+	return hash(task.canary + task.method + task.url + task.body)
+}
+
+func (task *HTTPLoadTask) Type() string {
+	return TaskTypeHTTPLoad
+}
+
+func (task *HTTPLoadTask) String() string {
+	return fmt.Sprintf("%s %s", task.method, task.url)
+}
+
+// currentRate returns the target requests per second at the given time since start,
+// during the ramp up the rate increases linearly from startRate to rate
+func (task *HTTPLoadTask) currentRate(elapsed time.Duration) float64 {
+	if task.rampDuration <= 0 || elapsed >= task.rampDuration {
+		return task.rate
+	}
+	start := math.Min(task.startRate, task.rate)
+	return start + (task.rate-start)*elapsed.Seconds()/task.rampDuration.Seconds()
+}
+
+func (task *HTTPLoadTask) Run(ctx context.Context) *TaskRunResult {
+	ctx, cancel := context.WithTimeout(ctx, task.duration)
+	defer cancel()
+
+	stats := &httpLoadStats{statusCodes: make(map[string]int)}
+	begin := time.Now()
+
+	var jobs chan struct{}
+	if task.rate > 0 {
+		jobs = make(chan struct{}, task.concurrency)
+		go task.pace(ctx, jobs, begin)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < task.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if jobs != nil {
+					select {
+					case <-ctx.Done():
+						return
+					case _, ok := <-jobs:
+						if !ok {
+							return
+						}
+					}
+				} else if ctx.Err() != nil {
+					return
+				}
+				task.send(ctx, stats)
+			}
+		}()
+	}
+	wg.Wait()
+
+	result := stats.result(time.Since(begin))
+	out, _ := json.Marshal(result)
+
+	ok := result.Requests > 0 && result.ErrorRate <= task.maxErrorRate
+	if ok {
+		task.logger.With("canary", task.canary).
+			Infof("http-load finished %s requests %d errors %d p99 %.2fms", task, result.Requests, result.Errors, result.Latency.P99)
+	} else {
+		task.logger.With("canary", task.canary).
+			Errorf("http-load failed %s requests %d error rate %.2f%% > %v%%", task, result.Requests, result.ErrorRate, task.maxErrorRate)
+	}
+	return &TaskRunResult{ok, out}
+}
+
+// pace emits a job for every request to be sent at the current rate
+func (task *HTTPLoadTask) pace(ctx context.Context, jobs chan<- struct{}, begin time.Time) {
+	defer close(jobs)
+	next := begin
+	for {
+		rate := task.currentRate(time.Since(begin))
+		if rate <= 0 {
+			rate = 1
+		}
+		next = next.Add(time.Duration(float64(time.Second) / rate))
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case jobs <- struct{}{}:
+		}
+	}
+}
+
+func (task *HTTPLoadTask) send(ctx context.Context, stats *httpLoadStats) {
+	var body io.Reader
+	if task.body != "" {
+		body = bytes.NewBufferString(task.body)
+	}
+	req, err := http.NewRequest(task.method, task.url, body)
+	if err != nil {
+		stats.record("error", 0)
+		return
+	}
+	req.Header = task.headers.Clone()
+
+	start := time.Now()
+	resp, err := task.client.Do(req.WithContext(ctx))
+	elapsed := time.Since(start)
+
+	// requests interrupted by the end of the test are not counted
+	if ctx.Err() != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return
+	}
+
+	code := "error"
+	if err == nil {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		code = strconv.Itoa(resp.StatusCode)
+	}
+
+	stats.record(code, elapsed)
+	httpLoadRequests.WithLabelValues(task.canary, code).Inc()
+	if err == nil {
+		httpLoadDuration.WithLabelValues(task.canary).Observe(elapsed.Seconds())
+	}
+}
+
+type httpLoadStats struct {
+	mu          sync.Mutex
+	latencies   []time.Duration
+	statusCodes map[string]int
+	errors      int
+}
+
+func (s *httpLoadStats) record(code string, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statusCodes[code]++
+	if code == "error" || strings.HasPrefix(code, "5") {
+		s.errors++
+	}
+	if code != "error" {
+		s.latencies = append(s.latencies, latency)
+	}
+}
+
+func (s *httpLoadStats) result(elapsed time.Duration) HTTPLoadResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requests := 0
+	for _, n := range s.statusCodes {
+		requests += n
+	}
+
+	result := HTTPLoadResult{
+		Requests:    requests,
+		Errors:      s.errors,
+		StatusCodes: s.statusCodes,
+		Duration:    elapsed.Round(time.Millisecond).String(),
+	}
+	if requests > 0 {
+		result.ErrorRate = float64(s.errors) * 100 / float64(requests)
+	}
+	if elapsed > 0 {
+		result.RPS = float64(requests) / elapsed.Seconds()
+	}
+
+	if len(s.latencies) > 0 {
+		sort.Slice(s.latencies, func(i, j int) bool { return s.latencies[i] < s.latencies[j] })
+		var total time.Duration
+		for _, l := range s.latencies {
+			total += l
+		}
+		result.Latency = HTTPLoadLatency{
+			Mean: milliseconds(total / time.Duration(len(s.latencies))),
+			P50:  milliseconds(percentile(s.latencies, 50)),
+			P90:  milliseconds(percentile(s.latencies, 90)),
+			P99:  milliseconds(percentile(s.latencies, 99)),
+			Max:  milliseconds(s.latencies[len(s.latencies)-1]),
+		}
+	}
+	return result
+}
+
+// percentile returns the nearest-rank percentile of a sorted slice
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
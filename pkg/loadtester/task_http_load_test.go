@@ -0,0 +1,108 @@
+package loadtester
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/flagger/pkg/logger"
+)
+
+func TestTaskHTTPLoad(t *testing.T) {
+	logger, _ := logger.NewLoggerWithEncoding("debug", "console")
+	canary := "podinfo.default"
+	taskFactory, ok := GetTaskFactory(TaskTypeHTTPLoad)
+	require.True(t, ok, "Failed to get http-load task factory")
+
+	var count int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&count, 1)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "test", r.Header.Get("X-Canary"))
+		if n%10 == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	t.Run("ConstantRate", func(t *testing.T) {
+		atomic.StoreInt32(&count, 0)
+		task, err := taskFactory(map[string]string{
+			"url":         ts.URL,
+			"method":      "post",
+			"headers":     "X-Canary: test\nContent-Type: application/json",
+			"body":        `{"test": 1}`,
+			"rate":        "50",
+			"concurrency": "2",
+			"duration":    "1s",
+		}, canary, logger)
+		require.NoError(t, err)
+
+		result := task.Run(context.Background())
+		require.True(t, result.ok, string(result.out))
+
+		var stats HTTPLoadResult
+		require.NoError(t, json.Unmarshal(result.out, &stats))
+		assert.InDelta(t, 50, stats.Requests, 10)
+		// every 10th request fails, the requests in flight at the end of the run may not be counted
+		assert.InDelta(t, stats.Requests/10, stats.Errors, 1)
+		assert.Equal(t, stats.Errors, stats.StatusCodes["500"])
+		assert.True(t, stats.Latency.P99 >= stats.Latency.P50)
+	})
+
+	t.Run("MaxErrorRate", func(t *testing.T) {
+		task, err := taskFactory(map[string]string{
+			"url":          ts.URL,
+			"method":       "POST",
+			"headers":      "X-Canary: test",
+			"rate":         "100",
+			"duration":     "500ms",
+			"maxErrorRate": "5",
+		}, canary, logger)
+		require.NoError(t, err)
+
+		result := task.Run(context.Background())
+		assert.False(t, result.ok, string(result.out))
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		task, err := taskFactory(map[string]string{
+			"url":      ts.URL,
+			"method":   "POST",
+			"headers":  "X-Canary: test",
+			"duration": "1m",
+		}, canary, logger)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		task.Run(ctx)
+		assert.True(t, time.Since(start) < 5*time.Second)
+	})
+
+	t.Run("InvalidMetadata", func(t *testing.T) {
+		_, err := taskFactory(map[string]string{}, canary, logger)
+		assert.Error(t, err)
+		_, err = taskFactory(map[string]string{"url": ts.URL, "rampDuration": "10s"}, canary, logger)
+		assert.Error(t, err)
+		_, err = taskFactory(map[string]string{"url": ts.URL, "headers": "invalid"}, canary, logger)
+		assert.Error(t, err)
+	})
+}
+
+func TestTaskHTTPLoad_Ramp(t *testing.T) {
+	task := &HTTPLoadTask{rate: 100, startRate: 10, rampDuration: 10 * time.Second}
+	assert.Equal(t, float64(10), task.currentRate(0))
+	assert.Equal(t, float64(55), task.currentRate(5*time.Second))
+	assert.Equal(t, float64(100), task.currentRate(20*time.Second))
+}
@@ -99,6 +99,10 @@ func (task *NGrinderTask) Run(ctx context.Context) *TaskRunResult {
 	return &TaskRunResult{task.PollStatus(ctx), nil}
 }
 
+func (task *NGrinderTask) Type() string {
+	return TaskTypeNGrinder
+}
+
 func (task *NGrinderTask) String() string {
 	return task.canary + task.CloneAndStartEndpoint().String()
 }
@@ -0,0 +1,88 @@
+package loadtester
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// HandleTaskList returns the queued, running and finished background tasks
+func HandleTaskList(logger *zap.SugaredLogger, taskRunner TaskRunnerInterface, auth *GateAuthenticator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if _, err := auth.Identify(r); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("Unauthorized"))
+			return
+		}
+
+		tasks := taskRunner.List()
+		if canary := r.URL.Query().Get("canary"); canary != "" {
+			filtered := make([]TaskRecord, 0)
+			for _, task := range tasks {
+				if task.Canary == canary {
+					filtered = append(filtered, task)
+				}
+			}
+			tasks = filtered
+		}
+
+		writeTaskJSON(w, tasks)
+	}
+}
+
+// HandleTask returns the details of a task on GET and cancels the task on DELETE
+func HandleTask(logger *zap.SugaredLogger, taskRunner TaskRunnerInterface, auth *GateAuthenticator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := auth.Identify(r)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("Unauthorized"))
+			return
+		}
+
+		hash := strings.TrimPrefix(r.URL.Path, "/tasks/")
+		if hash == "" || strings.Contains(hash, "/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			task, ok := taskRunner.Get(hash)
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte("Task not found"))
+				return
+			}
+			writeTaskJSON(w, task)
+		case http.MethodDelete:
+			if !taskRunner.Cancel(hash) {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte("Task not found or already finished"))
+				return
+			}
+			logger.Infof("task %s canceled by %s", hash, user)
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func writeTaskJSON(w http.ResponseWriter, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
@@ -0,0 +1,74 @@
+package loadtester
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/flagger/pkg/logger"
+)
+
+func TestHandleTask(t *testing.T) {
+	logger, _ := logger.NewLogger("debug")
+	tr := NewTaskRunner(logger, time.Hour)
+	auth := NewGateAuthenticator(map[string]string{"secret": "alice"}, false)
+
+	taskFactory, _ := GetTaskFactory(TaskTypeShell)
+	task1, _ := taskFactory(map[string]string{"cmd": "sleep 10"}, "podinfo.default", logger)
+	task2, _ := taskFactory(map[string]string{"cmd": "sleep 10"}, "backend.default", logger)
+	tr.Add(task1)
+	tr.Add(task2)
+
+	do := func(handler http.HandlerFunc, method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		resp := httptest.NewRecorder()
+		handler(resp, req)
+		return resp
+	}
+
+	t.Run("list", func(t *testing.T) {
+		resp := do(HandleTaskList(logger, tr, auth), http.MethodGet, "/tasks?canary=podinfo.default")
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var tasks []TaskRecord
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &tasks))
+		require.Len(t, tasks, 1)
+		assert.Equal(t, task1.Hash(), tasks[0].Hash)
+		assert.Equal(t, TaskStatusQueued, tasks[0].Status)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		HandleTaskList(logger, tr, auth)(resp, httptest.NewRequest(http.MethodGet, "/tasks", nil))
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		resp := do(HandleTask(logger, tr, auth), http.MethodGet, "/tasks/"+task2.Hash())
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var task TaskRecord
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &task))
+		assert.Equal(t, "backend.default", task.Canary)
+
+		resp = do(HandleTask(logger, tr, auth), http.MethodGet, "/tasks/unknown")
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		resp := do(HandleTask(logger, tr, auth), http.MethodDelete, "/tasks/"+task2.Hash())
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+
+		record, _ := tr.Get(task2.Hash())
+		assert.Equal(t, TaskStatusCanceled, record.Status)
+
+		resp = do(HandleTask(logger, tr, auth), http.MethodDelete, "/tasks/"+task2.Hash())
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
@@ -51,3 +51,7 @@ func (task *CmdTask) Run(ctx context.Context) *TaskRunResult {
 func (task *CmdTask) String() string {
 	return task.command
 }
+
+func (task *CmdTask) Type() string {
+	return TaskTypeShell
+}
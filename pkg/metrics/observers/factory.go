@@ -64,6 +64,10 @@ func (factory Factory) Observer(provider string) Interface {
 		return &LinkerdObserver{
 			client: factory.Client,
 		}
+	case provider == flaggerv1.KnativeProvider:
+		return &KnativeObserver{
+			client: factory.Client,
+		}
 	case provider == "contour":
 		return &ContourObserver{
 			client: factory.Client,
@@ -0,0 +1,78 @@
+package observers
+
+import (
+	"fmt"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+// the queue-proxy sidecar reports the revision metrics with the latencies in milliseconds
+var knativeQueries = map[string]string{
+	"request-success-rate": `
+	sum(
+		rate(
+			revision_app_request_count{
+				namespace_name="{{ namespace }}",
+				revision_name="{{ revision }}",
+				response_code_class!="5xx"
+			}[{{ interval }}]
+		)
+	)
+	/
+	sum(
+		rate(
+			revision_app_request_count{
+				namespace_name="{{ namespace }}",
+				revision_name="{{ revision }}"
+			}[{{ interval }}]
+		)
+	)
+	* 100`,
+	"request-duration": `
+	histogram_quantile(
+		0.99,
+		sum(
+			rate(
+				revision_app_request_latencies_bucket{
+					namespace_name="{{ namespace }}",
+					revision_name="{{ revision }}"
+				}[{{ interval }}]
+			)
+		) by (le)
+	)`,
+}
+
+type KnativeObserver struct {
+	client providers.Interface
+}
+
+func (ob *KnativeObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	query, err := RenderQuery(knativeQueries["request-success-rate"], model)
+	if err != nil {
+		return 0, fmt.Errorf("rendering query failed: %w", err)
+	}
+
+	value, err := ob.client.RunQuery(query)
+	if err != nil {
+		return 0, fmt.Errorf("running query failed: %w", err)
+	}
+
+	return value, nil
+}
+
+func (ob *KnativeObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	query, err := RenderQuery(knativeQueries["request-duration"], model)
+	if err != nil {
+		return 0, fmt.Errorf("rendering query failed: %w", err)
+	}
+
+	value, err := ob.client.RunQuery(query)
+	if err != nil {
+		return 0, fmt.Errorf("running query failed: %w", err)
+	}
+
+	ms := time.Duration(int64(value)) * time.Millisecond
+	return ms, nil
+}
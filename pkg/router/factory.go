@@ -7,6 +7,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
 
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
 )
 
@@ -17,13 +18,15 @@ type Factory struct {
 	flaggerClient            clientset.Interface
 	ingressAnnotationsPrefix string
 	logger                   *zap.SugaredLogger
+	meshClusters             []MeshCluster
 }
 
 func NewFactory(kubeConfig *restclient.Config, kubeClient kubernetes.Interface,
 	flaggerClient clientset.Interface,
 	ingressAnnotationsPrefix string,
 	logger *zap.SugaredLogger,
-	meshClient clientset.Interface) *Factory {
+	meshClient clientset.Interface,
+	meshClusters ...MeshCluster) *Factory {
 	return &Factory{
 		kubeConfig:               kubeConfig,
 		meshClient:               meshClient,
@@ -31,9 +34,15 @@ func NewFactory(kubeConfig *restclient.Config, kubeClient kubernetes.Interface,
 		flaggerClient:            flaggerClient,
 		ingressAnnotationsPrefix: ingressAnnotationsPrefix,
 		logger:                   logger,
+		meshClusters:             meshClusters,
 	}
 }
 
+// HasMeshClusters returns true if the routing objects are applied to additional mesh clusters
+func (factory *Factory) HasMeshClusters() bool {
+	return factory != nil && len(factory.meshClusters) > 0
+}
+
 // KubernetesRouter returns a KubernetesRouter interface implementation
 func (factory *Factory) KubernetesRouter(kind string, labelSelector string, ports map[string]int32) KubernetesRouter {
 	switch kind {
@@ -87,6 +96,11 @@ func (factory *Factory) MeshRouter(provider string) Interface {
 			smiClient:     factory.meshClient,
 			targetMesh:    "linkerd",
 		}
+	case provider == flaggerv1.KnativeProvider:
+		return &KnativeRouter{
+			logger:        factory.logger,
+			flaggerClient: factory.flaggerClient,
+		}
 	case provider == "contour":
 		return &ContourRouter{
 			logger:        factory.logger,
@@ -119,6 +133,7 @@ func (factory *Factory) MeshRouter(provider string) Interface {
 			flaggerClient: factory.flaggerClient,
 			kubeClient:    factory.kubeClient,
 			istioClient:   factory.meshClient,
+			meshClusters:  factory.meshClusters,
 		}
 	case strings.HasPrefix(provider, "supergloo:linkerd"):
 		return &SmiRouter{
@@ -134,6 +149,7 @@ func (factory *Factory) MeshRouter(provider string) Interface {
 			flaggerClient: factory.flaggerClient,
 			kubeClient:    factory.kubeClient,
 			istioClient:   factory.meshClient,
+			meshClusters:  factory.meshClusters,
 		}
 	}
 }
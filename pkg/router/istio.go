@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -11,6 +12,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/kubernetes"
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
@@ -18,56 +20,112 @@ import (
 	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
 )
 
+// defaultMeshCluster is the name of the mesh cluster Flagger reads the routes from
+const defaultMeshCluster = "default"
+
+// meshClusterManagedLabel marks the routing objects created in the additional mesh clusters,
+// the canary doesn't exist in these clusters so the objects are deleted on finalization
+// instead of being garbage collected
+const meshClusterManagedLabel = "app.kubernetes.io/managed-by"
+
+// MeshCluster is an additional control plane cluster of an Istio multi-primary mesh
+type MeshCluster struct {
+	Name   string
+	Client clientset.Interface
+}
+
 // IstioRouter is managing Istio virtual services
 type IstioRouter struct {
 	kubeClient    kubernetes.Interface
 	istioClient   clientset.Interface
 	flaggerClient clientset.Interface
 	logger        *zap.SugaredLogger
+	// meshClusters are kept in sync with the istioClient cluster
+	meshClusters []MeshCluster
 }
 
-// Reconcile creates or updates the Istio virtual service and destination rules
-func (ir *IstioRouter) Reconcile(canary *flaggerv1.Canary) error {
-	_, primaryName, canaryName := canary.GetServiceNames()
+// clusters returns the mesh control planes the routing objects are applied to
+func (ir *IstioRouter) clusters() []MeshCluster {
+	return append([]MeshCluster{{Name: defaultMeshCluster, Client: ir.istioClient}}, ir.meshClusters...)
+}
 
-	if err := ir.reconcileDestinationRule(canary, canaryName); err != nil {
-		return fmt.Errorf("reconcileDestinationRule failed: %w", err)
+// forEachCluster runs the action against all the mesh clusters and reports the clusters where it failed
+func (ir *IstioRouter) forEachCluster(action string, fn func(cluster MeshCluster) error) error {
+	clusters := ir.clusters()
+	if len(clusters) == 1 {
+		return fn(clusters[0])
 	}
 
-	if err := ir.reconcileDestinationRule(canary, primaryName); err != nil {
-		return fmt.Errorf("reconcileDestinationRule failed: %w", err)
+	var errs []error
+	for _, cluster := range clusters {
+		if err := fn(cluster); err != nil {
+			errs = append(errs, fmt.Errorf("cluster %s: %w", cluster.Name, err))
+		}
 	}
-
-	if err := ir.reconcileVirtualService(canary); err != nil {
-		return fmt.Errorf("reconcileVirtualService failed: %w", err)
+	if len(errs) > 0 {
+		return fmt.Errorf("%s failed in %d of %d mesh clusters: %w", action, len(errs), len(clusters), utilerrors.NewAggregate(errs))
 	}
 	return nil
 }
 
-func (ir *IstioRouter) reconcileDestinationRule(canary *flaggerv1.Canary, name string) error {
+// Reconcile creates or updates the Istio virtual service and destination rules
+func (ir *IstioRouter) Reconcile(canary *flaggerv1.Canary) error {
+	return ir.forEachCluster("reconcile", func(cluster MeshCluster) error {
+		_, primaryName, canaryName := canary.GetServiceNames()
+
+		if err := ir.reconcileDestinationRule(cluster, canary, canaryName); err != nil {
+			return fmt.Errorf("reconcileDestinationRule failed: %w", err)
+		}
+
+		if err := ir.reconcileDestinationRule(cluster, canary, primaryName); err != nil {
+			return fmt.Errorf("reconcileDestinationRule failed: %w", err)
+		}
+
+		if err := ir.reconcileVirtualService(cluster, canary); err != nil {
+			return fmt.Errorf("reconcileVirtualService failed: %w", err)
+		}
+		return nil
+	})
+}
+
+// makeObjectMeta sets the canary as the controller of the routing objects created in the default cluster,
+// in the other mesh clusters the owner doesn't exist and the objects are labeled instead
+func makeObjectMeta(cluster MeshCluster, canary *flaggerv1.Canary, name string) metav1.ObjectMeta {
+	if cluster.Name != defaultMeshCluster {
+		return metav1.ObjectMeta{
+			Name:      name,
+			Namespace: canary.Namespace,
+			Labels:    map[string]string{meshClusterManagedLabel: "flagger"},
+		}
+	}
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: canary.Namespace,
+		OwnerReferences: []metav1.OwnerReference{
+			*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+				Group:   flaggerv1.SchemeGroupVersion.Group,
+				Version: flaggerv1.SchemeGroupVersion.Version,
+				Kind:    flaggerv1.CanaryKind,
+			}),
+		},
+	}
+}
+
+func (ir *IstioRouter) reconcileDestinationRule(cluster MeshCluster, canary *flaggerv1.Canary, name string) error {
+	istioClient := cluster.Client
 	newSpec := istiov1alpha3.DestinationRuleSpec{
 		Host:          name,
 		TrafficPolicy: canary.Spec.Service.TrafficPolicy,
 	}
 
-	destinationRule, err := ir.istioClient.NetworkingV1alpha3().DestinationRules(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	destinationRule, err := istioClient.NetworkingV1alpha3().DestinationRules(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
 	// insert
 	if errors.IsNotFound(err) {
 		destinationRule = &istiov1alpha3.DestinationRule{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      name,
-				Namespace: canary.Namespace,
-				OwnerReferences: []metav1.OwnerReference{
-					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
-						Group:   flaggerv1.SchemeGroupVersion.Group,
-						Version: flaggerv1.SchemeGroupVersion.Version,
-						Kind:    flaggerv1.CanaryKind,
-					}),
-				},
-			},
-			Spec: newSpec,
+			ObjectMeta: makeObjectMeta(cluster, canary, name),
+			Spec:       newSpec,
 		}
-		_, err = ir.istioClient.NetworkingV1alpha3().DestinationRules(canary.Namespace).Create(context.TODO(), destinationRule, metav1.CreateOptions{})
+		_, err = istioClient.NetworkingV1alpha3().DestinationRules(canary.Namespace).Create(context.TODO(), destinationRule, metav1.CreateOptions{})
 		if err != nil {
 			return fmt.Errorf("DestinationRule %s.%s create error: %w", name, canary.Namespace, err)
 		}
@@ -83,7 +141,7 @@ func (ir *IstioRouter) reconcileDestinationRule(canary *flaggerv1.Canary, name s
 		if diff := cmp.Diff(newSpec, destinationRule.Spec); diff != "" {
 			clone := destinationRule.DeepCopy()
 			clone.Spec = newSpec
-			_, err = ir.istioClient.NetworkingV1alpha3().DestinationRules(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+			_, err = istioClient.NetworkingV1alpha3().DestinationRules(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
 			if err != nil {
 				return fmt.Errorf("DestinationRule %s.%s update error: %w", name, canary.Namespace, err)
 			}
@@ -95,7 +153,8 @@ func (ir *IstioRouter) reconcileDestinationRule(canary *flaggerv1.Canary, name s
 	return nil
 }
 
-func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
+func (ir *IstioRouter) reconcileVirtualService(cluster MeshCluster, canary *flaggerv1.Canary) error {
+	istioClient := cluster.Client
 	apexName, primaryName, canaryName := canary.GetServiceNames()
 
 	// set hosts and add the ClusterIP service host if it doesn't exists
@@ -174,24 +233,14 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 		}
 	}
 
-	virtualService, err := ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	virtualService, err := istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	// insert
 	if errors.IsNotFound(err) {
 		virtualService = &istiov1alpha3.VirtualService{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      apexName,
-				Namespace: canary.Namespace,
-				OwnerReferences: []metav1.OwnerReference{
-					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
-						Group:   flaggerv1.SchemeGroupVersion.Group,
-						Version: flaggerv1.SchemeGroupVersion.Version,
-						Kind:    flaggerv1.CanaryKind,
-					}),
-				},
-			},
-			Spec: newSpec,
+			ObjectMeta: makeObjectMeta(cluster, canary, apexName),
+			Spec:       newSpec,
 		}
-		_, err = ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Create(context.TODO(), virtualService, metav1.CreateOptions{})
+		_, err = istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Create(context.TODO(), virtualService, metav1.CreateOptions{})
 		if err != nil {
 			return fmt.Errorf("VirtualService %s.%s create error: %w", apexName, canary.Namespace, err)
 		}
@@ -229,7 +278,7 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 				vtClone.ObjectMeta.Annotations[configAnnotation] = string(b)
 			}
 
-			_, err = ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Update(context.TODO(), vtClone, metav1.UpdateOptions{})
+			_, err = istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Update(context.TODO(), vtClone, metav1.UpdateOptions{})
 			if err != nil {
 				return fmt.Errorf("VirtualService %s.%s update error: %w", apexName, canary.Namespace, err)
 			}
@@ -295,15 +344,8 @@ func (ir *IstioRouter) SetRoutes(
 ) error {
 	apexName, primaryName, canaryName := canary.GetServiceNames()
 
-	vs, err := ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
-	if err != nil {
-		return fmt.Errorf("VirtualService %s.%s get query error %v", apexName, canary.Namespace, err)
-	}
-
-	vsCopy := vs.DeepCopy()
-
 	// weighted routing (progressive canary)
-	vsCopy.Spec.Http = []istiov1alpha3.HTTPRoute{
+	http := []istiov1alpha3.HTTPRoute{
 		{
 			Match:      canary.Spec.Service.Match,
 			Rewrite:    canary.Spec.Service.Rewrite,
@@ -319,12 +361,12 @@ func (ir *IstioRouter) SetRoutes(
 	}
 
 	if mirrored {
-		vsCopy.Spec.Http[0].Mirror = &istiov1alpha3.Destination{
+		http[0].Mirror = &istiov1alpha3.Destination{
 			Host: canaryName,
 		}
 
 		if mw := canary.GetAnalysis().MirrorWeight; mw > 0 {
-			vsCopy.Spec.Http[0].MirrorPercentage = &istiov1alpha3.Percent{Value: float64(mw)}
+			http[0].MirrorPercentage = &istiov1alpha3.Percent{Value: float64(mw)}
 		}
 	}
 
@@ -332,7 +374,7 @@ func (ir *IstioRouter) SetRoutes(
 	if len(canary.GetAnalysis().Match) > 0 {
 		// merge the common routes with the canary ones
 		canaryMatch := mergeMatchConditions(canary.GetAnalysis().Match, canary.Spec.Service.Match)
-		vsCopy.Spec.Http = []istiov1alpha3.HTTPRoute{
+		http = []istiov1alpha3.HTTPRoute{
 			{
 				Match:      canaryMatch,
 				Rewrite:    canary.Spec.Service.Rewrite,
@@ -359,18 +401,135 @@ func (ir *IstioRouter) SetRoutes(
 		}
 	}
 
-	vs, err = ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Update(context.TODO(), vsCopy, metav1.UpdateOptions{})
-	if err != nil {
-		return fmt.Errorf("VirtualService %s.%s update failed: %w", apexName, canary.Namespace, err)
+	clusters := ir.clusters()
+	current := make([]*istiov1alpha3.VirtualService, len(clusters))
+	for i, cluster := range clusters {
+		vs, err := cluster.Client.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+		if err != nil {
+			if len(clusters) > 1 {
+				return fmt.Errorf("VirtualService %s.%s get query error in cluster %s %v", apexName, canary.Namespace, cluster.Name, err)
+			}
+			return fmt.Errorf("VirtualService %s.%s get query error %v", apexName, canary.Namespace, err)
+		}
+		current[i] = vs
+	}
+
+	// stop at the first failed cluster and revert the weights of the updated ones
+	// so that the traffic is split in the same way across the mesh
+	updated := make([]*istiov1alpha3.VirtualService, 0, len(clusters))
+	for i, cluster := range clusters {
+		vsCopy := current[i].DeepCopy()
+		vsCopy.Spec.Http = http
+		vs, err := cluster.Client.NetworkingV1alpha3().VirtualServices(canary.Namespace).Update(context.TODO(), vsCopy, metav1.UpdateOptions{})
+		if err != nil {
+			if len(clusters) == 1 {
+				return fmt.Errorf("VirtualService %s.%s update failed: %w", apexName, canary.Namespace, err)
+			}
+			return ir.revertRoutes(canary, clusters[:len(updated)], current, updated,
+				fmt.Errorf("VirtualService %s.%s update failed in cluster %s: %w", apexName, canary.Namespace, cluster.Name, err))
+		}
+		updated = append(updated, vs)
 	}
 	return nil
 }
 
+// revertRoutes restores the routes of the virtual services updated before a cluster failed
+func (ir *IstioRouter) revertRoutes(
+	canary *flaggerv1.Canary,
+	clusters []MeshCluster,
+	current []*istiov1alpha3.VirtualService,
+	updated []*istiov1alpha3.VirtualService,
+	cause error,
+) error {
+	errs := []error{cause}
+	var reverted []string
+	for i, cluster := range clusters {
+		vsCopy := updated[i].DeepCopy()
+		vsCopy.Spec.Http = current[i].Spec.Http
+		_, err := cluster.Client.NetworkingV1alpha3().VirtualServices(canary.Namespace).Update(context.TODO(), vsCopy, metav1.UpdateOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("VirtualService %s.%s revert failed in cluster %s: %w", vsCopy.Name, canary.Namespace, cluster.Name, err))
+			continue
+		}
+		reverted = append(reverted, cluster.Name)
+	}
+	if len(reverted) > 0 {
+		ir.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Warnf("VirtualService %s.%s routes reverted in clusters %s", current[0].Name, canary.Namespace, strings.Join(reverted, ", "))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
 func (ir *IstioRouter) Finalize(canary *flaggerv1.Canary) error {
+	return ir.forEachCluster("finalize", func(cluster MeshCluster) error {
+		if cluster.Name != defaultMeshCluster {
+			if deleted, err := ir.deleteManagedObjects(cluster.Client, canary); err != nil || deleted {
+				return err
+			}
+		}
+		return ir.finalizeVirtualService(cluster.Client, canary)
+	})
+}
+
+// DeleteMeshClusterObjects removes the routing objects created by Flagger in the additional mesh clusters,
+// the objects of the default cluster are owned by the canary and left to the garbage collector
+func (ir *IstioRouter) DeleteMeshClusterObjects(canary *flaggerv1.Canary) error {
+	if len(ir.meshClusters) == 0 {
+		return nil
+	}
+	return ir.forEachCluster("delete", func(cluster MeshCluster) error {
+		if cluster.Name == defaultMeshCluster {
+			return nil
+		}
+		_, err := ir.deleteManagedObjects(cluster.Client, canary)
+		return err
+	})
+}
+
+// deleteManagedObjects removes the routing objects created by Flagger in an additional mesh cluster,
+// returns false if the virtual service exists and is not managed by Flagger
+func (ir *IstioRouter) deleteManagedObjects(istioClient clientset.Interface, canary *flaggerv1.Canary) (bool, error) {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+
+	for _, name := range []string{canaryName, primaryName} {
+		dr, err := istioClient.NetworkingV1alpha3().DestinationRules(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return false, fmt.Errorf("DestinationRule %s.%s get query error: %w", name, canary.Namespace, err)
+		}
+		if dr.Labels[meshClusterManagedLabel] != "flagger" {
+			continue
+		}
+		err = istioClient.NetworkingV1alpha3().DestinationRules(canary.Namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return false, fmt.Errorf("DestinationRule %s.%s delete error: %w", name, canary.Namespace, err)
+		}
+	}
+
+	vs, err := istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, fmt.Errorf("VirtualService %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+	if vs.Labels[meshClusterManagedLabel] != "flagger" {
+		return false, nil
+	}
+	err = istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Delete(context.TODO(), apexName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return false, fmt.Errorf("VirtualService %s.%s delete error: %w", apexName, canary.Namespace, err)
+	}
+	ir.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Infof("VirtualService %s.%s and DestinationRules deleted", apexName, canary.Namespace)
+	return true, nil
+}
+
+func (ir *IstioRouter) finalizeVirtualService(istioClient clientset.Interface, canary *flaggerv1.Canary) error {
 	// Need to see if I can get the annotation orig-configuration
 	apexName, _, _ := canary.GetServiceNames()
 
-	vs, err := ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	vs, err := istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("VirtualService %s.%s get query error: %w", apexName, canary.Namespace, err)
 	}
@@ -396,7 +555,7 @@ func (ir *IstioRouter) Finalize(canary *flaggerv1.Canary) error {
 	clone := vs.DeepCopy()
 	clone.Spec = storedSpec
 
-	_, err = ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+	_, err = istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
 	if err != nil {
 		return fmt.Errorf("VirtualService %s.%s update error: %w", apexName, canary.Namespace, err)
 	}
@@ -8,10 +8,14 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
 
 	"github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
+	fakeFlagger "github.com/weaveworks/flagger/pkg/client/clientset/versioned/fake"
 )
 
 func TestIstioRouter_Sync(t *testing.T) {
@@ -429,3 +433,105 @@ func TestIstioRouter_Finalize(t *testing.T) {
 		}
 	}
 }
+
+func TestIstioRouter_MultiCluster(t *testing.T) {
+	mocks := newFixture(nil)
+	east := fakeFlagger.NewSimpleClientset()
+	west := fakeFlagger.NewSimpleClientset()
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+		meshClusters: []MeshCluster{
+			{Name: "east", Client: east},
+			{Name: "west", Client: west},
+		},
+	}
+
+	weights := func(client *fakeFlagger.Clientset) []int {
+		vs, err := client.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Len(t, vs.Spec.Http, 1)
+		var w []int
+		for _, route := range vs.Spec.Http[0].Route {
+			w = append(w, route.Weight)
+		}
+		return w
+	}
+
+	// the routing objects are created in all the clusters
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+	for _, client := range []*fakeFlagger.Clientset{east, west} {
+		dr, err := client.NetworkingV1alpha3().DestinationRules("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, dr.OwnerReferences)
+		assert.Equal(t, []int{100, 0}, weights(client))
+	}
+
+	err = router.SetRoutes(mocks.canary, 60, 40, false)
+	require.NoError(t, err)
+	assert.Equal(t, []int{60, 40}, weights(east))
+	assert.Equal(t, []int{60, 40}, weights(west))
+
+	// the weights are reverted if a cluster fails to update
+	west.PrependReactor("update", "virtualservices", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("connection refused")
+	})
+	err = router.SetRoutes(mocks.canary, 50, 50, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "update failed in cluster west")
+	assert.Equal(t, []int{60, 40}, weights(east))
+	assert.Equal(t, []int{60, 40}, weights(west))
+
+	p, c, _, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, p)
+	assert.Equal(t, 40, c)
+
+	// partial failures are reported for each cluster
+	west.PrependReactor("get", "virtualservices", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("connection refused")
+	})
+	err = router.Finalize(mocks.canary)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "finalize failed in 1 of 3 mesh clusters")
+	assert.Contains(t, err.Error(), "cluster west")
+}
+
+func TestIstioRouter_MultiClusterFinalize(t *testing.T) {
+	mocks := newFixture(nil)
+	east := fakeFlagger.NewSimpleClientset()
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+		meshClusters:  []MeshCluster{{Name: "east", Client: east}},
+	}
+
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	// the canary owns the objects of the default cluster only
+	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, metav1.IsControlledBy(vs, mocks.canary))
+	vs, err = east.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, vs.OwnerReferences)
+
+	// the objects created in the other clusters are deleted on finalization
+	err = router.Finalize(mocks.canary)
+	require.NoError(t, err)
+
+	_, err = east.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	for _, name := range []string{"podinfo-primary", "podinfo-canary"} {
+		_, err = east.NetworkingV1alpha3().DestinationRules("default").Get(context.TODO(), name, metav1.GetOptions{})
+		assert.True(t, errors.IsNotFound(err))
+	}
+	_, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+}
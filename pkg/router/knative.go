@@ -0,0 +1,190 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	knative "github.com/weaveworks/flagger/pkg/apis/knative/v1"
+	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
+)
+
+// KnativeRouter is managing the traffic targets of Knative Services
+type KnativeRouter struct {
+	flaggerClient clientset.Interface
+	logger        *zap.SugaredLogger
+}
+
+// Reconcile pins the service traffic to the primary revision
+// if the traffic targets are not managed by Flagger
+func (kr *KnativeRouter) Reconcile(canary *flaggerv1.Canary) error {
+	svc, err := kr.getService(canary)
+	if err != nil {
+		return err
+	}
+
+	primary := svc.Annotations[flaggerv1.KnativePrimaryRevisionAnnotation]
+	if primary == "" {
+		return fmt.Errorf("knative service %s.%s primary revision not found", svc.Name, svc.Namespace)
+	}
+
+	pinned := false
+	for _, target := range svc.Spec.Traffic {
+		if target.RevisionName == "" {
+			pinned = false
+			break
+		}
+		if target.RevisionName == primary {
+			pinned = true
+		}
+	}
+	if pinned {
+		return nil
+	}
+
+	if err := kr.SetRoutes(canary, 100, 0, false); err != nil {
+		return err
+	}
+
+	kr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Infof("Knative service %s.%s traffic pinned to revision %s", svc.Name, svc.Namespace, primary)
+	return nil
+}
+
+// SetRoutes updates the traffic percentages of the primary and canary revisions
+func (kr *KnativeRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	_ bool,
+) error {
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		svc, err := kr.getService(canary)
+		if err != nil {
+			return err
+		}
+
+		primary := svc.Annotations[flaggerv1.KnativePrimaryRevisionAnnotation]
+		if primary == "" {
+			return fmt.Errorf("knative service %s.%s primary revision not found", svc.Name, svc.Namespace)
+		}
+
+		traffic := []knative.TrafficTarget{
+			{
+				Tag:            "primary",
+				RevisionName:   primary,
+				LatestRevision: boolp(false),
+				Percent:        int64p(int64(primaryWeight)),
+			},
+		}
+
+		// the latest created revision is the canary, tagging it exposes a dedicated URL
+		// that can be used by the pre-rollout and load testing webhooks
+		if rev := svc.Status.LatestCreatedRevisionName; rev != "" && rev != primary {
+			traffic = append(traffic, knative.TrafficTarget{
+				Tag:            "canary",
+				RevisionName:   rev,
+				LatestRevision: boolp(false),
+				Percent:        int64p(int64(canaryWeight)),
+			})
+		} else {
+			traffic[0].Percent = int64p(100)
+		}
+
+		return kr.patchTraffic(svc, traffic)
+	})
+	if err != nil {
+		return fmt.Errorf("knative service %s.%s update error: %w", canary.Spec.TargetRef.Name, canary.Namespace, err)
+	}
+	return nil
+}
+
+// GetRoutes returns the traffic percentages of the primary and canary revisions
+func (kr *KnativeRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	svc, err := kr.getService(canary)
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	primary := svc.Annotations[flaggerv1.KnativePrimaryRevisionAnnotation]
+	for _, target := range svc.Spec.Traffic {
+		if target.Percent == nil {
+			continue
+		}
+		if target.RevisionName == "" || target.RevisionName == primary {
+			primaryWeight += int(*target.Percent)
+		} else {
+			canaryWeight += int(*target.Percent)
+		}
+	}
+
+	if primaryWeight == 0 && canaryWeight == 0 {
+		return 0, 0, false, fmt.Errorf("knative service %s.%s traffic targets not found", svc.Name, svc.Namespace)
+	}
+	return
+}
+
+// Finalize routes all the traffic to the latest ready revision
+func (kr *KnativeRouter) Finalize(canary *flaggerv1.Canary) error {
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		svc, err := kr.getService(canary)
+		if err != nil {
+			return err
+		}
+
+		return kr.patchTraffic(svc, []knative.TrafficTarget{
+			{
+				LatestRevision: boolp(true),
+				Percent:        int64p(100),
+			},
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("knative service %s.%s update error: %w", canary.Spec.TargetRef.Name, canary.Namespace, err)
+	}
+	return nil
+}
+
+// patchTraffic replaces the traffic targets with a merge patch so that the service fields
+// not modelled by Flagger are left untouched, the patch fails with a conflict
+// if the service changed since it was read
+func (kr *KnativeRouter) patchTraffic(svc *knative.Service, traffic []knative.TrafficTarget) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": svc.ResourceVersion,
+		},
+		"spec": map[string]interface{}{
+			"traffic": traffic,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("knative service %s.%s patch encoding failed: %w", svc.Name, svc.Namespace, err)
+	}
+
+	_, err = kr.flaggerClient.ServingV1().Services(svc.Namespace).Patch(context.TODO(), svc.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+func (kr *KnativeRouter) getService(canary *flaggerv1.Canary) (*knative.Service, error) {
+	targetName := canary.Spec.TargetRef.Name
+	svc, err := kr.flaggerClient.ServingV1().Services(canary.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("knative service %s.%s get query error: %w", targetName, canary.Namespace, err)
+	}
+	return svc, nil
+}
+
+func boolp(b bool) *bool {
+	return &b
+}
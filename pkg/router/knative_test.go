@@ -0,0 +1,150 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8stesting "k8s.io/client-go/testing"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	knative "github.com/weaveworks/flagger/pkg/apis/knative/v1"
+	fakeFlagger "github.com/weaveworks/flagger/pkg/client/clientset/versioned/fake"
+	"github.com/weaveworks/flagger/pkg/logger"
+)
+
+func TestKnativeRouter_Reconcile(t *testing.T) {
+	canary := newTestKnativeCanary()
+	flaggerClient := fakeFlagger.NewSimpleClientset(canary, newTestKnativeService())
+	logger, _ := logger.NewLogger("debug")
+	router := &KnativeRouter{flaggerClient: flaggerClient, logger: logger}
+
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	svc, err := flaggerClient.ServingV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, svc.Spec.Traffic, 1)
+	assert.Equal(t, "podinfo-00001", svc.Spec.Traffic[0].RevisionName)
+	assert.Equal(t, int64(100), *svc.Spec.Traffic[0].Percent)
+	assert.False(t, *svc.Spec.Traffic[0].LatestRevision)
+
+	primaryWeight, canaryWeight, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, primaryWeight)
+	assert.Equal(t, 0, canaryWeight)
+}
+
+func TestKnativeRouter_SetRoutes(t *testing.T) {
+	canary := newTestKnativeCanary()
+	svc := newTestKnativeService()
+	svc.Status.LatestCreatedRevisionName = "podinfo-00002"
+	flaggerClient := fakeFlagger.NewSimpleClientset(canary, svc)
+	logger, _ := logger.NewLogger("debug")
+	router := &KnativeRouter{flaggerClient: flaggerClient, logger: logger}
+
+	require.NoError(t, router.Reconcile(canary))
+
+	err := router.SetRoutes(canary, 70, 30, false)
+	require.NoError(t, err)
+
+	svc, err = flaggerClient.ServingV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, svc.Spec.Traffic, 2)
+	assert.Equal(t, "primary", svc.Spec.Traffic[0].Tag)
+	assert.Equal(t, "canary", svc.Spec.Traffic[1].Tag)
+	assert.Equal(t, "podinfo-00002", svc.Spec.Traffic[1].RevisionName)
+
+	primaryWeight, canaryWeight, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 70, primaryWeight)
+	assert.Equal(t, 30, canaryWeight)
+
+	// the weights are kept on reconciliation
+	require.NoError(t, router.Reconcile(canary))
+	primaryWeight, canaryWeight, _, err = router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 70, primaryWeight)
+	assert.Equal(t, 30, canaryWeight)
+
+	// only the traffic targets are patched
+	for _, action := range flaggerClient.Actions() {
+		if action.GetResource().Resource != "services" || action.GetVerb() == "get" {
+			continue
+		}
+		patch, ok := action.(k8stesting.PatchAction)
+		require.True(t, ok, "unexpected %s action", action.GetVerb())
+		assert.Equal(t, types.MergePatchType, patch.GetPatchType())
+		var obj struct {
+			Metadata map[string]interface{} `json:"metadata"`
+			Spec     map[string]interface{} `json:"spec"`
+		}
+		require.NoError(t, json.Unmarshal(patch.GetPatch(), &obj))
+		assert.Len(t, obj.Metadata, 1)
+		assert.Contains(t, obj.Metadata, "resourceVersion")
+		assert.Len(t, obj.Spec, 1)
+		assert.Contains(t, obj.Spec, "traffic")
+	}
+}
+
+func TestKnativeRouter_Finalize(t *testing.T) {
+	canary := newTestKnativeCanary()
+	flaggerClient := fakeFlagger.NewSimpleClientset(canary, newTestKnativeService())
+	logger, _ := logger.NewLogger("debug")
+	router := &KnativeRouter{flaggerClient: flaggerClient, logger: logger}
+
+	require.NoError(t, router.Reconcile(canary))
+	require.NoError(t, router.Finalize(canary))
+
+	svc, err := flaggerClient.ServingV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, svc.Spec.Traffic, 1)
+	assert.True(t, *svc.Spec.Traffic[0].LatestRevision)
+	assert.Equal(t, int64(100), *svc.Spec.Traffic[0].Percent)
+}
+
+func newTestKnativeCanary() *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		TypeMeta: metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo",
+		},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{
+				Name:       "podinfo",
+				APIVersion: flaggerv1.KnativeServiceAPIVersion,
+				Kind:       "Service",
+			},
+		},
+	}
+}
+
+func newTestKnativeService() *knative.Service {
+	return &knative.Service{
+		TypeMeta: metav1.TypeMeta{APIVersion: knative.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo",
+			Annotations: map[string]string{
+				flaggerv1.KnativePrimaryRevisionAnnotation: "podinfo-00001",
+			},
+		},
+		Spec: knative.ServiceSpec{
+			Traffic: []knative.TrafficTarget{
+				{
+					LatestRevision: boolp(true),
+					Percent:        int64p(100),
+				},
+			},
+		},
+		Status: knative.ServiceStatus{
+			LatestReadyRevisionName:   "podinfo-00001",
+			LatestCreatedRevisionName: "podinfo-00001",
+		},
+	}
+}
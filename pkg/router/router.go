@@ -11,3 +11,8 @@ type Interface interface {
 	GetRoutes(canary *flaggerv1.Canary) (primaryWeight int, canaryWeight int, mirrored bool, err error)
 	Finalize(canary *flaggerv1.Canary) error
 }
+
+// MeshClusterRouter is implemented by the routers that apply the routing objects to additional mesh clusters
+type MeshClusterRouter interface {
+	DeleteMeshClusterObjects(canary *flaggerv1.Canary) error
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"go.uber.org/zap"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/validation"
+)
+
+// HandleAdmission registers the validating and mutating admission webhooks
+// of the Flagger custom resources on the HTTPS server mux
+func HandleAdmission(mux *http.ServeMux, logger *zap.SugaredLogger) {
+	h := &admissionHandler{logger: logger}
+	mux.HandleFunc("/validate", h.serve(h.validate))
+	mux.HandleFunc("/mutate", h.serve(h.mutate))
+}
+
+type admissionHandler struct {
+	logger *zap.SugaredLogger
+}
+
+// serve decodes the admission review, the response is sent with the API version of the request
+// so that both admission.k8s.io/v1 and v1beta1 reviews are supported
+func (h *admissionHandler) serve(admit func(*admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("reading the request body failed: %v", err), http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		review := &admissionv1.AdmissionReview{}
+		if err := json.Unmarshal(body, review); err != nil || review.Request == nil {
+			http.Error(w, fmt.Sprintf("decoding the admission review failed: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		response := admit(review.Request)
+		response.UID = review.Request.UID
+		review.Response = response
+		review.Request = nil
+
+		res, err := json.Marshal(review)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("encoding the admission review failed: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(res)
+	}
+}
+
+func (h *admissionHandler) validate(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if req.Operation == admissionv1.Delete || !isV1beta1(req) {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	var errs field.ErrorList
+	var err error
+	switch req.Kind.Kind {
+	case flaggerv1.CanaryKind:
+		cd := &flaggerv1.Canary{}
+		if err = json.Unmarshal(req.Object.Raw, cd); err == nil {
+			errs = validation.ValidateCanary(cd)
+		}
+	case flaggerv1.CanaryTemplateKind:
+		template := &flaggerv1.CanaryTemplate{}
+		if err = json.Unmarshal(req.Object.Raw, template); err == nil {
+			errs = validation.ValidateCanaryTemplate(template.Spec)
+		}
+	case flaggerv1.ClusterCanaryTemplateKind:
+		template := &flaggerv1.ClusterCanaryTemplate{}
+		if err = json.Unmarshal(req.Object.Raw, template); err == nil {
+			errs = validation.ValidateCanaryTemplate(template.Spec)
+		}
+	case flaggerv1.MetricTemplateKind:
+		template := &flaggerv1.MetricTemplate{}
+		if err = json.Unmarshal(req.Object.Raw, template); err == nil {
+			errs = validation.ValidateMetricTemplate(template)
+		}
+	case flaggerv1.AlertProviderKind:
+		provider := &flaggerv1.AlertProvider{}
+		if err = json.Unmarshal(req.Object.Raw, provider); err == nil {
+			errs = validation.ValidateAlertProvider(provider)
+		}
+	case flaggerv1.CanaryFleetKind:
+		fleet := &flaggerv1.CanaryFleet{}
+		if err = json.Unmarshal(req.Object.Raw, fleet); err == nil {
+			errs = validation.ValidateCanaryFleet(fleet)
+		}
+	default:
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	if err != nil {
+		return denied(req, http.StatusBadRequest, fmt.Sprintf("decoding %s failed: %v", req.Kind.Kind, err))
+	}
+	if len(errs) > 0 {
+		h.logger.Debugf("Admission denied %s %s.%s: %v", req.Kind.Kind, req.Name, req.Namespace, errs.ToAggregate())
+		return denied(req, http.StatusUnprocessableEntity, errs.ToAggregate().Error())
+	}
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+func (h *admissionHandler) mutate(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if req.Operation == admissionv1.Delete || !isV1beta1(req) {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	var spec interface{}
+	switch req.Kind.Kind {
+	case flaggerv1.CanaryKind:
+		cd := &flaggerv1.Canary{}
+		if err := json.Unmarshal(req.Object.Raw, cd); err != nil {
+			return denied(req, http.StatusBadRequest, fmt.Sprintf("decoding %s failed: %v", req.Kind.Kind, err))
+		}
+		validation.SetCanaryDefaults(cd)
+		spec = cd.Spec
+	case flaggerv1.CanaryTemplateKind, flaggerv1.ClusterCanaryTemplateKind:
+		template := &flaggerv1.CanaryTemplate{}
+		if err := json.Unmarshal(req.Object.Raw, template); err != nil {
+			return denied(req, http.StatusBadRequest, fmt.Sprintf("decoding %s failed: %v", req.Kind.Kind, err))
+		}
+		if template.Spec.Analysis != nil {
+			validation.SetAnalysisDefaults(template.Spec.Analysis)
+		}
+		spec = template.Spec
+	default:
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	patch, err := specPatch(req.Object.Raw, spec)
+	if err != nil {
+		return denied(req, http.StatusInternalServerError, err.Error())
+	}
+	if patch == nil {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	patchType := admissionv1.PatchTypeJSONPatch
+	return &admissionv1.AdmissionResponse{
+		Allowed:   true,
+		Patch:     patch,
+		PatchType: &patchType,
+	}
+}
+
+// isV1beta1 returns true if the request object can be decoded with the v1beta1 types,
+// the objects of the other versions are let through as decoding them would drop the fields missing from v1beta1
+func isV1beta1(req *admissionv1.AdmissionRequest) bool {
+	return req.Kind.Group == flaggerv1.SchemeGroupVersion.Group && req.Kind.Version == flaggerv1.SchemeGroupVersion.Version
+}
+
+// specPatch returns a JSON patch that replaces the object spec or nil if the spec is unchanged
+func specPatch(raw []byte, spec interface{}) ([]byte, error) {
+	var obj struct {
+		Spec json.RawMessage `json:"spec"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, fmt.Errorf("decoding spec failed: %w", err)
+	}
+
+	current := map[string]interface{}{}
+	if len(obj.Spec) > 0 {
+		if err := json.Unmarshal(obj.Spec, &current); err != nil {
+			return nil, fmt.Errorf("decoding spec failed: %w", err)
+		}
+	}
+	currentJSON, _ := json.Marshal(current)
+
+	desiredJSON, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("encoding spec failed: %w", err)
+	}
+	desired := map[string]interface{}{}
+	if err := json.Unmarshal(desiredJSON, &desired); err != nil {
+		return nil, fmt.Errorf("decoding spec failed: %w", err)
+	}
+	desiredJSON, _ = json.Marshal(desired)
+
+	if bytes.Equal(currentJSON, desiredJSON) {
+		return nil, nil
+	}
+
+	return json.Marshal([]map[string]interface{}{
+		{"op": "replace", "path": "/spec", "value": desired},
+	})
+}
+
+func denied(req *admissionv1.AdmissionRequest, code int32, message string) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    code,
+			Reason:  metav1.StatusReasonInvalid,
+			Message: fmt.Sprintf("%s %s is invalid: %s", req.Kind.Kind, req.Name, message),
+		},
+	}
+}
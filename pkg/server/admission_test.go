@@ -0,0 +1,134 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/logger"
+)
+
+func newTestAdmissionReview(t *testing.T, cd *flaggerv1.Canary) []byte {
+	raw, err := json.Marshal(cd)
+	require.NoError(t, err)
+
+	review := admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       "test",
+			Kind:      metav1.GroupVersionKind{Group: "flagger.app", Version: "v1beta1", Kind: flaggerv1.CanaryKind},
+			Name:      cd.Name,
+			Namespace: cd.Namespace,
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+	body, err := json.Marshal(review)
+	require.NoError(t, err)
+	return body
+}
+
+func postAdmissionReview(t *testing.T, handler http.HandlerFunc, body []byte) *admissionv1.AdmissionResponse {
+	req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	review := &admissionv1.AdmissionReview{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), review))
+	require.NotNil(t, review.Response)
+	assert.Equal(t, "admission.k8s.io/v1", review.APIVersion)
+	assert.Equal(t, "test", string(review.Response.UID))
+	return review.Response
+}
+
+func TestAdmission(t *testing.T) {
+	logger, _ := logger.NewLogger("debug")
+	h := &admissionHandler{logger: logger}
+
+	cd := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "podinfo"},
+			Service:   flaggerv1.CanaryService{Port: 9898},
+			Analysis: &flaggerv1.CanaryAnalysis{
+				Threshold:  5,
+				MaxWeight:  50,
+				StepWeight: 10,
+			},
+		},
+	}
+
+	// valid canary
+	res := postAdmissionReview(t, h.serve(h.validate), newTestAdmissionReview(t, cd))
+	assert.True(t, res.Allowed)
+
+	// defaults
+	res = postAdmissionReview(t, h.serve(h.mutate), newTestAdmissionReview(t, cd))
+	assert.True(t, res.Allowed)
+	require.NotNil(t, res.PatchType)
+	var patch []struct {
+		Op    string               `json:"op"`
+		Path  string               `json:"path"`
+		Value flaggerv1.CanarySpec `json:"value"`
+	}
+	require.NoError(t, json.Unmarshal(res.Patch, &patch))
+	require.Len(t, patch, 1)
+	assert.Equal(t, "/spec", patch[0].Path)
+	assert.Equal(t, "1m", patch[0].Value.Analysis.Interval)
+	assert.Equal(t, int32(600), *patch[0].Value.ProgressDeadlineSeconds)
+
+	// invalid canary
+	cd.Spec.Analysis.StepWeight = 60
+	res = postAdmissionReview(t, h.serve(h.validate), newTestAdmissionReview(t, cd))
+	assert.False(t, res.Allowed)
+	assert.Contains(t, res.Result.Message, "spec.analysis.stepWeight")
+}
+
+func TestAdmission_V1(t *testing.T) {
+	logger, _ := logger.NewLogger("debug")
+	h := &admissionHandler{logger: logger}
+
+	// the istio options are not part of the v1beta1 service
+	raw := []byte(`{
+  "apiVersion": "flagger.app/v1",
+  "kind": "Canary",
+  "metadata": {"name": "podinfo", "namespace": "default"},
+  "spec": {
+    "targetRef": {"apiVersion": "apps/v1", "kind": "Deployment", "name": "podinfo"},
+    "service": {"port": 9898, "istio": {"headers": {"request": {"add": {"x-canary": "true"}}}}},
+    "analysis": {"threshold": 5, "maxWeight": 50, "stepWeight": 10}
+  }
+}`)
+	review := admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       "test",
+			Kind:      metav1.GroupVersionKind{Group: "flagger.app", Version: "v1", Kind: flaggerv1.CanaryKind},
+			Name:      "podinfo",
+			Namespace: "default",
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+	body, err := json.Marshal(review)
+	require.NoError(t, err)
+
+	res := postAdmissionReview(t, h.serve(h.validate), body)
+	assert.True(t, res.Allowed)
+
+	// the spec is not replaced with the v1beta1 one
+	res = postAdmissionReview(t, h.serve(h.mutate), body)
+	assert.True(t, res.Allowed)
+	assert.Nil(t, res.PatchType)
+	assert.Empty(t, res.Patch)
+}
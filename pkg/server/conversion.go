@@ -0,0 +1,133 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1"
+	"github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// ConversionReview is the apiextensions.k8s.io conversion request and response,
+// the v1 and v1beta1 reviews share the same wire format
+type ConversionReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *ConversionRequest  `json:"request,omitempty"`
+	Response        *ConversionResponse `json:"response,omitempty"`
+}
+
+// ConversionRequest holds the objects to be converted to the desired API version
+type ConversionRequest struct {
+	UID               types.UID              `json:"uid"`
+	DesiredAPIVersion string                 `json:"desiredAPIVersion"`
+	Objects           []runtime.RawExtension `json:"objects"`
+}
+
+// ConversionResponse holds the converted objects in the same order as the request
+type ConversionResponse struct {
+	UID              types.UID              `json:"uid"`
+	ConvertedObjects []runtime.RawExtension `json:"convertedObjects"`
+	Result           metav1.Status          `json:"result"`
+}
+
+// HandleConversion registers the Canary conversion webhook on the HTTPS server mux
+func HandleConversion(mux *http.ServeMux, logger *zap.SugaredLogger) {
+	mux.HandleFunc("/convert", convert(logger))
+}
+
+func convert(logger *zap.SugaredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("reading the request body failed: %v", err), http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		review := &ConversionReview{}
+		if err := json.Unmarshal(body, review); err != nil || review.Request == nil {
+			http.Error(w, fmt.Sprintf("decoding the conversion review failed: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		response := &ConversionResponse{
+			UID:    review.Request.UID,
+			Result: metav1.Status{Status: metav1.StatusSuccess},
+		}
+		for _, obj := range review.Request.Objects {
+			converted, err := ConvertCanary(obj.Raw, review.Request.DesiredAPIVersion)
+			if err != nil {
+				logger.Errorf("Canary conversion to %s failed: %v", review.Request.DesiredAPIVersion, err)
+				response.ConvertedObjects = nil
+				response.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+				break
+			}
+			response.ConvertedObjects = append(response.ConvertedObjects, runtime.RawExtension{Raw: converted})
+		}
+		review.Request = nil
+		review.Response = response
+
+		res, err := json.Marshal(review)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("encoding the conversion review failed: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(res)
+	}
+}
+
+// ConvertCanary converts a Canary object between the flagger.app v1beta1 and v1 API versions
+func ConvertCanary(raw []byte, desiredAPIVersion string) ([]byte, error) {
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, fmt.Errorf("decoding object failed: %w", err)
+	}
+	if typeMeta.Kind != flaggerv1.CanaryKind {
+		return nil, fmt.Errorf("kind %s not supported", typeMeta.Kind)
+	}
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+
+	// v1alpha3 shares the v1beta1 schema and is converted by rewriting the API version
+	var out interface{}
+	switch {
+	case isV1beta1Schema(typeMeta.APIVersion) && isV1beta1Schema(desiredAPIVersion):
+		cd := &v1beta1.Canary{}
+		if err := json.Unmarshal(raw, cd); err != nil {
+			return nil, fmt.Errorf("decoding %s canary failed: %w", typeMeta.APIVersion, err)
+		}
+		cd.APIVersion = desiredAPIVersion
+		out = cd
+	case isV1beta1Schema(typeMeta.APIVersion) && desiredAPIVersion == flaggerv1.SchemeGroupVersion.String():
+		cd := &v1beta1.Canary{}
+		if err := json.Unmarshal(raw, cd); err != nil {
+			return nil, fmt.Errorf("decoding %s canary failed: %w", typeMeta.APIVersion, err)
+		}
+		out = flaggerv1.ConvertFromV1beta1(cd)
+	case typeMeta.APIVersion == flaggerv1.SchemeGroupVersion.String() && isV1beta1Schema(desiredAPIVersion):
+		cd := &flaggerv1.Canary{}
+		if err := json.Unmarshal(raw, cd); err != nil {
+			return nil, fmt.Errorf("decoding %s canary failed: %w", typeMeta.APIVersion, err)
+		}
+		converted := cd.ToV1beta1()
+		converted.APIVersion = desiredAPIVersion
+		out = converted
+	default:
+		return nil, fmt.Errorf("conversion from %s to %s not supported", typeMeta.APIVersion, desiredAPIVersion)
+	}
+
+	return json.Marshal(out)
+}
+
+func isV1beta1Schema(apiVersion string) bool {
+	return apiVersion == v1beta1.SchemeGroupVersion.String() || apiVersion == "flagger.app/v1alpha3"
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1"
+	"github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/logger"
+)
+
+func postConversionReview(t *testing.T, desiredAPIVersion string, objects ...interface{}) *ConversionResponse {
+	logger, _ := logger.NewLogger("debug")
+
+	review := ConversionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1beta1", Kind: "ConversionReview"},
+		Request:  &ConversionRequest{UID: "test", DesiredAPIVersion: desiredAPIVersion},
+	}
+	for _, obj := range objects {
+		raw, err := json.Marshal(obj)
+		require.NoError(t, err)
+		review.Request.Objects = append(review.Request.Objects, runtime.RawExtension{Raw: raw})
+	}
+	body, err := json.Marshal(review)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/convert", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	convert(logger).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	res := &ConversionReview{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), res))
+	require.NotNil(t, res.Response)
+	assert.Equal(t, "apiextensions.k8s.io/v1beta1", res.APIVersion)
+	assert.Equal(t, "test", string(res.Response.UID))
+	return res.Response
+}
+
+func TestConversion(t *testing.T) {
+	cd := &v1beta1.Canary{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "flagger.app/v1beta1", Kind: "Canary"},
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: v1beta1.CanarySpec{
+			TargetRef: v1beta1.CrossNamespaceObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "podinfo"},
+			Service:   v1beta1.CanaryService{Port: 9898, MeshName: "global"},
+			CanaryAnalysis: &v1beta1.CanaryAnalysis{
+				Interval:   "1m",
+				Threshold:  5,
+				StepWeight: 10,
+			},
+		},
+		Status: v1beta1.CanaryStatus{Phase: v1beta1.CanaryPhaseProgressing, CanaryWeight: 10},
+	}
+
+	// v1beta1 to v1
+	res := postConversionReview(t, "flagger.app/v1", cd)
+	require.Equal(t, metav1.StatusSuccess, res.Result.Status)
+	require.Len(t, res.ConvertedObjects, 1)
+
+	v1 := &flaggerv1.Canary{}
+	require.NoError(t, json.Unmarshal(res.ConvertedObjects[0].Raw, v1))
+	assert.Equal(t, "flagger.app/v1", v1.APIVersion)
+	assert.Equal(t, "global", v1.Spec.Service.AppMesh.MeshName)
+	assert.Equal(t, 10, v1.Spec.Analysis.StepWeight)
+	assert.Equal(t, 10, v1.Status.Analysis.CanaryWeight)
+
+	// v1 to v1beta1
+	res = postConversionReview(t, "flagger.app/v1beta1", v1)
+	require.Equal(t, metav1.StatusSuccess, res.Result.Status)
+	require.Len(t, res.ConvertedObjects, 1)
+
+	v1beta1Canary := &v1beta1.Canary{}
+	require.NoError(t, json.Unmarshal(res.ConvertedObjects[0].Raw, v1beta1Canary))
+	assert.Equal(t, "flagger.app/v1beta1", v1beta1Canary.APIVersion)
+	assert.Equal(t, "global", v1beta1Canary.Spec.Service.MeshName)
+	assert.Equal(t, cd.Spec.CanaryAnalysis, v1beta1Canary.Spec.Analysis)
+	assert.Equal(t, 10, v1beta1Canary.Status.CanaryWeight)
+
+	// v1beta1 to v1alpha3
+	res = postConversionReview(t, "flagger.app/v1alpha3", cd)
+	require.Equal(t, metav1.StatusSuccess, res.Result.Status)
+	v1alpha3 := &v1beta1.Canary{}
+	require.NoError(t, json.Unmarshal(res.ConvertedObjects[0].Raw, v1alpha3))
+	assert.Equal(t, "flagger.app/v1alpha3", v1alpha3.APIVersion)
+	assert.Equal(t, cd.Spec, v1alpha3.Spec)
+
+	// unsupported version
+	res = postConversionReview(t, "flagger.app/v2", cd, v1)
+	assert.Equal(t, metav1.StatusFailure, res.Result.Status)
+	assert.Contains(t, res.Result.Message, "not supported")
+	assert.Empty(t, res.ConvertedObjects)
+}
@@ -18,14 +18,7 @@ func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogge
 		w.Write([]byte("OK"))
 	})
 
-	srv := &http.Server{
-		Addr:         ":" + port,
-		Handler:      mux,
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 1 * time.Minute,
-		IdleTimeout:  15 * time.Second,
-	}
-
+	srv := newServer(port, mux)
 	logger.Infof("Starting HTTP server on port %s", port)
 
 	// run server in background
@@ -35,7 +28,37 @@ func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogge
 		}
 	}()
 
-	// wait for SIGTERM or SIGINT
+	shutdown(srv, timeout, logger, stopCh)
+}
+
+// ListenAndServeTLS starts a HTTPS server for the admission webhooks and waits for SIGTERM,
+// the webhooks are served on their own mux so that they are not exposed on the metrics port
+func ListenAndServeTLS(port string, certFile string, keyFile string, mux *http.ServeMux, timeout time.Duration, logger *zap.SugaredLogger, stopCh <-chan struct{}) {
+	srv := newServer(port, mux)
+	logger.Infof("Starting HTTPS server on port %s", port)
+
+	// run server in background
+	go func() {
+		if err := srv.ListenAndServeTLS(certFile, keyFile); err != http.ErrServerClosed {
+			logger.Fatalf("HTTPS server crashed %v", err)
+		}
+	}()
+
+	shutdown(srv, timeout, logger, stopCh)
+}
+
+func newServer(port string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         ":" + port,
+		Handler:      handler,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 1 * time.Minute,
+		IdleTimeout:  15 * time.Second,
+	}
+}
+
+// shutdown waits for SIGTERM or SIGINT and stops the server gracefully
+func shutdown(srv *http.Server, timeout time.Duration, logger *zap.SugaredLogger, stopCh <-chan struct{}) {
 	<-stopCh
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
@@ -0,0 +1,97 @@
+package sink
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// ErrBufferFull is returned when the dispatcher queue has no room left
+var ErrBufferFull = errors.New("event buffer is full")
+
+// Dispatcher buffers events and publishes them in the background,
+// a slow or unreachable broker never blocks the caller
+type Dispatcher struct {
+	sink    Interface
+	queue   chan Event
+	logger  *zap.SugaredLogger
+	dropped uint64
+}
+
+// NewDispatcher returns a dispatcher that can hold up to bufferSize events
+func NewDispatcher(sink Interface, bufferSize int, logger *zap.SugaredLogger) *Dispatcher {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	return &Dispatcher{
+		sink:   sink,
+		queue:  make(chan Event, bufferSize),
+		logger: logger,
+	}
+}
+
+// Publish enqueues the event, if the buffer is full the event is dropped
+func (d *Dispatcher) Publish(event Event) error {
+	select {
+	case d.queue <- event:
+		return nil
+	default:
+		atomic.AddUint64(&d.dropped, 1)
+		return ErrBufferFull
+	}
+}
+
+// GetDropped returns the number of events discarded due to a full buffer
+func (d *Dispatcher) GetDropped() uint64 {
+	return atomic.LoadUint64(&d.dropped)
+}
+
+// NewDroppedCounter returns a Prometheus counter that reports the number of dropped events
+func (d *Dispatcher) NewDroppedCounter(subsystem string) prometheus.CounterFunc {
+	return prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Subsystem: subsystem,
+		Name:      "event_sink_dropped_total",
+		Help:      "Total number of canary events dropped due to a full event sink buffer",
+	}, func() float64 {
+		return float64(d.GetDropped())
+	})
+}
+
+// Run publishes the queued events until the stop channel is closed,
+// on shutdown the remaining events are flushed and the sink is closed
+func (d *Dispatcher) Run(stopCh <-chan struct{}) {
+	for {
+		select {
+		case event := <-d.queue:
+			d.publish(event)
+		case <-stopCh:
+			d.drain()
+			if closer, ok := d.sink.(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					d.logger.Errorf("closing event sink failed: %v", err)
+				}
+			}
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) drain() {
+	for {
+		select {
+		case event := <-d.queue:
+			d.publish(event)
+		default:
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) publish(event Event) {
+	if err := d.sink.Publish(event); err != nil {
+		d.logger.With("canary", event.Key()).Errorf("event sink publish error: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package sink
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/flagger/pkg/logger"
+)
+
+type blockingSink struct {
+	mu      sync.Mutex
+	release chan struct{}
+	events  []Event
+	closed  bool
+}
+
+func (s *blockingSink) Publish(event Event) error {
+	<-s.release
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *blockingSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func TestDispatcher_Publish(t *testing.T) {
+	logger, _ := logger.NewLogger("debug")
+	s := &blockingSink{release: make(chan struct{})}
+	d := NewDispatcher(s, 2, logger)
+
+	// the sink is blocked, the buffer must absorb events without stalling the caller
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		require.NoError(t, d.Publish(Event{Name: "podinfo", Type: PhaseEvent}))
+		require.NoError(t, d.Publish(Event{Name: "podinfo", Type: WeightEvent}))
+		assert.Equal(t, ErrBufferFull, d.Publish(Event{Name: "podinfo", Type: AnalysisEvent}))
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a slow sink")
+	}
+	assert.Equal(t, uint64(1), d.GetDropped())
+	assert.Equal(t, float64(1), testutil.ToFloat64(d.NewDroppedCounter("flagger")))
+
+	// release the sink and flush on shutdown
+	close(s.release)
+	stopCh := make(chan struct{})
+	close(stopCh)
+	d.Run(stopCh)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	require.Len(t, s.events, 2)
+	assert.Equal(t, PhaseEvent, s.events[0].Type)
+	assert.Equal(t, WeightEvent, s.events[1].Type)
+	assert.True(t, s.closed)
+}
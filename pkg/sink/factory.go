@@ -0,0 +1,39 @@
+package sink
+
+import (
+	"fmt"
+)
+
+type Factory struct {
+	Address string
+	Topic   string
+}
+
+func NewFactory(address string, topic string) *Factory {
+	return &Factory{
+		Address: address,
+		Topic:   topic,
+	}
+}
+
+func (f Factory) Sink(provider string) (Interface, error) {
+	if f.Address == "" {
+		return &NopSink{}, fmt.Errorf("%s address not set", provider)
+	}
+
+	var s Interface
+	var err error
+	switch provider {
+	case "nats":
+		s, err = NewNATS(f.Address, f.Topic)
+	case "kafka":
+		s, err = NewKafka(f.Address, f.Topic)
+	default:
+		err = fmt.Errorf("provider %s not supported", provider)
+	}
+
+	if err != nil {
+		s = &NopSink{}
+	}
+	return s, err
+}
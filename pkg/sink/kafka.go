@@ -0,0 +1,76 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Kafka publishes events to a Kafka topic
+type Kafka struct {
+	writer  *kafka.Writer
+	topic   string
+	timeout time.Duration
+}
+
+// NewKafka validates the broker list and returns a Kafka object
+func NewKafka(address string, topic string) (*Kafka, error) {
+	if topic == "" {
+		return nil, errors.New("empty Kafka topic")
+	}
+
+	var brokers []string
+	for _, b := range strings.Split(address, ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			brokers = append(brokers, b)
+		}
+	}
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("invalid Kafka brokers %s", address)
+	}
+
+	// events are keyed by canary so that all events
+	// of a canary land on the same partition in order
+	writer := kafka.NewWriter(kafka.WriterConfig{
+		Brokers:      brokers,
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		BatchTimeout: 100 * time.Millisecond,
+	})
+
+	return &Kafka{
+		writer:  writer,
+		topic:   topic,
+		timeout: 10 * time.Second,
+	}, nil
+}
+
+// Publish sends the JSON encoded event to the Kafka topic
+func (k *Kafka) Publish(event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshalling event failed: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), k.timeout)
+	defer cancel()
+
+	msg := kafka.Message{
+		Key:   []byte(event.Key()),
+		Value: data,
+	}
+	if err := k.writer.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("publishing to Kafka topic %s failed: %w", k.topic, err)
+	}
+	return nil
+}
+
+// Close flushes the pending messages and closes the writer
+func (k *Kafka) Close() error {
+	return k.writer.Close()
+}
@@ -0,0 +1,51 @@
+package sink
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATS publishes events to a NATS subject
+type NATS struct {
+	conn    *nats.Conn
+	subject string
+}
+
+// NewNATS connects to the NATS server and returns a NATS object
+func NewNATS(address string, subject string) (*NATS, error) {
+	if subject == "" {
+		return nil, errors.New("empty NATS subject")
+	}
+
+	conn, err := nats.Connect(address, nats.Name("flagger"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("connecting to NATS %s failed: %w", address, err)
+	}
+
+	return &NATS{
+		conn:    conn,
+		subject: subject,
+	}, nil
+}
+
+// Publish sends the JSON encoded event to the NATS subject
+func (n *NATS) Publish(event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshalling event failed: %w", err)
+	}
+
+	if err := n.conn.Publish(n.subject, data); err != nil {
+		return fmt.Errorf("publishing to NATS subject %s failed: %w", n.subject, err)
+	}
+	return nil
+}
+
+// Close flushes the pending messages and closes the connection
+func (n *NATS) Close() error {
+	defer n.conn.Close()
+	return n.conn.Flush()
+}
@@ -0,0 +1,95 @@
+package sink
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func runNATSServer() *server.Server {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = server.RANDOM_PORT
+	return natsserver.RunServer(&opts)
+}
+
+func TestNATS_Publish(t *testing.T) {
+	srv := runNATSServer()
+	defer srv.Shutdown()
+
+	sub, err := nats.Connect(srv.ClientURL())
+	require.NoError(t, err)
+	defer sub.Close()
+
+	msgs := make(chan *nats.Msg, 1)
+	_, err = sub.ChanSubscribe("flagger.events", msgs)
+	require.NoError(t, err)
+	require.NoError(t, sub.Flush())
+
+	s, err := NewNATS(srv.ClientURL(), "flagger.events")
+	require.NoError(t, err)
+	defer s.Close()
+
+	cd := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{Kind: "Deployment", Name: "podinfo"},
+		},
+	}
+	err = s.Publish(NewWeightEvent(cd, 90, 10))
+	require.NoError(t, err)
+
+	select {
+	case msg := <-msgs:
+		var event Event
+		require.NoError(t, json.Unmarshal(msg.Data, &event))
+		require.Equal(t, WeightEvent, event.Type)
+		require.Equal(t, "podinfo.default", event.Key())
+		require.Equal(t, "Deployment/podinfo", event.Target)
+		require.Equal(t, 90, event.PrimaryWeight)
+		require.Equal(t, 10, event.CanaryWeight)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for NATS message")
+	}
+}
+
+func TestNATS_EmptySubject(t *testing.T) {
+	_, err := NewNATS("nats://localhost:4222", "")
+	require.Error(t, err)
+}
+
+func TestEvent_FailedAnalysis(t *testing.T) {
+	cd := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+	}
+	data, err := json.Marshal(NewAnalysisEvent(cd, false))
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"success":false`)
+}
+
+func TestEvent_Weights(t *testing.T) {
+	cd := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Status:     flaggerv1.CanaryStatus{CanaryWeight: 30},
+	}
+
+	event := NewPhaseEvent(cd, flaggerv1.CanaryPhaseProgressing)
+	require.Equal(t, 70, event.PrimaryWeight)
+	require.Equal(t, 30, event.CanaryWeight)
+
+	event = NewAnalysisEvent(cd, true)
+	require.Equal(t, 70, event.PrimaryWeight)
+	require.Equal(t, 30, event.CanaryWeight)
+}
+
+func TestFactory_EmptyAddress(t *testing.T) {
+	_, err := NewFactory("", "flagger.events").Sink("nats")
+	require.Error(t, err)
+}
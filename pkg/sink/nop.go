@@ -0,0 +1,7 @@
+package sink
+
+type NopSink struct{}
+
+func (s *NopSink) Publish(Event) error {
+	return nil
+}
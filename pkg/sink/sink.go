@@ -0,0 +1,85 @@
+package sink
+
+import (
+	"fmt"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// EventType identifies what changed in the canary analysis
+type EventType string
+
+const (
+	// PhaseEvent is published on canary phase transitions
+	PhaseEvent EventType = "phase"
+	// WeightEvent is published when the traffic weights are changed
+	WeightEvent EventType = "weight"
+	// AnalysisEvent is published after each analysis run
+	AnalysisEvent EventType = "analysis"
+)
+
+// Interface publishes canary events to a message broker
+type Interface interface {
+	Publish(event Event) error
+}
+
+// Event holds the canary state published to the sink
+type Event struct {
+	Type          EventType             `json:"type"`
+	Name          string                `json:"name"`
+	Namespace     string                `json:"namespace"`
+	Target        string                `json:"target"`
+	Phase         flaggerv1.CanaryPhase `json:"phase"`
+	PrimaryWeight int                   `json:"primaryWeight"`
+	CanaryWeight  int                   `json:"canaryWeight"`
+	FailedChecks  int                   `json:"failedChecks"`
+	Iterations    int                   `json:"iterations"`
+	Success       bool                  `json:"success"`
+	Timestamp     time.Time             `json:"timestamp"`
+}
+
+// Key returns the canary identifier used for partitioning
+func (e Event) Key() string {
+	return fmt.Sprintf("%s.%s", e.Name, e.Namespace)
+}
+
+func newEvent(typ EventType, cd *flaggerv1.Canary) Event {
+	return Event{
+		Type:          typ,
+		Name:          cd.Name,
+		Namespace:     cd.Namespace,
+		Target:        fmt.Sprintf("%s/%s", cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name),
+		Phase:         cd.Status.Phase,
+		PrimaryWeight: 100 - cd.Status.CanaryWeight,
+		CanaryWeight:  cd.Status.CanaryWeight,
+		FailedChecks:  cd.Status.FailedChecks,
+		Iterations:    cd.Status.Iterations,
+		Timestamp:     time.Now().UTC(),
+	}
+}
+
+// NewPhaseEvent returns an event for a canary phase transition
+func NewPhaseEvent(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) Event {
+	e := newEvent(PhaseEvent, cd)
+	e.Phase = phase
+	return e
+}
+
+// NewWeightEvent returns an event for a traffic weight change
+func NewWeightEvent(cd *flaggerv1.Canary, primaryWeight int, canaryWeight int) Event {
+	e := newEvent(WeightEvent, cd)
+	e.PrimaryWeight = primaryWeight
+	e.CanaryWeight = canaryWeight
+	return e
+}
+
+// NewAnalysisEvent returns an event for the result of an analysis run
+func NewAnalysisEvent(cd *flaggerv1.Canary, success bool) Event {
+	e := newEvent(AnalysisEvent, cd)
+	e.Success = success
+	if !success {
+		e.FailedChecks++
+	}
+	return e
+}
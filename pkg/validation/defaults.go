@@ -0,0 +1,53 @@
+package validation
+
+import (
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// SetCanaryDefaults sets the documented default values of the canary spec,
+// the spec level defaults are skipped for canaries that reference a template
+// as they would take precedence over the template values
+func SetCanaryDefaults(cd *flaggerv1.Canary) {
+	if cd.Spec.TemplateRef == nil && cd.Spec.ProgressDeadlineSeconds == nil {
+		deadline := int32(flaggerv1.ProgressDeadlineSeconds)
+		cd.Spec.ProgressDeadlineSeconds = &deadline
+	}
+
+	analysis := cd.GetAnalysis()
+	if analysis == nil {
+		return
+	}
+
+	if cd.Spec.TemplateRef == nil {
+		if analysis.Interval == "" {
+			analysis.Interval = "1m"
+		}
+		if analysis.Threshold == 0 {
+			analysis.Threshold = 1
+		}
+	}
+
+	SetAnalysisDefaults(analysis)
+}
+
+// SetAnalysisDefaults sets the default values of the analysis metrics, webhooks and alerts
+func SetAnalysisDefaults(analysis *flaggerv1.CanaryAnalysis) {
+	for i := range analysis.Metrics {
+		if analysis.Metrics[i].Interval == "" {
+			analysis.Metrics[i].Interval = flaggerv1.MetricInterval
+		}
+	}
+	for i := range analysis.Webhooks {
+		if analysis.Webhooks[i].Type == "" {
+			analysis.Webhooks[i].Type = flaggerv1.RolloutHook
+		}
+		if analysis.Webhooks[i].Timeout == "" {
+			analysis.Webhooks[i].Timeout = "10s"
+		}
+	}
+	for i := range analysis.Alerts {
+		if analysis.Alerts[i].Severity == "" {
+			analysis.Alerts[i].Severity = flaggerv1.SeverityInfo
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package validation
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+)
+
+var (
+	targetKinds         = []string{"Deployment", "DaemonSet", "Service"}
+	severities          = []string{"", string(flaggerv1.SeverityInfo), string(flaggerv1.SeverityWarn), string(flaggerv1.SeverityError)}
+	builtinMetrics      = []string{"request-success-rate", "request-duration"}
+	metricProviderTypes = []string{"", "prometheus", "datadog", "cloudwatch"}
+	alertProviderTypes  = []string{"slack", "discord", "rocket", "msteams"}
+	canaryTemplateKinds = []string{"", flaggerv1.CanaryTemplateKind, flaggerv1.ClusterCanaryTemplateKind}
+	companionKinds      = []string{canary.PodDisruptionBudgetKind, canary.NetworkPolicyKind, canary.ServiceMonitorKind}
+	hookTypes           = []string{"",
+		string(flaggerv1.RolloutHook), string(flaggerv1.PreRolloutHook), string(flaggerv1.PostRolloutHook),
+		string(flaggerv1.ConfirmRolloutHook), string(flaggerv1.ConfirmPromotionHook), string(flaggerv1.EventHook),
+		string(flaggerv1.RollbackHook)}
+)
+
+// ValidateCanary returns the misconfigurations of the canary spec, the analysis of a canary
+// that references a template can be partial so only the fields set in the canary are checked
+func ValidateCanary(cd *flaggerv1.Canary) field.ErrorList {
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+
+	targetRef := spec.Child("targetRef")
+	if cd.Spec.TargetRef.Name == "" {
+		errs = append(errs, field.Required(targetRef.Child("name"), ""))
+	}
+	if !cd.Spec.TargetRef.IsKnativeService() && !contains(targetKinds, cd.Spec.TargetRef.Kind) {
+		errs = append(errs, field.NotSupported(targetRef.Child("kind"), cd.Spec.TargetRef.Kind, targetKinds))
+	}
+
+	// Knative Services are routed by Knative, the service port is not used
+	if !cd.Spec.TargetRef.IsKnativeService() && (cd.Spec.Service.Port < 1 || cd.Spec.Service.Port > 65535) {
+		errs = append(errs, field.Invalid(spec.Child("service", "port"), cd.Spec.Service.Port, "must be between 1 and 65535"))
+	}
+
+	for i, ref := range cd.Spec.CompanionRefs {
+		if !contains(companionKinds, ref.Kind) {
+			errs = append(errs, field.NotSupported(spec.Child("companionRefs").Index(i).Child("kind"), ref.Kind, companionKinds))
+		}
+	}
+
+	if ref := cd.Spec.TemplateRef; ref != nil {
+		if !contains(canaryTemplateKinds, ref.Kind) {
+			errs = append(errs, field.NotSupported(spec.Child("templateRef", "kind"), ref.Kind, canaryTemplateKinds[1:]))
+		}
+		if ref.Name == "" {
+			errs = append(errs, field.Required(spec.Child("templateRef", "name"), ""))
+		}
+	}
+
+	analysisPath := spec.Child("analysis")
+	if cd.Spec.Analysis == nil && cd.Spec.CanaryAnalysis != nil {
+		analysisPath = spec.Child("canaryAnalysis")
+	}
+	if analysis := cd.GetAnalysis(); analysis != nil {
+		errs = append(errs, ValidateAnalysis(analysis, analysisPath)...)
+	} else if cd.Spec.TemplateRef == nil && !cd.Spec.SkipAnalysis {
+		errs = append(errs, field.Required(analysisPath, "analysis or templateRef must be specified"))
+	}
+
+	return errs
+}
+
+// ValidateAnalysis returns the misconfigurations of the canary analysis
+func ValidateAnalysis(analysis *flaggerv1.CanaryAnalysis, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	errs = append(errs, validateDuration(path.Child("interval"), analysis.Interval)...)
+	errs = append(errs, validateDuration(path.Child("bakeTime"), analysis.BakeTime)...)
+
+	if analysis.Threshold < 0 {
+		errs = append(errs, field.Invalid(path.Child("threshold"), analysis.Threshold, "must be greater than or equal to 0"))
+	}
+	if analysis.Iterations < 0 {
+		errs = append(errs, field.Invalid(path.Child("iterations"), analysis.Iterations, "must be greater than or equal to 0"))
+	}
+	errs = append(errs, validatePercentage(path.Child("maxWeight"), analysis.MaxWeight)...)
+	errs = append(errs, validatePercentage(path.Child("stepWeight"), analysis.StepWeight)...)
+	errs = append(errs, validatePercentage(path.Child("mirrorWeight"), analysis.MirrorWeight)...)
+
+	if analysis.StepWeight > 0 && analysis.MaxWeight > 0 && analysis.StepWeight > analysis.MaxWeight {
+		errs = append(errs, field.Invalid(path.Child("stepWeight"), analysis.StepWeight, "must be less than or equal to maxWeight"))
+	}
+	if !analysis.Shadow && analysis.Iterations > 0 && analysis.StepWeight > 0 {
+		errs = append(errs, field.Forbidden(path.Child("stepWeight"),
+			"iterations (A/B testing, Blue/Green) and stepWeight (progressive traffic shifting) are mutually exclusive"))
+	}
+
+	metricNames := map[string]bool{}
+	for i, metric := range analysis.Metrics {
+		metricPath := path.Child("metrics").Index(i)
+		if metric.Name == "" {
+			errs = append(errs, field.Required(metricPath.Child("name"), ""))
+		} else if metricNames[metric.Name] {
+			errs = append(errs, field.Duplicate(metricPath.Child("name"), metric.Name))
+		}
+		metricNames[metric.Name] = true
+
+		if metric.Query == "" && metric.TemplateRef == nil && !contains(builtinMetrics, metric.Name) {
+			errs = append(errs, field.Required(metricPath, "query or templateRef must be specified for custom metrics"))
+		}
+		if metric.Query != "" && metric.TemplateRef != nil {
+			errs = append(errs, field.Forbidden(metricPath.Child("query"), "query and templateRef are mutually exclusive"))
+		}
+		if metric.TemplateRef != nil && metric.TemplateRef.Name == "" {
+			errs = append(errs, field.Required(metricPath.Child("templateRef", "name"), ""))
+		}
+		errs = append(errs, validateDuration(metricPath.Child("interval"), metric.Interval)...)
+
+		if tr := metric.ThresholdRange; tr != nil && tr.Min != nil && tr.Max != nil && *tr.Min > *tr.Max {
+			errs = append(errs, field.Invalid(metricPath.Child("thresholdRange"), *tr.Min, "min must be less than or equal to max"))
+		}
+	}
+
+	webhookNames := map[string]bool{}
+	for i, webhook := range analysis.Webhooks {
+		webhookPath := path.Child("webhooks").Index(i)
+		if webhook.Name == "" {
+			errs = append(errs, field.Required(webhookPath.Child("name"), ""))
+		} else if webhookNames[webhook.Name] {
+			errs = append(errs, field.Duplicate(webhookPath.Child("name"), webhook.Name))
+		}
+		webhookNames[webhook.Name] = true
+
+		if webhook.URL == "" {
+			errs = append(errs, field.Required(webhookPath.Child("url"), ""))
+		}
+		if !contains(hookTypes, string(webhook.Type)) {
+			errs = append(errs, field.NotSupported(webhookPath.Child("type"), webhook.Type, hookTypes[1:]))
+		}
+		errs = append(errs, validateDuration(webhookPath.Child("timeout"), webhook.Timeout)...)
+	}
+
+	for i, alert := range analysis.Alerts {
+		alertPath := path.Child("alerts").Index(i)
+		if alert.Name == "" {
+			errs = append(errs, field.Required(alertPath.Child("name"), ""))
+		}
+		if alert.ProviderRef.Name == "" {
+			errs = append(errs, field.Required(alertPath.Child("providerRef", "name"), ""))
+		}
+		if !contains(severities, string(alert.Severity)) {
+			errs = append(errs, field.NotSupported(alertPath.Child("severity"), alert.Severity, severities[1:]))
+		}
+	}
+
+	return errs
+}
+
+// ValidateCanaryTemplate returns the misconfigurations of the template analysis
+func ValidateCanaryTemplate(spec flaggerv1.CanaryTemplateSpec) field.ErrorList {
+	if spec.Analysis == nil {
+		return nil
+	}
+	return ValidateAnalysis(spec.Analysis, field.NewPath("spec", "analysis"))
+}
+
+// ValidateMetricTemplate returns the misconfigurations of the metric template
+func ValidateMetricTemplate(template *flaggerv1.MetricTemplate) field.ErrorList {
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+
+	if !contains(metricProviderTypes, template.Spec.Provider.Type) {
+		errs = append(errs, field.NotSupported(spec.Child("provider", "type"), template.Spec.Provider.Type, metricProviderTypes[1:]))
+	}
+	if template.Spec.Query == "" {
+		errs = append(errs, field.Required(spec.Child("query"), ""))
+	}
+
+	return errs
+}
+
+// ValidateAlertProvider returns the misconfigurations of the alert provider
+func ValidateAlertProvider(provider *flaggerv1.AlertProvider) field.ErrorList {
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+
+	if !contains(alertProviderTypes, provider.Spec.Type) {
+		errs = append(errs, field.NotSupported(spec.Child("type"), provider.Spec.Type, alertProviderTypes))
+	}
+	if provider.Spec.Address == "" && provider.Spec.SecretRef == nil {
+		errs = append(errs, field.Required(spec.Child("address"), "address or secretRef must be specified"))
+	}
+
+	return errs
+}
+
+// ValidateCanaryFleet returns the misconfigurations of the fleet clusters and waves
+func ValidateCanaryFleet(fleet *flaggerv1.CanaryFleet) field.ErrorList {
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+
+	if fleet.Spec.CanaryRef.Name == "" {
+		errs = append(errs, field.Required(spec.Child("canaryRef", "name"), ""))
+	}
+
+	clusters := make(map[string]bool)
+	if len(fleet.Spec.Clusters) == 0 {
+		errs = append(errs, field.Required(spec.Child("clusters"), "at least one cluster must be specified"))
+	}
+	for i, cluster := range fleet.Spec.Clusters {
+		path := spec.Child("clusters").Index(i)
+		if cluster.Name == "" {
+			errs = append(errs, field.Required(path.Child("name"), ""))
+		} else if clusters[cluster.Name] {
+			errs = append(errs, field.Duplicate(path.Child("name"), cluster.Name))
+		}
+		clusters[cluster.Name] = true
+	}
+
+	percentage := 0
+	for i, wave := range fleet.Spec.Waves {
+		path := spec.Child("waves").Index(i)
+		if wave.Name == "" {
+			errs = append(errs, field.Required(path.Child("name"), ""))
+		}
+		if len(wave.Clusters) == 0 && wave.Percentage == 0 {
+			errs = append(errs, field.Required(path.Child("clusters"), "clusters or percentage must be specified"))
+		}
+		for j, name := range wave.Clusters {
+			if !clusters[name] {
+				errs = append(errs, field.NotFound(path.Child("clusters").Index(j), name))
+			}
+		}
+		if wave.Percentage != 0 {
+			errs = append(errs, validatePercentage(path.Child("percentage"), wave.Percentage)...)
+			if wave.Percentage < percentage {
+				errs = append(errs, field.Invalid(path.Child("percentage"), wave.Percentage, "must not be lower than the percentage of the previous waves"))
+			}
+			percentage = wave.Percentage
+		}
+	}
+
+	return errs
+}
+
+func validateDuration(path *field.Path, value string) field.ErrorList {
+	if value == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, value, err.Error())}
+	}
+	if d < 0 {
+		return field.ErrorList{field.Invalid(path, value, "must be a positive duration")}
+	}
+	return nil
+}
+
+func validatePercentage(path *field.Path, value int) field.ErrorList {
+	if value < 0 || value > 100 {
+		return field.ErrorList{field.Invalid(path, value, "must be between 0 and 100")}
+	}
+	return nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
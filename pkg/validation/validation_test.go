@@ -0,0 +1,192 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newTestCanary() *flaggerv1.Canary {
+	cd := &flaggerv1.Canary{}
+	cd.Name = "podinfo"
+	cd.Namespace = "default"
+	cd.Spec = flaggerv1.CanarySpec{
+		TargetRef: flaggerv1.CrossNamespaceObjectReference{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Name:       "podinfo",
+		},
+		Service: flaggerv1.CanaryService{Port: 9898},
+		Analysis: &flaggerv1.CanaryAnalysis{
+			Interval:   "1m",
+			Threshold:  5,
+			MaxWeight:  50,
+			StepWeight: 10,
+			Metrics: []flaggerv1.CanaryMetric{
+				{Name: "request-success-rate", Threshold: 99},
+				{Name: "custom", Query: "sum(up)", Interval: "30s"},
+			},
+			Webhooks: []flaggerv1.CanaryWebhook{
+				{Name: "load-test", URL: "http://flagger-loadtester.test/", Timeout: "5s"},
+			},
+		},
+	}
+	return cd
+}
+
+func TestValidateCanary(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(cd *flaggerv1.Canary)
+		fields []string
+	}{
+		{
+			name:   "valid",
+			mutate: func(cd *flaggerv1.Canary) {},
+		},
+		{
+			name:   "step weight greater than max weight",
+			mutate: func(cd *flaggerv1.Canary) { cd.Spec.Analysis.StepWeight = 60 },
+			fields: []string{"spec.analysis.stepWeight"},
+		},
+		{
+			name:   "unknown target kind",
+			mutate: func(cd *flaggerv1.Canary) { cd.Spec.TargetRef.Kind = "StatefulSet" },
+			fields: []string{"spec.targetRef.kind"},
+		},
+		{
+			name:   "iterations with step weight",
+			mutate: func(cd *flaggerv1.Canary) { cd.Spec.Analysis.Iterations = 10 },
+			fields: []string{"spec.analysis.stepWeight"},
+		},
+		{
+			name: "metric without query or template",
+			mutate: func(cd *flaggerv1.Canary) {
+				cd.Spec.Analysis.Metrics[1].Query = ""
+			},
+			fields: []string{"spec.analysis.metrics[1]"},
+		},
+		{
+			name: "unparsable durations",
+			mutate: func(cd *flaggerv1.Canary) {
+				cd.Spec.Analysis.Interval = "1 minute"
+				cd.Spec.Analysis.Webhooks[0].Timeout = "5"
+			},
+			fields: []string{"spec.analysis.interval", "spec.analysis.webhooks[0].timeout"},
+		},
+		{
+			name:   "missing service port",
+			mutate: func(cd *flaggerv1.Canary) { cd.Spec.Service = flaggerv1.CanaryService{} },
+			fields: []string{"spec.service.port"},
+		},
+		{
+			name: "knative service without service port",
+			mutate: func(cd *flaggerv1.Canary) {
+				cd.Spec.TargetRef = flaggerv1.CrossNamespaceObjectReference{
+					APIVersion: "serving.knative.dev/v1",
+					Kind:       "Service",
+					Name:       "podinfo",
+				}
+				cd.Spec.Service = flaggerv1.CanaryService{}
+			},
+		},
+		{
+			name:   "missing analysis",
+			mutate: func(cd *flaggerv1.Canary) { cd.Spec.Analysis = nil },
+			fields: []string{"spec.analysis"},
+		},
+		{
+			name: "partial analysis with template",
+			mutate: func(cd *flaggerv1.Canary) {
+				cd.Spec.TemplateRef = &flaggerv1.CanaryTemplateRef{Name: "progressive"}
+				cd.Spec.Analysis = &flaggerv1.CanaryAnalysis{StepWeight: 20}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cd := newTestCanary()
+			tt.mutate(cd)
+
+			var fields []string
+			for _, err := range ValidateCanary(cd) {
+				fields = append(fields, err.Field)
+			}
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+}
+
+func TestValidateProviders(t *testing.T) {
+	template := &flaggerv1.MetricTemplate{}
+	template.Spec.Provider.Type = "influxdb"
+	errs := ValidateMetricTemplate(template)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "spec.provider.type", errs[0].Field)
+	assert.Equal(t, "spec.query", errs[1].Field)
+
+	provider := &flaggerv1.AlertProvider{}
+	provider.Spec.Type = "slack"
+	provider.Spec.SecretRef = &corev1.LocalObjectReference{Name: "slack-url"}
+	assert.Empty(t, ValidateAlertProvider(provider))
+
+	provider.Spec.Type = "pagerduty"
+	assert.Len(t, ValidateAlertProvider(provider), 1)
+}
+
+func TestSetCanaryDefaults(t *testing.T) {
+	cd := newTestCanary()
+	cd.Spec.Analysis.Interval = ""
+	cd.Spec.Analysis.Alerts = []flaggerv1.CanaryAlert{{Name: "on-call"}}
+	SetCanaryDefaults(cd)
+
+	assert.Equal(t, int32(600), *cd.Spec.ProgressDeadlineSeconds)
+	assert.Equal(t, "1m", cd.Spec.Analysis.Interval)
+	assert.Equal(t, "1m", cd.Spec.Analysis.Metrics[0].Interval)
+	assert.Equal(t, "30s", cd.Spec.Analysis.Metrics[1].Interval)
+	assert.Equal(t, flaggerv1.RolloutHook, cd.Spec.Analysis.Webhooks[0].Type)
+	assert.Equal(t, flaggerv1.SeverityInfo, cd.Spec.Analysis.Alerts[0].Severity)
+
+	// the template values take precedence over the defaults
+	cd = newTestCanary()
+	cd.Spec.TemplateRef = &flaggerv1.CanaryTemplateRef{Name: "progressive"}
+	cd.Spec.Analysis = &flaggerv1.CanaryAnalysis{StepWeight: 20}
+	SetCanaryDefaults(cd)
+
+	assert.Nil(t, cd.Spec.ProgressDeadlineSeconds)
+	assert.Equal(t, "", cd.Spec.Analysis.Interval)
+	assert.Equal(t, 0, cd.Spec.Analysis.Threshold)
+}
+
+func TestValidateCanaryFleet(t *testing.T) {
+	fleet := &flaggerv1.CanaryFleet{
+		Spec: flaggerv1.CanaryFleetSpec{
+			CanaryRef: flaggerv1.CrossNamespaceObjectReference{Name: "podinfo"},
+			Clusters: []flaggerv1.CanaryFleetCluster{
+				{Name: "eu-west-1"},
+				{Name: "eu-west-2"},
+			},
+			Waves: []flaggerv1.CanaryFleetWave{
+				{Name: "canary", Clusters: []string{"eu-west-1"}},
+				{Name: "rest", Percentage: 100},
+			},
+		},
+	}
+	assert.Empty(t, ValidateCanaryFleet(fleet))
+
+	fleet.Spec.Clusters = append(fleet.Spec.Clusters, flaggerv1.CanaryFleetCluster{Name: "eu-west-1"})
+	fleet.Spec.Waves = append(fleet.Spec.Waves,
+		flaggerv1.CanaryFleetWave{Name: "missing", Clusters: []string{"us-east-1"}},
+		flaggerv1.CanaryFleetWave{Name: "lower", Percentage: 50},
+	)
+	var fields []string
+	for _, err := range ValidateCanaryFleet(fleet) {
+		fields = append(fields, err.Field)
+	}
+	assert.Equal(t, []string{"spec.clusters[2].name", "spec.waves[2].clusters[0]", "spec.waves[3].percentage"}, fields)
+}